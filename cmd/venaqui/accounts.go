@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/mhrsntrk/venaqui/internal/alldebrid"
+	"github.com/mhrsntrk/venaqui/internal/config"
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/premiumize"
+	"github.com/mhrsntrk/venaqui/internal/realdebrid"
+	"github.com/mhrsntrk/venaqui/internal/utils"
+)
+
+// accounts are the debrid accounts links are resolved with: the configured
+// token first, then the accounts of the realdebrid.failover profiles, which
+// take over when one runs out of traffic or premium. Links of the hosts in
+// a provider's hosts setting go to that provider instead.
+type accounts struct {
+	profiles  []string // Profile of each account, empty outside profiles
+	providers []debrid.Provider
+	routes    []route
+
+	mu      sync.Mutex
+	current int
+}
+
+// route sends the links of some hosts to another provider
+type route struct {
+	hosts    []string
+	provider debrid.Provider
+}
+
+// newProvider creates the client of a provider named as in the configuration
+func newProvider(name, apiToken string) debrid.Provider {
+	switch name {
+	case "alldebrid":
+		return alldebrid.NewClient(apiToken)
+	case "premiumize":
+		return premiumize.NewClient(apiToken)
+	}
+	return realdebrid.NewClient(apiToken)
+}
+
+// newAccounts creates a client per account and checks the token in use.
+// With failover configured, accounts whose premium has expired are skipped.
+// It exits on failure like the download command does.
+func newAccounts(cfg *config.Config) *accounts {
+	a := &accounts{
+		profiles:  []string{cfg.Profile},
+		providers: []debrid.Provider{newProvider(cfg.Provider, cfg.APIToken)},
+	}
+	for _, account := range cfg.Failover {
+		a.profiles = append(a.profiles, account.Profile)
+		a.providers = append(a.providers, newProvider(account.Provider, account.APIToken))
+	}
+	for _, r := range cfg.Routes {
+		a.routes = append(a.routes, route{hosts: r.Hosts, provider: newProvider(r.Provider, r.APIToken)})
+	}
+
+	if len(a.providers) == 1 {
+		if err := a.provider().ValidateToken(); err != nil {
+			exitWithError(a.provider().Name()+" API token validation failed", err)
+		}
+		return a
+	}
+
+	for {
+		p := a.provider()
+		account, err := p.GetAccount()
+		if err != nil {
+			exitWithError(fmt.Sprintf("%s API token validation failed for account %s", p.Name(), a.name(a.current)), err)
+		}
+		if account.Premium {
+			return a
+		}
+		reason := fmt.Errorf("premium expired")
+		if !account.Expiration.IsZero() {
+			reason = fmt.Errorf("premium expired on %s", account.Expiration.Format("2006-01-02"))
+		}
+		if !a.failover(a.current, reason) {
+			return a
+		}
+	}
+}
+
+// provider returns the provider of the account in use
+func (a *accounts) provider() debrid.Provider {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.providers[a.current]
+}
+
+// name describes an account in messages
+func (a *accounts) name(i int) string {
+	if a.profiles[i] == "" {
+		return "default"
+	}
+	return a.profiles[i]
+}
+
+// failover switches from account i to the next one, reporting why. It
+// returns false when there is no account left. Another caller may have
+// switched already, in which case the account in use is kept.
+func (a *accounts) failover(i int, reason error) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current != i {
+		return true
+	}
+	if a.current+1 >= len(a.providers) {
+		return false
+	}
+	a.current++
+	reporter.Warning(fmt.Sprintf("%s account %s: %v; switching to %s account %s",
+		a.providers[i].Name(), a.name(i), reason, a.providers[a.current].Name(), a.name(a.current)))
+	return true
+}
+
+// resolve runs resolve with the account in use, trying the next accounts
+// while they run out of traffic or premium
+func (a *accounts) resolve(resolve func(debrid.Provider) ([]resolvedLink, error)) ([]resolvedLink, error) {
+	for {
+		a.mu.Lock()
+		i := a.current
+		a.mu.Unlock()
+
+		links, err := resolve(a.providers[i])
+		if err == nil || !debrid.AccountExhausted(err) || !a.failover(i, err) {
+			return links, err
+		}
+	}
+}
+
+// resolveLink resolves a hoster, magnet or torrent link, failing over
+// between accounts. Links on a routed host go to its provider.
+func (a *accounts) resolveLink(link string, choose fileChooser) ([]resolvedLink, error) {
+	if p := a.route(link); p != nil {
+		return resolveLink(p, link, choose)
+	}
+	return a.resolve(func(p debrid.Provider) ([]resolvedLink, error) {
+		return resolveLink(p, link, choose)
+	})
+}
+
+// route returns the provider a link is routed to by its host, or nil
+func (a *accounts) route(link string) debrid.Provider {
+	if len(a.routes) == 0 || utils.IsMagnetLink(link) {
+		return nil
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	for _, r := range a.routes {
+		for _, h := range r.hosts {
+			h = strings.TrimPrefix(strings.ToLower(h), "www.")
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return r.provider
+			}
+		}
+	}
+	return nil
+}
+
+// hostRegexes returns the link patterns of the provider in use and of the
+// routed providers
+func (a *accounts) hostRegexes() ([]string, error) {
+	patterns, err := a.provider().GetHostRegexes()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range a.routes {
+		routed, err := r.provider.GetHostRegexes()
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, routed...)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"github.com/mhrsntrk/venaqui/internal/clipboard"
+	"github.com/mhrsntrk/venaqui/internal/config"
+	"github.com/mhrsntrk/venaqui/internal/duplicate"
+)
+
+var clipCmd = &cobra.Command{
+	Use:   "clip",
+	Short: "Queue links copied to the clipboard",
+	Long: `Watch the clipboard and offer every copied hoster link supported by
+the debrid provider, magnet link and .torrent URL for download. Links are queued in
+aria2 after confirmation, or right away with --yes or clip.auto_accept.`,
+	Args: cobra.NoArgs,
+	Run:  runClip,
+}
+
+var clipAutoAccept bool
+
+func init() {
+	clipCmd.Flags().BoolVarP(&clipAutoAccept, "yes", "y", false, "Queue copied links without asking (overrides clip.auto_accept)")
+}
+
+func runClip(cmd *cobra.Command, args []string) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range cfg.Warnings {
+		reporter.Warning(warning)
+	}
+
+	reader, err := clipboard.NewReader(cfg.Clip.Command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Clipboard error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Or set clip.command to a command printing the clipboard\n")
+		os.Exit(1)
+	}
+
+	duplicateAction, err := duplicate.ParseAction(cfg.Duplicates.Action)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid duplicate action: %v\n", err)
+		os.Exit(1)
+	}
+
+	autoAccept := cfg.Clip.AutoAccept || clipAutoAccept
+	if !autoAccept && !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintln(os.Stderr, "Copied links can't be confirmed without a terminal: pass --yes or set clip.auto_accept")
+		os.Exit(1)
+	}
+
+	q := newQueuer(cfg, duplicateAction)
+	defer q.aria2Client.Close()
+
+	// The providers' hoster patterns decide which copied links are offered
+	patterns, err := q.accounts.hostRegexes()
+	if err != nil {
+		reporter.Warning(fmt.Sprintf("Failed to get supported hosts, only offering magnet and .torrent links: %v", err))
+	}
+	matcher, skipped := clipboard.NewMatcher(append(patterns, cfg.Clip.Hosts...))
+	if skipped > 0 {
+		reporter.Warning(fmt.Sprintf("Ignoring %d host pattern(s) that could not be compiled", skipped))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	answers := readLines(os.Stdin)
+	offer := func(links []string) {
+		for _, link := range links {
+			if !autoAccept && !confirmLink(ctx, answers, link) {
+				continue
+			}
+			if err := q.queueLink(link); err != nil {
+				reporter.Warning(fmt.Sprintf("Failed to queue %s: %v", link, err))
+				q.notifyQueueFailed(link, err)
+			}
+		}
+	}
+	onError := func(err error) {
+		reporter.Warning(err.Error())
+	}
+
+	go q.enforceSchedule(ctx)
+	go q.followDownloads(ctx)
+
+	reporter.Status("Watching the clipboard for links, press Ctrl+C to stop...")
+	clipboard.NewMonitor(reader, matcher, cfg.Clip.Interval).Run(ctx, offer, onError)
+}
+
+// readLines sends the lines read from r until it is closed, so prompts can
+// be abandoned when the command is interrupted
+func readLines(r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return lines
+}
+
+// confirmLink asks the user whether to queue a copied link
+func confirmLink(ctx context.Context, answers <-chan string, link string) bool {
+	for {
+		fmt.Printf("Queue %s? [Y/n] ", link)
+		var answer string
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return false
+		case line, ok := <-answers:
+			if !ok {
+				return false
+			}
+			answer = line
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
@@ -0,0 +1,573 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"github.com/mhrsntrk/venaqui/internal/config"
+	"github.com/mhrsntrk/venaqui/internal/organize"
+	"github.com/mhrsntrk/venaqui/internal/schedule"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change settings in the configuration file",
+	Args:  cobra.NoArgs,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the path of the configuration file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(configFile())
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every setting with its value and where it comes from",
+	Args:  cobra.NoArgs,
+	Run:   runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> [value]",
+	Short: "Change a setting in the configuration file",
+	Long: `Change a setting in the configuration file. Lists are given comma
+separated. When the value is left out it is read from standard input, without
+echo in a terminal, which keeps secrets out of the shell history.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting from the configuration file, restoring its default",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigUnset,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration file for unknown keys and invalid values",
+	Args:  cobra.NoArgs,
+	Run:   runConfigValidate,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the configuration file in $VISUAL or $EDITOR and validate it",
+	Args:  cobra.NoArgs,
+	Run:   runConfigEdit,
+}
+
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the profiles in the configuration file, marking the active one",
+	Args:  cobra.NoArgs,
+	Run:   runConfigProfiles,
+}
+
+var configSetSecretCmd = &cobra.Command{
+	Use:   "set-secret <key> [value]",
+	Short: "Store the API token or aria2 secret in the keyring or an encrypted file",
+	Long: `Store an API token such as realdebrid.api_token, or aria2.secret, in a
+secret backend instead of the configuration file, and remove the plaintext
+copy from the file.
+
+  keyring    The desktop keyring, through the Secret Service API on Linux
+  file       A file encrypted with a passphrase, read from
+             VENAQUI_SECRETS_PASSPHRASE or asked for
+  plaintext  The configuration file
+
+The backend defaults to secrets.backend and is saved there when given with
+--backend. When the value is left out it is read from standard input,
+without echo in a terminal.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runConfigSetSecret,
+}
+
+var (
+	showSecrets   bool
+	secretBackend string
+)
+
+func init() {
+	configListCmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "Print secrets such as the API token instead of hiding them")
+	configGetCmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "Print secrets such as the API token instead of hiding them")
+
+	configCmd.Long = "Show and change settings in the configuration file. Every setting but the\n" +
+		"structured ones can be overridden with an environment variable such as\n" +
+		config.EnvVar("realdebrid.api_token") + ". With --profile, set, unset and set-secret\n" +
+		"change the settings of that profile.\n\nSettings:\n" + describeKeys()
+	configSetSecretCmd.Flags().StringVar(&secretBackend, "backend", "", "Where to store the secret: keyring, file or plaintext (overrides secrets.backend)")
+	configCmd.AddCommand(configPathCmd, configListCmd, configProfilesCmd, configGetCmd, configSetCmd, configSetSecretCmd, configUnsetCmd, configValidateCmd, configEditCmd)
+}
+
+// describeKeys lists the known settings for the help text
+func describeKeys() string {
+	var s strings.Builder
+	w := tabwriter.NewWriter(&s, 0, 0, 2, ' ', 0)
+	for _, key := range config.Keys {
+		kind := key.Kind.String()
+		if key.Kind == config.KindChoice {
+			kind = strings.Join(key.Choices, "|")
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", key.Name, kind, key.Description)
+	}
+	w.Flush()
+	return s.String()
+}
+
+// configFile returns the path of the configuration file or exits
+func configFile() string {
+	path, err := config.GetConfigFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get home directory: %v\n", err)
+		os.Exit(1)
+	}
+	return path
+}
+
+// lookupKey returns the named setting or exits
+func lookupKey(name string) config.Key {
+	key, ok := config.LookupKey(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown key %s, run 'venaqui config --help' for the list of settings\n", name)
+		os.Exit(1)
+	}
+	return key
+}
+
+// settingKey returns the key a setting is changed under in the file: within
+// the profile given with --profile, if any. It exits for settings that
+// apply to every profile.
+func settingKey(key config.Key) string {
+	if profileName == "" {
+		return key.Name
+	}
+	if key.Global {
+		fmt.Fprintf(os.Stderr, "%s applies to every profile and can't be set with --profile\n", key.Name)
+		os.Exit(1)
+	}
+	return config.ProfileKey(profileName, key.Name)
+}
+
+func runConfigProfiles(cmd *cobra.Command, args []string) {
+	path := configFile()
+	profiles, err := config.Profiles(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if len(profiles) == 0 {
+		fmt.Printf("No profiles in %s\n", path)
+		return
+	}
+
+	active, err := config.ActiveProfile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	for _, name := range profiles {
+		marker := " "
+		if strings.EqualFold(name, active) {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+}
+
+func runConfigList(cmd *cobra.Command, args []string) {
+	settings, err := config.Inspect(configFile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, setting := range settings {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key.Name, formatValue(setting.Key, setting.Value, false), setting.Source)
+	}
+	w.Flush()
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	key := lookupKey(args[0])
+	settings, err := config.Inspect(configFile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, setting := range settings {
+		if setting.Key.Name == key.Name {
+			fmt.Println(formatValue(key, setting.Value, true))
+			return
+		}
+	}
+}
+
+// formatValue renders a setting's value, hiding secrets unless
+// --show-secrets is given. Structured settings are summarized unless full
+// is set, in which case they are printed as JSON.
+func formatValue(key config.Key, value interface{}, full bool) string {
+	if value == nil {
+		return ""
+	}
+	if key.Secret && !showSecrets && !isEmpty(value) {
+		return "********"
+	}
+
+	if key.Kind == config.KindStructured {
+		return formatStructured(value, full)
+	}
+
+	switch value := value.(type) {
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ", ")
+	case []string:
+		return strings.Join(value, ", ")
+	}
+	return fmt.Sprint(value)
+}
+
+// formatStructured renders a list or map of settings, summarized unless
+// full is set
+func formatStructured(value interface{}, full bool) string {
+	if !full {
+		switch value := value.(type) {
+		case []interface{}:
+			return fmt.Sprintf("(%d entries)", len(value))
+		case map[string]interface{}:
+			return fmt.Sprintf("(%d entries)", len(value))
+		}
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// isEmpty reports whether a value is an empty string or list
+func isEmpty(value interface{}) bool {
+	switch value := value.(type) {
+	case string:
+		return value == ""
+	case []interface{}:
+		return len(value) == 0
+	case []string:
+		return len(value) == 0
+	}
+	return false
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	key := lookupKey(args[0])
+	if key.Kind == config.KindStructured {
+		fmt.Fprintf(os.Stderr, "%s can only be changed by editing the configuration file, run 'venaqui config edit'\n", key.Name)
+		os.Exit(1)
+	}
+
+	var raw string
+	if len(args) == 2 {
+		raw = args[1]
+	} else {
+		var err error
+		if raw, err = readValue(key); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read value: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	value, err := key.Parse(raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid value: %v\n", err)
+		os.Exit(1)
+	}
+
+	path := configFile()
+	name := settingKey(key)
+	if err := config.Save(path, map[string]interface{}{name: value}); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Set %s = %s in %s\n", name, formatValue(key, value, false), path)
+	warnEnvOverride(key)
+	if config.IsStoredSecret(key.Name) {
+		fmt.Fprintf(os.Stderr, "Note: the secret is stored in plaintext; 'venaqui config set-secret %s' keeps it out of the file\n", key.Name)
+	}
+}
+
+func runConfigSetSecret(cmd *cobra.Command, args []string) {
+	key := lookupKey(args[0])
+	if !config.IsStoredSecret(key.Name) {
+		fmt.Fprintln(os.Stderr, "Only the API tokens and aria2.secret can be stored in a secret backend")
+		os.Exit(1)
+	}
+
+	path := configFile()
+	name := settingKey(key)
+	settings, err := config.Inspect(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	configured, _ := settingValue(settings, "secrets.backend").(string)
+	file, _ := settingValue(settings, "secrets.file").(string)
+
+	backend := configured
+	if secretBackend != "" {
+		backend = secretBackend
+	}
+	if backend == "" {
+		fmt.Fprintln(os.Stderr, "Choose where to store the secret with --backend keyring or --backend file, or set secrets.backend")
+		os.Exit(1)
+	}
+	store, err := config.NewSecretStore(backend, file, newPassphrase(file))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid secret backend: %v\n", err)
+		os.Exit(1)
+	}
+
+	var raw string
+	if len(args) == 2 {
+		raw = args[1]
+	} else if raw, err = readValue(key); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read value: %v\n", err)
+		os.Exit(1)
+	}
+	if raw == "" {
+		fmt.Fprintln(os.Stderr, "The secret is empty")
+		os.Exit(1)
+	}
+
+	if store == nil {
+		// The plaintext backend keeps the secret in the file
+		settings := map[string]interface{}{name: raw}
+		if backend != configured {
+			settings["secrets.backend"] = backend
+		}
+		if err := config.Save(path, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Stored %s in plaintext in %s\n", name, path)
+		return
+	}
+
+	if err := store.Set(name, raw); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to store secret: %v\n", err)
+		os.Exit(1)
+	}
+	// Remember the backend so later runs read the secret from it
+	if backend != configured {
+		if err := config.Save(path, map[string]interface{}{"secrets.backend": backend}); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if _, err := config.Unset(path, name); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove the plaintext copy from %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Stored %s in the %s backend\n", name, backend)
+	warnEnvOverride(key)
+}
+
+// settingValue returns the effective value of a setting
+func settingValue(settings []config.Setting, name string) interface{} {
+	for _, setting := range settings {
+		if setting.Key.Name == name {
+			return setting.Value
+		}
+	}
+	return nil
+}
+
+// newPassphrase returns the passphrase function for the encrypted secrets
+// file, asking twice when the file is about to be created
+func newPassphrase(file string) func() (string, error) {
+	return func() (string, error) {
+		if value := os.Getenv(config.PassphraseEnv); value != "" {
+			return value, nil
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return "", fmt.Errorf("set %s to encrypt the secrets file", config.PassphraseEnv)
+		}
+
+		passphrase, err := readPassword("Secrets passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		path, err := config.SecretsFile(file)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			again, err := readPassword("Repeat the passphrase: ")
+			if err != nil {
+				return "", err
+			}
+			if again != passphrase {
+				return "", errors.New("the passphrases don't match")
+			}
+		}
+		return passphrase, nil
+	}
+}
+
+// readPassword asks for a line on the terminal without echo
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return strings.TrimSpace(string(data)), err
+}
+
+// warnEnvOverride warns when an environment variable overrides the file
+func warnEnvOverride(key config.Key) {
+	if _, ok := os.LookupEnv(config.EnvVar(key.Name)); ok && key.Kind != config.KindStructured {
+		fmt.Fprintf(os.Stderr, "Note: %s is set and overrides the file\n", config.EnvVar(key.Name))
+	}
+}
+
+// readValue reads the value of a setting from standard input, without echo
+// when it is a terminal
+func readValue(key config.Key) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	return readPassword(key.Name + ": ")
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) {
+	key := lookupKey(args[0])
+	path := configFile()
+	name := settingKey(key)
+
+	removed, err := config.Unset(path, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err)
+		os.Exit(1)
+	}
+	if !removed {
+		fmt.Printf("%s is not set in %s\n", name, path)
+		return
+	}
+	fmt.Printf("Removed %s from %s\n", name, path)
+	warnEnvOverride(key)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) {
+	path := configFile()
+	if !validateConfig(path) {
+		os.Exit(1)
+	}
+	fmt.Printf("✓ %s is valid\n", path)
+}
+
+// validateConfig prints the problems in the configuration file and reports
+// whether there were none
+func validateConfig(path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %s does not exist, run 'venaqui init' to create it\n", path)
+		return false
+	}
+
+	problems := config.Validate(path)
+	if len(problems) == 0 {
+		// The types are right, so check what the packages using them check
+		cfg, err := config.Load()
+		if err != nil {
+			problems = append(problems, err)
+		} else {
+			for _, warning := range cfg.Warnings {
+				fmt.Fprintf(os.Stderr, "! %s\n", warning)
+			}
+			if _, err := organize.New(cfg.Organize); err != nil {
+				problems = append(problems, fmt.Errorf("invalid organize configuration: %w", err))
+			}
+			if _, err := schedule.New(cfg.Schedule); err != nil {
+				problems = append(problems, fmt.Errorf("invalid schedule configuration: %w", err))
+			}
+		}
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", problem)
+	}
+	return len(problems) == 0
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) {
+	path := configFile()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create config directory: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+
+	editor := strings.Fields(editorCommand())
+	edit := exec.Command(editor[0], append(editor[1:], path)...)
+	edit.Stdin, edit.Stdout, edit.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := edit.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Editor failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !validateConfig(path) {
+		fmt.Fprintln(os.Stderr, "Run 'venaqui config edit' again to fix the configuration")
+		os.Exit(1)
+	}
+	fmt.Printf("✓ %s is valid\n", path)
+}
+
+// editorCommand returns the user's editor
+func editorCommand() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
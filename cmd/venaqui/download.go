@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/diskspace"
+	"github.com/mhrsntrk/venaqui/internal/extract"
+	"github.com/mhrsntrk/venaqui/internal/headless"
+	"github.com/mhrsntrk/venaqui/internal/history"
+	"github.com/mhrsntrk/venaqui/internal/hooks"
+	"github.com/mhrsntrk/venaqui/internal/notify"
+	"github.com/mhrsntrk/venaqui/internal/schedule"
+	"github.com/mhrsntrk/venaqui/internal/verify"
+)
+
+// diskCheckInterval is how often free space is checked during a download
+const diskCheckInterval = 5 * time.Second
+
+// scheduleCheckInterval is how often the download schedule is applied during a download
+const scheduleCheckInterval = 10 * time.Second
+
+// guardedSource checks free disk space and applies the download schedule
+// while the download is followed
+type guardedSource struct {
+	client       *aria2.Client
+	guard        *diskspace.Guard   // Nil disables the check
+	schedule     *schedule.Enforcer // Nil when the download is not scheduled
+	lastPoll     time.Time
+	lastSchedule time.Time
+}
+
+// GetStatus implements headless.StatusSource
+func (s *guardedSource) GetStatus(gid string) (*aria2.DownloadStatus, error) {
+	if s.schedule != nil && time.Since(s.lastSchedule) >= scheduleCheckInterval {
+		s.lastSchedule = time.Now()
+		if event := s.schedule.Poll(s.lastSchedule); event != nil {
+			reporter.Status(event.String())
+		}
+	}
+	if s.guard != nil && time.Since(s.lastPoll) >= diskCheckInterval {
+		s.lastPoll = time.Now()
+		event, err := s.guard.Poll()
+		if err != nil {
+			reporter.Warning(fmt.Sprintf("Disk space check failed: %v", err))
+		} else if event != nil {
+			reporter.Warning(event.String())
+		}
+	}
+	return s.client.GetStatus(gid)
+}
+
+// runHeadless follows a download without the TUI, verifying the file once it
+// completes and re-downloading it if the checksum does not match
+func runHeadless(client *aria2.Client, guard *diskspace.Guard, enforcer *schedule.Enforcer, gid, filename string, checksum *verify.Checksum, opts aria2.DownloadOptions) (*aria2.DownloadStatus, *verify.Result, error) {
+	source := &guardedSource{client: client, guard: guard, schedule: enforcer}
+	// Nothing resumes downloads paused for low disk space once venaqui exits
+	if guard != nil {
+		defer guard.Release()
+	}
+	for attempt := 0; ; attempt++ {
+		status, err := reporter.Run(source, gid, filename, time.Second)
+		if err != nil {
+			if status == nil || !status.IsChecksumError() || attempt >= verify.MaxRedownloads {
+				// aria2 gave up on the checksum itself
+				if status != nil && status.IsChecksumError() {
+					err = fmt.Errorf("%w: %v", verify.ErrMismatch, err)
+				}
+				return status, nil, err
+			}
+			reporter.Status("aria2 rejected the file's checksum, downloading it again...")
+		} else {
+			result, err := verify.Check(status.GetFilePath(), checksum)
+			if err != nil {
+				return status, nil, fmt.Errorf("failed to verify download: %w", err)
+			}
+			if result == nil {
+				return status, nil, nil
+			}
+
+			reporter.Verification(filename, result)
+			if result.OK() {
+				return status, result, nil
+			}
+			if attempt >= verify.MaxRedownloads {
+				return status, result, fmt.Errorf("%w: %s", verify.ErrMismatch, result.Path)
+			}
+			reporter.Status("Checksum mismatch, downloading the file again...")
+		}
+
+		gid, err = client.Redownload(status, opts)
+		if err != nil {
+			return status, nil, err
+		}
+		if enforcer != nil {
+			enforcer.Track(gid)
+		}
+		if guard != nil {
+			guard.Track(gid)
+		}
+	}
+}
+
+// recordHistory appends the outcome of a download to the history file,
+// completing an entry that describes the link. Failures are reported but never fatal.
+func recordHistory(entry history.Entry, status *aria2.DownloadStatus, result *verify.Result, downloadErr error) {
+	path, err := history.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to record history: %v\n", err)
+		return
+	}
+
+	entry.Time = time.Now()
+	if status != nil {
+		entry.GID = status.GID
+		entry.Path = status.GetFilePath()
+		entry.Size = status.TotalLength
+	}
+	entry.Status = historyStatus(status, result, downloadErr)
+	if downloadErr != nil {
+		entry.Error = downloadErr.Error()
+	}
+	if result != nil {
+		entry.Checksum = &history.Checksum{
+			Algo:   result.Algo,
+			Source: result.Source,
+			OK:     result.OK(),
+		}
+	}
+
+	if err := history.NewStore(path).Append(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to record history: %v\n", err)
+	}
+}
+
+// historyStatus returns the outcome of a download to record. Only files
+// that finished and passed verification, if any, count as complete.
+func historyStatus(status *aria2.DownloadStatus, result *verify.Result, downloadErr error) string {
+	switch {
+	case result != nil && !result.OK():
+		return history.StatusCorrupted
+	case downloadErr != nil:
+		return history.StatusError
+	case status == nil || !status.IsComplete():
+		return history.StatusIncomplete
+	}
+	return history.StatusComplete
+}
+
+// extractHeadless extracts the archive set a completed download belongs to.
+// It waits while aria2 is still downloading other parts of the set, but
+// gives up without an error if parts are missing that aria2 does not know about.
+func extractHeadless(client *aria2.Client, extractor *extract.Extractor, path string) error {
+	set, err := extract.Detect(path)
+	if err != nil || set == nil {
+		return err
+	}
+
+	for {
+		pending, err := client.GetPendingFiles()
+		if err != nil {
+			return err
+		}
+		missing := set.Missing(pending)
+		if len(missing) == 0 {
+			break
+		}
+
+		stillDownloading := false
+		for _, p := range pending {
+			if set.Contains(p) {
+				stillDownloading = true
+			}
+		}
+		if !stillDownloading {
+			reporter.Extraction(headless.PhaseExtracting, fmt.Sprintf("Skipping extraction of %s, missing part(s): %s", set.Name, strings.Join(missing, ", ")), "")
+			return nil
+		}
+
+		reporter.Extraction(headless.PhaseExtracting, fmt.Sprintf("Waiting for %d part(s) of %s...", len(missing), set.Name), "")
+		time.Sleep(2 * time.Second)
+		if set, err = extract.Detect(path); err != nil || set == nil {
+			return err
+		}
+	}
+
+	lastPercent := -10
+	dest, err := extractor.Extract(context.Background(), set, func(p extract.Progress) {
+		// Report every 10% to keep the output readable
+		if p.Percent < 0 || p.Percent/10 == lastPercent/10 {
+			return
+		}
+		lastPercent = p.Percent
+		reporter.Extraction(headless.PhaseExtracting, fmt.Sprintf("Extracting %s... %d%%", set.Name, p.Percent), "")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", set.Name, err)
+	}
+
+	reporter.Extraction(headless.PhaseExtracted, fmt.Sprintf("Extracted %s to %s", set.Name, dest), dest)
+	return nil
+}
+
+// notifyOutcome sends the notification for a download that completed or
+// failed. It is sent right away, since headless runs exit soon after.
+func notifyOutcome(notifications *notify.Notifications, filename string, downloadErr error) {
+	if downloadErr == nil {
+		notifications.SendEvent(notify.EventComplete, "Download complete", filename)
+		return
+	}
+
+	event := notify.EventError
+	if errors.Is(downloadErr, verify.ErrMismatch) {
+		event = notify.EventRetryExhausted
+	}
+	notifications.SendEvent(event, "Download failed", fmt.Sprintf("%s: %s", filename, strings.TrimSpace(downloadErr.Error())))
+}
+
+// resumeQueued unpauses a download already in aria2's queue
+func resumeQueued(client *aria2.Client, gid string) error {
+	status, err := client.GetStatus(gid)
+	if err != nil {
+		return err
+	}
+	if status.Status == "paused" {
+		return client.ResumeDownload(gid)
+	}
+	return nil
+}
+
+// runHooks runs the hooks for an event and reports their results.
+// Hook failures are reported but never change the exit code.
+func runHooks(runner *hooks.Runner, event hooks.Event, vars hooks.Vars, status *aria2.DownloadStatus, start time.Time, downloadErr error) {
+	if !runner.Has(event) {
+		return
+	}
+
+	vars = vars.WithStatus(status)
+	vars.Duration = time.Since(start)
+	if downloadErr != nil {
+		vars.Error = downloadErr.Error()
+	}
+	for _, result := range runner.Run(context.Background(), event, vars) {
+		reporter.Hook(result)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/history"
+	"github.com/mhrsntrk/venaqui/internal/verify"
+)
+
+func TestHistoryStatus(t *testing.T) {
+	complete := &aria2.DownloadStatus{Status: "complete"}
+	good := &verify.Result{Expected: "abc", Actual: "abc"}
+	bad := &verify.Result{Expected: "abc", Actual: "def"}
+
+	tests := []struct {
+		name   string
+		status *aria2.DownloadStatus
+		result *verify.Result
+		err    error
+		want   string
+	}{
+		{"finished", complete, nil, nil, history.StatusComplete},
+		{"verified", complete, good, nil, history.StatusComplete},
+		{"quit while active", &aria2.DownloadStatus{Status: "active"}, nil, nil, history.StatusIncomplete},
+		{"corrupted after every re-download", complete, bad, nil, history.StatusCorrupted},
+		{"failed", &aria2.DownloadStatus{Status: "error"}, nil, errors.New("boom"), history.StatusError},
+	}
+	for _, tt := range tests {
+		if got := historyStatus(tt.status, tt.result, tt.err); got != tt.want {
+			t.Errorf("historyStatus() of %s = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
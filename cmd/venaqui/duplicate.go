@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mhrsntrk/venaqui/internal/duplicate"
+	"github.com/mhrsntrk/venaqui/internal/history"
+)
+
+// loadHistory returns the download history. Failures are reported but
+// never fatal; duplicate detection then only looks at disk and aria2.
+func loadHistory() []history.Entry {
+	path, err := history.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read history: %v\n", err)
+		return nil
+	}
+	entries, err := history.NewStore(path).Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read history: %v\n", err)
+	}
+	return entries
+}
+
+// resolveDuplicate decides what to do about duplicates of a download. It
+// returns an empty action when there are none, and otherwise skip, resume
+// or force along with the match the action applies to. ask prompts the
+// user when interactive is set and skips otherwise.
+func resolveDuplicate(action duplicate.Action, interactive bool, name string, matches []duplicate.Match) (duplicate.Action, duplicate.Match) {
+	if len(matches) == 0 {
+		return "", duplicate.Match{}
+	}
+
+	match := matches[0]
+	canResume := false
+	for _, m := range matches {
+		if m.Partial {
+			match, canResume = m, true
+			break
+		}
+	}
+
+	if action == duplicate.ActionAsk {
+		if interactive {
+			action = askDuplicate(name, matches, canResume)
+		} else {
+			action = duplicate.ActionSkip
+		}
+	}
+
+	switch {
+	case action == duplicate.ActionResume && !canResume:
+		reporter.Status(fmt.Sprintf("Skipping %s: nothing to resume, %s", name, match))
+		return duplicate.ActionSkip, match
+	case action == duplicate.ActionSkip:
+		reporter.Status(fmt.Sprintf("Skipping %s: %s", name, match))
+	}
+	return action, match
+}
+
+// askDuplicate asks the user whether to skip, resume or force a duplicate download
+func askDuplicate(name string, matches []duplicate.Match, canResume bool) duplicate.Action {
+	fmt.Printf("%s looks like a duplicate:\n", name)
+	for _, m := range matches {
+		fmt.Printf("  - %s\n", m)
+	}
+
+	prompt := "[s]kip or [f]orce download? [s] "
+	if canResume {
+		prompt = "[s]kip, [r]esume or [f]orce download? [s] "
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(prompt)
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return duplicate.ActionSkip
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "s", "skip":
+			return duplicate.ActionSkip
+		case "f", "force":
+			return duplicate.ActionForce
+		case "r", "resume":
+			if canResume {
+				return duplicate.ActionResume
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/diskspace"
+	"github.com/mhrsntrk/venaqui/internal/verify"
+)
+
+// Process exit codes. Provider errors get their own codes so scripts
+// can tell a bad token from an exhausted quota without parsing output.
+const (
+	exitOK             = 0
+	exitFailure        = 1
+	exitDownloadFailed = 3
+	exitChecksumFailed = 4
+	exitNoSpace        = 5
+	exitRemoved        = 6
+
+	exitBadToken               = 10
+	exitPermissionDenied       = 11
+	exitAccountLocked          = 12
+	exitRateLimited            = 13
+	exitHosterUnsupported      = 14
+	exitHosterUnavailable      = 15
+	exitHosterLimitReached     = 16
+	exitPremiumRequired        = 17
+	exitTooManyActiveDownloads = 18
+	exitIPNotAllowed           = 19
+	exitTrafficExhausted       = 20
+	exitFileUnavailable        = 21
+	exitFileNotAllowed         = 22
+	exitServiceUnavailable     = 23
+	exitTorrentInvalid         = 24
+)
+
+// rdExitCodes maps the providers' sentinel errors to exit codes
+var rdExitCodes = []struct {
+	err  error
+	code int
+}{
+	{debrid.ErrBadToken, exitBadToken},
+	{debrid.ErrPermissionDenied, exitPermissionDenied},
+	{debrid.ErrTwoFactorRequired, exitAccountLocked},
+	{debrid.ErrAccountLocked, exitAccountLocked},
+	{debrid.ErrRateLimited, exitRateLimited},
+	{debrid.ErrHosterUnsupported, exitHosterUnsupported},
+	{debrid.ErrHosterUnavailable, exitHosterUnavailable},
+	{debrid.ErrHosterLimitReached, exitHosterLimitReached},
+	{debrid.ErrPremiumRequired, exitPremiumRequired},
+	{debrid.ErrTooManyActiveDownloads, exitTooManyActiveDownloads},
+	{debrid.ErrIPNotAllowed, exitIPNotAllowed},
+	{debrid.ErrTrafficExhausted, exitTrafficExhausted},
+	{debrid.ErrFileUnavailable, exitFileUnavailable},
+	{debrid.ErrFileNotAllowed, exitFileNotAllowed},
+	{debrid.ErrServiceUnavailable, exitServiceUnavailable},
+	{debrid.ErrTorrentInvalid, exitTorrentInvalid},
+	{debrid.ErrTorrentTooBig, exitTorrentInvalid},
+}
+
+// exitCode returns the process exit code for an error
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, verify.ErrMismatch) {
+		return exitChecksumFailed
+	}
+	if errors.Is(err, diskspace.ErrInsufficientSpace) {
+		return exitNoSpace
+	}
+	if errors.Is(err, aria2.ErrDownloadFailed) {
+		return exitDownloadFailed
+	}
+	if errors.Is(err, aria2.ErrDownloadRemoved) {
+		return exitRemoved
+	}
+	for _, m := range rdExitCodes {
+		if errors.Is(err, m.err) {
+			return m.code
+		}
+	}
+	return exitFailure
+}
+
+// exitWithError prints err with a remediation hint, if one is known,
+// and exits with the matching exit code
+func exitWithError(prefix string, err error) {
+	reporter.Error(prefix, err)
+	fmt.Fprintf(os.Stderr, "%s: %v\n", prefix, err)
+	if hint := debrid.Hint(err); hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+	}
+	os.Exit(exitCode(err))
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/config"
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/secret"
+	"github.com/mhrsntrk/venaqui/internal/tui"
+	"github.com/mhrsntrk/venaqui/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create or edit the configuration file interactively",
+	Long: `Ask for the API token of the debrid provider (see --provider), download
+directory and aria2 settings, check them, and write the configuration file ('venaqui config path' prints
+where it is). An existing configuration can be edited; settings the wizard
+does not ask for are kept.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		saved, err := runSetupWizard()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Setup failed: %v\n", err)
+			os.Exit(1)
+		}
+		if !saved {
+			fmt.Println("Configuration left unchanged")
+		}
+	},
+}
+
+// runSetupWizard runs the setup wizard and writes the configuration file.
+// It reports whether the configuration was saved.
+func runSetupWizard() (bool, error) {
+	path, err := config.GetConfigFile()
+	if err != nil {
+		return false, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	existing := true
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		existing = false
+	}
+
+	settings, err := config.Inspect(path)
+	if err != nil {
+		return false, err
+	}
+	providerName, _ := settingValue(settings, "provider").(string)
+	providerName = strings.ToLower(providerName)
+	if _, ok := debrid.TokenURLs[providerName]; !ok {
+		return false, fmt.Errorf("unknown provider %q, use one of %s", providerName, strings.Join(debrid.Providers, ", "))
+	}
+	tokenKey := providerName + ".api_token"
+
+	initial, err := wizardDefaults(path, tokenKey)
+	if err != nil {
+		return false, err
+	}
+
+	wizard := tui.NewWizard(initial, tui.WizardOptions{
+		ConfigPath: path,
+		Existing:   existing,
+		Provider:   newProvider(providerName, "").Name(),
+		TokenURL:   debrid.TokenURLs[providerName],
+		ValidateToken: func(token string) error {
+			return newProvider(providerName, token).ValidateToken()
+		},
+		DetectAria2: func(rpcURL, secret string) error {
+			client, err := aria2.NewClient(rpcURL, secret)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			return client.Ping()
+		},
+	})
+
+	finalModel, err := tea.NewProgram(wizard).Run()
+	if err != nil {
+		return false, fmt.Errorf("TUI error: %w", err)
+	}
+	values, saved := finalModel.(tui.Wizard).Result()
+	if !saved {
+		return false, nil
+	}
+
+	changed := map[string]interface{}{
+		"download.default_dir": values.DownloadDir,
+		"download.connections": values.Connections,
+		"aria2.rpc_url":        values.Aria2RPCUrl,
+	}
+	secrets := map[string]string{
+		tokenKey:       values.APIToken,
+		"aria2.secret": values.Aria2Secret,
+	}
+
+	// Secrets go to the secret backend when one is configured
+	store, err := configuredSecretStore(path)
+	if err != nil {
+		return false, err
+	}
+	for name, value := range secrets {
+		if store == nil {
+			changed[name] = value
+			continue
+		}
+		if value == "" {
+			continue
+		}
+		if err := store.Set(name, value); err != nil {
+			return false, fmt.Errorf("failed to store %s: %w", name, err)
+		}
+		if _, err := config.Unset(path, name); err != nil {
+			return false, err
+		}
+	}
+
+	if err := config.Save(path, changed); err != nil {
+		return false, err
+	}
+	if err := utils.EnsureDirExists(values.DownloadDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create download directory: %v\n", err)
+	}
+
+	fmt.Printf("Configuration written to %s\n", path)
+	return true, nil
+}
+
+// configuredSecretStore returns the secret backend set in the
+// configuration file at path, or nil if secrets are kept in the file
+func configuredSecretStore(path string) (secret.Store, error) {
+	settings, err := config.Inspect(path)
+	if err != nil {
+		return nil, err
+	}
+	backend, _ := settingValue(settings, "secrets.backend").(string)
+	file, _ := settingValue(settings, "secrets.file").(string)
+	return config.NewSecretStore(backend, file, newPassphrase(file))
+}
+
+// wizardDefaults returns the settings in the configuration file at path,
+// or the defaults for settings it does not have. The API token is read
+// from tokenKey.
+func wizardDefaults(path, tokenKey string) (tui.WizardValues, error) {
+	downloadDir, err := config.GetDefaultDownloadDir()
+	if err != nil {
+		return tui.WizardValues{}, fmt.Errorf("failed to get default download directory: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	v.SetDefault("download.default_dir", downloadDir)
+	v.SetDefault("download.connections", 16)
+	v.SetDefault("aria2.rpc_url", "http://localhost:6800/jsonrpc")
+	if err := v.ReadInConfig(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return tui.WizardValues{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	values := tui.WizardValues{
+		APIToken:    v.GetString(tokenKey),
+		DownloadDir: v.GetString("download.default_dir"),
+		Aria2RPCUrl: v.GetString("aria2.rpc_url"),
+		Aria2Secret: v.GetString("aria2.secret"),
+		Connections: v.GetInt("download.connections"),
+	}
+	// An empty directory in the file means the OS default
+	if values.DownloadDir == "" {
+		values.DownloadDir = downloadDir
+	}
+	return values, nil
+}
+
+// needsSetup reports whether venaqui has nothing to run with yet: no
+// configuration file and no API token in the environment
+func needsSetup() bool {
+	if configExists() {
+		return false
+	}
+	name, err := config.DefaultProvider()
+	return err != nil || !config.TokenInEnv(name)
+}
+
+// configExists reports whether the configuration file exists
+func configExists() bool {
+	path, err := config.GetConfigFile()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
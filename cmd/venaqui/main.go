@@ -1,21 +1,34 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	"github.com/mhrsntrk/venaqui/internal/aria2"
 	"github.com/mhrsntrk/venaqui/internal/config"
-	"github.com/mhrsntrk/venaqui/internal/realdebrid"
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/direct"
+	"github.com/mhrsntrk/venaqui/internal/diskspace"
+	"github.com/mhrsntrk/venaqui/internal/duplicate"
+	"github.com/mhrsntrk/venaqui/internal/extract"
+	"github.com/mhrsntrk/venaqui/internal/headless"
+	"github.com/mhrsntrk/venaqui/internal/history"
+	"github.com/mhrsntrk/venaqui/internal/hooks"
+	"github.com/mhrsntrk/venaqui/internal/media"
+	"github.com/mhrsntrk/venaqui/internal/notify"
+	"github.com/mhrsntrk/venaqui/internal/organize"
+	"github.com/mhrsntrk/venaqui/internal/schedule"
 	"github.com/mhrsntrk/venaqui/internal/tui"
 	"github.com/mhrsntrk/venaqui/internal/utils"
+	"github.com/mhrsntrk/venaqui/internal/verify"
 )
 
 var (
@@ -28,11 +41,38 @@ var rootCmd = &cobra.Command{
 	Use:   "venaqui [link] [location]",
 	Short: "Download files via Real-Debrid and aria2",
 	Long: `Venaqui is a command-line tool with a Terminal User Interface (TUI) that
-leverages Real-Debrid premium links and aria2 for high-speed downloads.`,
+leverages Real-Debrid premium links and aria2 for high-speed downloads.
+AllDebrid and Premiumize can be used instead with --provider or the provider
+setting, and plain HTTP(S) and FTP URLs are downloaded without a provider
+with --direct.`,
 	Args: cobra.MinimumNArgs(1),
 	Run:  run,
 }
 
+var (
+	noTUI        bool
+	fullscreen   bool
+	quiet        bool
+	jsonOutput   bool
+	checksum     string
+	extractArc   bool
+	onDuplicate  string
+	startAt      string
+	startAfter   time.Duration
+	configPath   string
+	profileName  string
+	providerName string
+	directMode   bool
+	headers      []string
+	cookies      []string
+	userAgent    string
+	credentials  string
+)
+
+// reporter prints progress when the TUI is not used. It is set at the
+// start of run and defaults to plain text for other commands.
+var reporter = headless.NewReporter(headless.ModeText, os.Stdout, os.Stderr)
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number",
@@ -44,7 +84,47 @@ var versionCmd = &cobra.Command{
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Configuration file to use (default: $VENAQUI_CONFIG or config.yaml in the config directory)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Profile of the configuration file to use (default: $VENAQUI_PROFILE or the profile setting)")
+	rootCmd.PersistentFlags().StringVar(&providerName, "provider", "", "Debrid service to use: realdebrid, alldebrid or premiumize (default: the provider setting)")
+	cobra.OnInitialize(func() {
+		if configPath != "" {
+			config.SetConfigFile(configPath)
+		}
+		if profileName != "" {
+			config.SetProfile(profileName)
+		}
+		if providerName != "" {
+			config.SetProvider(providerName)
+		}
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			config.SetPassphrasePrompt(func() (string, error) {
+				return readPassword("Secrets passphrase: ")
+			})
+		}
+	})
+
+	rootCmd.Flags().BoolVar(&noTUI, "no-tui", false, "Print progress as plain text instead of starting the TUI")
+	rootCmd.Flags().BoolVar(&fullscreen, "fullscreen", false, "Run the TUI in the terminal's alternate screen (overrides tui.fullscreen)")
+	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print nothing but errors (implies --no-tui)")
+	rootCmd.Flags().BoolVar(&jsonOutput, "json", false, "Print newline-delimited JSON progress events (implies --no-tui)")
+	rootCmd.Flags().StringVar(&checksum, "checksum", "", "Verify the download against a checksum, e.g. sha256=<hex> or md5=<hex>")
+	rootCmd.Flags().BoolVar(&extractArc, "extract", false, "Extract archives after download (overrides extract.enabled)")
+	rootCmd.Flags().StringVar(&onDuplicate, "on-duplicate", "", "What to do when the download was seen before: ask, skip, resume or force (overrides duplicates.action)")
+	rootCmd.Flags().StringVar(&startAt, "at", "", "Start the download at a time such as 01:00 or \"2006-01-02 01:00\"")
+	rootCmd.Flags().DurationVar(&startAfter, "after", 0, "Start the download after a delay such as 2h or 30m")
+	rootCmd.MarkFlagsMutuallyExclusive("at", "after")
+	rootCmd.Flags().BoolVar(&directMode, "direct", false, "Download the URL with aria2 without a debrid provider; no API token is needed")
+	rootCmd.Flags().StringArrayVarP(&headers, "header", "H", nil, "HTTP header for direct downloads, such as \"Referer: https://example.com\" (repeatable)")
+	rootCmd.Flags().StringArrayVar(&cookies, "cookie", nil, "Cookie for direct downloads, such as session=abc (repeatable)")
+	rootCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent for direct downloads")
+	rootCmd.Flags().StringVar(&credentials, "user", "", "HTTP or FTP credentials for direct downloads as user:password")
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(clipCmd)
+	rootCmd.AddCommand(playCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(configCmd)
 }
 
 func main() {
@@ -55,13 +135,35 @@ func main() {
 }
 
 func run(cmd *cobra.Command, args []string) {
+	useTUI := !noTUI && !quiet && !jsonOutput && term.IsTerminal(int(os.Stdout.Fd()))
+	switch {
+	case jsonOutput:
+		reporter = headless.NewReporter(headless.ModeJSON, os.Stdout, os.Stderr)
+	case quiet:
+		reporter = headless.NewReporter(headless.ModeQuiet, os.Stdout, os.Stderr)
+	}
+
+	// Offer the setup wizard on first run, unless no token is needed
+	if useTUI && !directMode && term.IsTerminal(int(os.Stdin.Fd())) && needsSetup() {
+		if saved, err := runSetupWizard(); err != nil || !saved {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Setup failed: %v\n", err)
+			}
+			os.Exit(1)
+		}
+	}
+
 	// Load configuration
+	config.SetTokenOptional(directMode)
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
-		fmt.Fprintf(os.Stderr, "Please create a config file at ~/.venaqui/config.yaml\n")
+		fmt.Fprintf(os.Stderr, "Run 'venaqui init' to create a config file, or set %s\n", config.EnvVar("realdebrid.api_token"))
 		os.Exit(1)
 	}
+	for _, warning := range cfg.Warnings {
+		reporter.Warning(warning)
+	}
 
 	// Parse arguments
 	link := args[0]
@@ -75,6 +177,61 @@ func run(cmd *cobra.Command, args []string) {
 		fmt.Fprintf(os.Stderr, "Invalid URL: %v\n", err)
 		os.Exit(1)
 	}
+	if directMode && !direct.Supported(link) {
+		fmt.Fprintln(os.Stderr, "Invalid URL: --direct needs an HTTP(S) or FTP URL")
+		os.Exit(1)
+	}
+	directOpts := direct.Options{Headers: headers, Cookies: cookies, UserAgent: userAgent, User: credentials}
+	if err := directOpts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid direct download option: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Parse expected checksum
+	var expectedChecksum *verify.Checksum
+	if checksum != "" {
+		expectedChecksum, err = verify.ParseChecksum(checksum)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid checksum: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Hold the download until it is allowed to run
+	downloadSchedule, err := schedule.New(cfg.Schedule)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid schedule configuration: %v\n", err)
+		os.Exit(1)
+	}
+	switch {
+	case startAt != "":
+		at, err := schedule.ParseAt(startAt, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --at: %v\n", err)
+			os.Exit(1)
+		}
+		downloadSchedule = downloadSchedule.Until(at)
+	case startAfter > 0:
+		downloadSchedule = downloadSchedule.Until(time.Now().Add(startAfter))
+	}
+
+	duplicateSetting := cfg.Duplicates.Action
+	if onDuplicate != "" {
+		duplicateSetting = onDuplicate
+	}
+	duplicateAction, err := duplicate.ParseAction(duplicateSetting)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid duplicate action: %v\n", err)
+		os.Exit(1)
+	}
+	// Prompts are only shown to a user who is about to see the TUI
+	interactive := useTUI && term.IsTerminal(int(os.Stdin.Fd()))
+
+	organizer, err := organize.New(cfg.Organize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid organize configuration: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Normalize and validate download directory
 	downloadDir = utils.NormalizePath(downloadDir)
@@ -96,161 +253,279 @@ func run(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Initialize Real-Debrid client
-	rdClient := realdebrid.NewClient(cfg.RealDebridAPIToken)
-
-	// Validate token (optional check)
-	if err := rdClient.ValidateToken(); err != nil {
-		fmt.Fprintf(os.Stderr, "Real-Debrid API token validation failed: %v\n", err)
-		os.Exit(1)
+	// Check the history before asking the provider for the same link again
+	historyEntries := loadHistory()
+	earlyMatches := duplicate.Find(duplicate.Candidate{Link: link, TorrentHash: duplicate.MagnetHash(link)}, historyEntries, nil)
+	if decision, _ := resolveDuplicate(duplicateAction, interactive, link, earlyMatches); decision == duplicate.ActionSkip {
+		return
+	} else if decision != "" {
+		// Don't ask again once the link is resolved
+		duplicateAction = decision
 	}
 
-	var unrestrictedLink *realdebrid.UnrestrictedLink
-	var filename string
-
-	// Check if this is a torrent or magnet link
-	if utils.IsTorrentLink(link) || utils.IsMagnetLink(link) {
-		// Handle torrent/magnet link
-		fmt.Println("Adding torrent to Real-Debrid...")
-		var torrentResp *realdebrid.AddTorrentResponse
-		var err error
-
-		if utils.IsMagnetLink(link) {
-			torrentResp, err = rdClient.AddMagnet(link)
-		} else {
-			torrentResp, err = rdClient.AddTorrent(link)
+	var links []resolvedLink
+	if directMode {
+		links = []resolvedLink{resolveDirect(link, directOpts)}
+	} else {
+		// Initialize the debrid accounts and validate the token
+		debridAccounts := newAccounts(cfg)
+
+		links, err = debridAccounts.resolveLink(link, firstFile)
+		// A host the provider doesn't support may serve the file itself
+		if errors.Is(err, debrid.ErrHosterUnsupported) && direct.Supported(link) && !utils.IsTorrentLink(link) {
+			reporter.Status("The provider doesn't support this host, downloading the link directly...")
+			links, err = []resolvedLink{resolveDirect(link, directOpts)}, nil
+			directMode = true
 		}
-
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "RD API error: %v\n", err)
-			os.Exit(1)
+			exitWithError("Debrid API error", err)
 		}
+	}
+	resolved := links[0]
+	unrestrictedLink := resolved.unrestricted
+	filename := resolved.filename
+	downloadURL := resolved.downloadURL
+	torrentHash := resolved.torrentHash
 
-		// Check if files need to be selected
-		torrentInfo, err := rdClient.GetTorrentInfo(torrentResp.ID)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "RD API error: %v\n", err)
-			os.Exit(1)
-		}
+	// Initialize aria2 client
+	aria2Client, err := aria2.NewClient(cfg.Aria2RPCUrl, cfg.Aria2Secret)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "aria2 connection error: %v\n", err)
+		os.Exit(1)
+	}
+	defer aria2Client.Close()
 
-		// Select all files if needed
-		if torrentInfo.Status == "waiting_files_selection" {
-			fileIDs := []int{}
-			for _, file := range torrentInfo.Files {
-				fileIDs = append(fileIDs, file.ID)
-			}
-			if len(fileIDs) > 0 {
-				fmt.Println("Selecting files...")
-				if err := rdClient.SelectFiles(torrentResp.ID, fileIDs); err != nil {
-					fmt.Fprintf(os.Stderr, "RD API error: %v\n", err)
-					os.Exit(1)
-				}
-			}
-		}
+	// Choose the directory and file name from the organize rules
+	placement, err := organizer.Target(downloadDir, organize.Input{
+		Filename: filename,
+		Host:     unrestrictedLink.Host,
+		MimeType: unrestrictedLink.MimeType,
+		Size:     unrestrictedLink.Filesize,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid organize template: %v\n", err)
+		os.Exit(1)
+	}
 
-		fmt.Println("Waiting for torrent to be processed...")
-		torrentInfo, err = rdClient.WaitForTorrentReady(torrentResp.ID, 5*time.Minute)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "RD API error: %v\n", err)
-			os.Exit(1)
+	// Look for the same download in aria2, on disk and in the history
+	queue, err := aria2Client.GetQueue()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to check aria2 for duplicates: %v\n", err)
+	}
+	matches := duplicate.Find(duplicate.Candidate{
+		Link:        link,
+		DownloadURL: downloadURL,
+		RDID:        unrestrictedLink.ID,
+		TorrentHash: torrentHash,
+		Path:        placement.Path(),
+		Size:        unrestrictedLink.Filesize,
+	}, historyEntries, queue)
+
+	resumeGID := ""
+	decision, match := resolveDuplicate(duplicateAction, interactive, filename, matches)
+	switch decision {
+	case duplicate.ActionSkip:
+		return
+	case duplicate.ActionResume:
+		// Follow the queued download, or let aria2 continue the partial file
+		if match.Source == duplicate.SourceAria2 {
+			resumeGID = match.GID
 		}
-
-		if len(torrentInfo.Links) == 0 {
-			fmt.Fprintf(os.Stderr, "No download links available from torrent\n")
-			os.Exit(1)
+	case duplicate.ActionForce:
+		placement.Overwrite = true
+	default:
+		err = organizer.Resolve(placement)
+		if errors.Is(err, organize.ErrExists) {
+			reporter.Status(fmt.Sprintf("Skipping download, %s already exists", placement.Path()))
+			return
 		}
+	}
+	if err := utils.EnsureDirExists(placement.Dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create download directory: %v\n", err)
+		os.Exit(1)
+	}
+	filename = placement.Out
 
-		// Get the first link and validate it
-		downloadLink := torrentInfo.Links[0]
-		if downloadLink == "" {
-			fmt.Fprintf(os.Stderr, "Download link is empty\n")
-			os.Exit(1)
+	// Make sure the file fits; resumed downloads already have their space allocated
+	if decision != duplicate.ActionResume {
+		if err := diskspace.Check(placement.Dir, uint64(resolved.size), cfg.DiskReserve); err != nil {
+			exitWithError("Disk space check failed", err)
+		}
+	}
+	var diskGuard *diskspace.Guard
+	if cfg.DiskReserve > 0 {
+		diskGuard = diskspace.NewGuard(placement.Dir, cfg.DiskReserve, aria2Client)
+	}
+	var enforcer *schedule.Enforcer
+	if !downloadSchedule.Empty() {
+		enforcer = schedule.NewEnforcer(downloadSchedule, aria2Client)
+		// Space recovering must not resume downloads outside the schedule
+		if diskGuard != nil {
+			diskGuard.DeferTo(enforcer)
 		}
+	}
+	// Links are unrestricted right away; outside the schedule aria2 holds the download paused
+	held := enforcer != nil && !enforcer.Open(time.Now())
+
+	downloadOpts := aria2.DownloadOptions{
+		Dir:            placement.Dir,
+		Out:            downloadOut(placement, resolved.filename),
+		AllowOverwrite: placement.Overwrite,
+		Connections:    cfg.Connections,
+	}
+	if expectedChecksum != nil {
+		downloadOpts.Checksum = expectedChecksum.Aria2Option()
+	}
+	// Only the server of a direct link gets the headers and credentials
+	if directMode {
+		downloadOpts.Headers = directOpts.HeaderLines()
+		downloadOpts.UserAgent = directOpts.UserAgent
+		downloadOpts.User, downloadOpts.Password = directOpts.Credentials()
+	}
+	hookRunner := hooks.NewRunner(cfg.Hooks)
+	hookVars := hooks.Vars{
+		Link:     link,
+		Filename: filename,
+		Host:     unrestrictedLink.Host,
+		Dir:      placement.Dir,
+	}
+	startTime := time.Now()
 
-		// Links from /torrents/info/{id} can be:
-		// 1. Real-Debrid download page links (real-debrid.com/d/...) - need unrestricting to get direct link
-		// 2. Direct download links (rdeb.io/...) - already direct, use as-is
-		// 3. Hoster links - need unrestricting
-		
-		// Check if it's already a direct download link (rdeb.io)
-		if strings.Contains(downloadLink, "rdeb.io") {
-			// Already a direct download link - use it directly
-			unrestrictedLink = &realdebrid.UnrestrictedLink{
-				Link:     downloadLink,
-				Filename: torrentInfo.Filename,
-			}
-			filename = torrentInfo.Filename
-		} else {
-			// Real-Debrid download page link (real-debrid.com/d/...) or hoster link
-			// Both need to be unrestricted to get the direct download link
-			fmt.Println("Unrestricting torrent download link...")
-			unrestrictedLink, err = rdClient.UnrestrictLink(downloadLink)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "RD API error: %v\n", err)
-				fmt.Fprintf(os.Stderr, "Link: %s\n", downloadLink)
-				os.Exit(1)
-			}
-			// Use filename from torrent info, fallback to unrestricted link filename
-			filename = torrentInfo.Filename
-			if filename == "" {
-				filename = unrestrictedLink.Filename
-			}
+	var gid string
+	err = nil
+	if held {
+		reporter.Status(downloadSchedule.Status(time.Now()) + ", the download is queued paused")
+	}
+	if resumeGID != "" {
+		gid = resumeGID
+		if !held {
+			reporter.Status("Resuming download...")
+			err = resumeQueued(aria2Client, gid)
+		}
+		if path := match.Path; path != "" {
+			filename = filepath.Base(path)
+			hookVars.Filename = filename
 		}
 	} else {
-		// Handle regular hoster link
-		fmt.Println("Unrestricting link via Real-Debrid...")
-		var err error
-		unrestrictedLink, err = rdClient.UnrestrictLink(link)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "RD API error: %v\n", err)
-			os.Exit(1)
+		addOpts := downloadOpts
+		addOpts.Paused = held
+		if !held {
+			reporter.Status("Starting download...")
 		}
-		filename = unrestrictedLink.Filename
+		gid, err = aria2Client.AddDownloadWithOptions(downloadURL, addOpts)
 	}
-
-	// Initialize aria2 client
-	aria2Client, err := aria2.NewClient(cfg.Aria2RPCUrl, cfg.Aria2Secret)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "aria2 connection error: %v\n", err)
+		runHooks(hookRunner, hooks.EventError, hookVars, nil, startTime, err)
+		fmt.Fprintf(os.Stderr, "Download error: %v\n", err)
 		os.Exit(1)
 	}
-	defer aria2Client.Close()
 
-	// Add download to aria2
-	// Use the 'download' field from the API response, which contains the direct download link
-	// The 'link' field contains the original link, not the download link
-	downloadURL := unrestrictedLink.Download
-	if downloadURL == "" {
-		// Fallback to Link if Download is not available (for backwards compatibility)
-		downloadURL = unrestrictedLink.Link
+	hookVars.GID = gid
+	if enforcer != nil {
+		enforcer.Track(gid)
 	}
-	fmt.Println("Starting download...")
-	gid, err := aria2Client.AddDownload(downloadURL, downloadDir)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Download error: %v\n", err)
-		os.Exit(1)
+	if diskGuard != nil {
+		diskGuard.Track(gid)
+	}
+	// Describe videos while they download
+	isVideo := media.IsVideo(unrestrictedLink.MimeType, filename)
+	var mediaInfo *media.Info
+	if isVideo {
+		mediaInfo = describeMedia(resolved)
+	}
+	historyEntry := history.Entry{
+		Link:        link,
+		RDID:        unrestrictedLink.ID,
+		TorrentHash: torrentHash,
+		Filename:    filename,
+		Media:       mediaInfo,
+	}
+	runHooks(hookRunner, hooks.EventStart, hookVars, nil, startTime, nil)
+
+	var extractor *extract.Extractor
+	if cfg.Extract.Enabled || extractArc {
+		extractor = extract.NewExtractor(extract.Options{
+			Dest:           cfg.Extract.Dir,
+			Passwords:      cfg.Extract.Passwords,
+			DeleteArchives: cfg.Extract.DeleteArchives,
+		})
 	}
 
-	// Start TUI
-	if filename == "" {
-		filename = unrestrictedLink.Filename
-		if filename == "" {
-			// Use download URL for filename extraction if available
-			urlForFilename := downloadURL
-			if urlForFilename == "" {
-				urlForFilename = unrestrictedLink.Link
+	notifications := notify.New(cfg.Notifications)
+	if !useTUI {
+		status, result, err := runHeadless(aria2Client, diskGuard, enforcer, gid, filename, expectedChecksum, downloadOpts)
+		if err == nil && isVideo {
+			if historyEntry.Media == nil {
+				historyEntry.Media = probeMedia(status.GetFilePath())
+			}
+			if historyEntry.Media != nil {
+				reportMedia(historyEntry.Media)
+			}
+		}
+		recordHistory(historyEntry, status, result, err)
+		if err != nil {
+			runHooks(hookRunner, hooks.EventError, hookVars, status, startTime, err)
+			notifyOutcome(notifications, filename, err)
+			aria2Client.Close()
+			fmt.Fprintf(os.Stderr, "Download error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if extractor != nil {
+			if err := extractHeadless(aria2Client, extractor, status.GetFilePath()); err != nil {
+				runHooks(hookRunner, hooks.EventError, hookVars, status, startTime, err)
+				notifyOutcome(notifications, filename, err)
+				aria2Client.Close()
+				fmt.Fprintf(os.Stderr, "Extraction error: %v\n", err)
+				os.Exit(exitFailure)
 			}
-			filename = filepath.Base(urlForFilename)
 		}
+		runHooks(hookRunner, hooks.EventComplete, hookVars, status, startTime, nil)
+		notifyOutcome(notifications, filename, nil)
+		return
 	}
 
-	model := tui.InitialModel(aria2Client, gid, filename)
-	p := tea.NewProgram(model)
+	themes, theme := loadThemes(cfg.TUI.Theme)
+	model := tui.InitialModelWithOptions(aria2Client, gid, filename, tui.Options{
+		Checksum:        expectedChecksum,
+		DownloadOptions: downloadOpts,
+		Extractor:       extractor,
+		Hooks:           hookRunner,
+		HookVars:        hookVars,
+		Notifications:   notifications,
+		DiskGuard:       diskGuard,
+		Schedule:        enforcer,
+		Media:           mediaInfo,
+		ProbeMedia:      isVideo,
+		Themes:          themes,
+		Theme:           theme,
+	})
+	var programOpts []tea.ProgramOption
+	if cfg.TUI.Fullscreen || fullscreen {
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+	p := tea.NewProgram(model, programOpts...)
 
-	if _, err := p.Run(); err != nil {
+	finalModel, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "TUI error: %v\n", err)
 		os.Exit(1)
 	}
+	if m, ok := finalModel.(tui.Model); ok {
+		if m.Status() != nil {
+			historyEntry.Media = m.Media()
+			recordHistory(historyEntry, m.Status(), m.Verification(), m.Err())
+		}
+		if m.Err() != nil {
+			runHooks(hookRunner, hooks.EventError, hookVars, m.Status(), startTime, m.Err())
+			aria2Client.Close()
+			os.Exit(exitCode(m.Err()))
+		}
+		if result := m.Verification(); result != nil && !result.OK() {
+			runHooks(hookRunner, hooks.EventError, hookVars, m.Status(), startTime, fmt.Errorf("%w: %s", verify.ErrMismatch, result.Path))
+			aria2Client.Close()
+			os.Exit(exitChecksumFailed)
+		}
+	}
 }
 
 // ensureAria2Running checks if aria2 is running and starts it if needed
@@ -267,7 +542,7 @@ func ensureAria2Running(rpcURL string) error {
 	}
 
 	// aria2 is not running, try to start it
-	fmt.Println("Starting aria2 daemon...")
+	reporter.Status("Starting aria2 daemon...")
 
 	cmd := exec.Command("aria2c",
 		"--enable-rpc",
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/media"
+)
+
+// describeMedia asks the provider for the tracks of a video link. It
+// returns nil for other files, and when the provider can't describe the
+// video, which is then left to ffprobe once it is downloaded.
+func describeMedia(resolved resolvedLink) *media.Info {
+	describer, ok := resolved.provider.(debrid.MediaDescriber)
+	if !ok || resolved.unrestricted.ID == "" {
+		return nil
+	}
+	info, err := describer.MediaInfo(resolved.unrestricted.ID)
+	if err != nil {
+		reporter.Warning(fmt.Sprintf("Failed to get the video's tracks from %s: %v", resolved.provider.Name(), err))
+		return nil
+	}
+	return info
+}
+
+// probeMedia describes a downloaded video with ffprobe. Failures are
+// reported but never fatal; a missing ffprobe is silently skipped.
+func probeMedia(path string) *media.Info {
+	info, err := media.Probe(context.Background(), path)
+	if errors.Is(err, media.ErrNoProbe) {
+		return nil
+	}
+	if err != nil {
+		reporter.Warning(fmt.Sprintf("Failed to read the video's tracks: %v", err))
+		return nil
+	}
+	return info
+}
+
+// reportMedia prints the tracks of a video
+func reportMedia(info *media.Info) {
+	reporter.Status("Video: " + info.Summary())
+	if audio := info.AudioSummary(); audio != "" {
+		reporter.Status("Audio: " + audio)
+	}
+	if subtitles := info.SubtitleSummary(); subtitles != "" {
+		reporter.Status("Subtitles: " + subtitles)
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"github.com/mhrsntrk/venaqui/internal/config"
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/duplicate"
+	"github.com/mhrsntrk/venaqui/internal/media"
+	"github.com/mhrsntrk/venaqui/internal/utils"
+)
+
+var playCmd = &cobra.Command{
+	Use:   "play <link>",
+	Short: "Stream a video link to a player such as mpv or VLC",
+	Long: `Unrestrict a hoster, magnet or torrent link and open it in a player without
+waiting for the download. With Real-Debrid the transcoded streams are offered
+in each quality next to the original file; other providers play the original
+file. With --download the file is also downloaded with aria2, from the start
+first, while it plays.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runPlay,
+}
+
+var (
+	playQuality  string
+	playPlayer   string
+	playDownload bool
+)
+
+func init() {
+	playCmd.Flags().StringVar(&playQuality, "quality", "", "Stream quality such as 720p, or original (overrides play.quality)")
+	playCmd.Flags().StringVar(&playPlayer, "player", "", "Player command (overrides play.player)")
+	playCmd.Flags().BoolVar(&playDownload, "download", false, "Download the file with aria2 while it plays (overrides play.download)")
+}
+
+func runPlay(cmd *cobra.Command, args []string) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range cfg.Warnings {
+		reporter.Warning(warning)
+	}
+
+	link := args[0]
+	if err := utils.ValidateURL(link); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid URL: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Find the player before spending a link on it
+	configured := cfg.Play.Player
+	if playPlayer != "" {
+		configured = playPlayer
+	}
+	player, err := playerCommand(configured)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Player error: %v\n", err)
+		os.Exit(1)
+	}
+	quality := cfg.Play.Quality
+	if playQuality != "" {
+		quality = playQuality
+	}
+
+	var q *queuer
+	var debridAccounts *accounts
+	if cfg.Play.Download || playDownload {
+		duplicateAction, err := duplicate.ParseAction(cfg.Duplicates.Action)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid duplicate action: %v\n", err)
+			os.Exit(1)
+		}
+		q = newQueuer(cfg, duplicateAction)
+		defer q.aria2Client.Close()
+		q.sequential = true
+		debridAccounts = q.accounts
+	} else {
+		debridAccounts = newAccounts(cfg)
+	}
+
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	links, err := debridAccounts.resolveLink(link, chooseVideo(interactive))
+	if err != nil {
+		exitWithError("Debrid API error", err)
+	}
+	resolved := links[0]
+
+	streams := playableStreams(resolved)
+	stream, err := chooseStream(resolved.filename, streams, quality, interactive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Stream error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if q != nil {
+		if err := q.queueResolved(link, links); err != nil {
+			reporter.Warning(fmt.Sprintf("Failed to start the download: %v", err))
+		}
+	}
+
+	reporter.Status(fmt.Sprintf("Playing %s (%s) with %s...", resolved.filename, describeStream(stream), filepath.Base(player)))
+	if err := runPlayer(player, cfg.Play.Args, resolved.filename, stream.URL); err != nil {
+		fmt.Fprintf(os.Stderr, "Player error: %v\n", err)
+		os.Exit(1)
+	}
+	if q != nil {
+		reporter.Status("The download continues in aria2")
+	}
+}
+
+// playableStreams returns the streams a resolved link can be played from:
+// the original file, then the provider's transcoded streams, if any
+func playableStreams(resolved resolvedLink) []debrid.Stream {
+	streams := []debrid.Stream{{Format: "original", Quality: "original", URL: resolved.downloadURL}}
+	streamer, ok := resolved.provider.(debrid.Streamer)
+	if !ok || resolved.unrestricted.ID == "" {
+		return streams
+	}
+
+	reporter.Status("Fetching streams...")
+	media, err := streamer.Streams(resolved.unrestricted.ID)
+	if err != nil {
+		reporter.Warning(fmt.Sprintf("Failed to get streams, playing the original file: %v", err))
+		return streams
+	}
+	if media.Height > 0 || media.Duration > 0 {
+		reporter.Status(fmt.Sprintf("%s: %dx%d, %s", resolved.filename, media.Width, media.Height, media.Duration.Round(time.Second)))
+	}
+	return append(streams, media.Streams...)
+}
+
+// chooseStream picks the stream of a quality, or asks for one in a
+// terminal. The original file is the default.
+func chooseStream(name string, streams []debrid.Stream, quality string, interactive bool) (debrid.Stream, error) {
+	if quality != "" {
+		var qualities []string
+		for _, stream := range streams {
+			if strings.EqualFold(stream.Quality, quality) {
+				return stream, nil
+			}
+			qualities = append(qualities, stream.Quality)
+		}
+		return debrid.Stream{}, fmt.Errorf("no %s stream, choose one of %s", quality, strings.Join(qualities, ", "))
+	}
+	if !interactive || len(streams) == 1 {
+		return streams[0], nil
+	}
+
+	fmt.Printf("Streams of %s:\n", name)
+	for i, stream := range streams {
+		fmt.Printf("  %d. %s\n", i+1, describeStream(stream))
+	}
+	return streams[askChoice("Stream to play?", len(streams), 0)], nil
+}
+
+// chooseVideo returns a file chooser for torrents that picks the largest
+// video, or asks which file to play in a terminal
+func chooseVideo(interactive bool) fileChooser {
+	return func(torrent *debrid.Torrent) (int, error) {
+		// Files can only be told apart when there is a link per file
+		if len(torrent.Files) < 2 || len(torrent.Links) != len(torrent.Files) {
+			return 0, nil
+		}
+		best := largestVideo(torrent.Files)
+		if !interactive {
+			return best, nil
+		}
+
+		fmt.Printf("Files of %s:\n", torrent.Filename)
+		for i, file := range torrent.Files {
+			fmt.Printf("  %d. %s (%s)\n", i+1, file.Path, humanize.Bytes(uint64(file.Size)))
+		}
+		return askChoice("File to play?", len(torrent.Files), best), nil
+	}
+}
+
+// largestVideo returns the index of the largest video file, or of the
+// largest file if none looks like a video
+func largestVideo(files []debrid.TorrentFile) int {
+	best, bestVideo := 0, false
+	for i, file := range files {
+		video := media.IsVideo("", file.Path)
+		if (video && !bestVideo) || (video == bestVideo && file.Size > files[best].Size) {
+			best, bestVideo = i, video
+		}
+	}
+	return best
+}
+
+// askChoice asks for one of count numbered options and returns its index.
+// Enter, or the end of the input, picks the default.
+func askChoice(question string, count, def int) int {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("%s [%d] ", question, def+1)
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return def
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return def
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= count {
+			return n - 1
+		}
+	}
+}
+
+// describeStream names a stream in messages
+func describeStream(stream debrid.Stream) string {
+	if stream.Format == "original" {
+		return "original file"
+	}
+	return fmt.Sprintf("%s, %s", stream.Quality, stream.Format)
+}
+
+// playerCommand returns the player to run: the configured one, or mpv or
+// VLC when installed
+func playerCommand(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	for _, name := range []string{"mpv", "vlc"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	// VLC isn't on the PATH on macOS
+	if runtime.GOOS == "darwin" {
+		const vlc = "/Applications/VLC.app/Contents/MacOS/VLC"
+		if _, err := os.Stat(vlc); err == nil {
+			return vlc, nil
+		}
+	}
+	return "", errors.New("no player found: install mpv or VLC, or set play.player")
+}
+
+// runPlayer plays a stream and waits for the player to exit
+func runPlayer(player string, args []string, title, url string) error {
+	args = append([]string{}, args...)
+	// mpv would show the stream's URL as the title
+	if strings.HasPrefix(filepath.Base(player), "mpv") {
+		args = append(args, "--force-media-title="+title)
+	}
+	cmd := exec.Command(player, append(args, url)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", filepath.Base(player), err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+func TestLargestVideo(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []debrid.TorrentFile
+		want  int
+	}{
+		{
+			name: "largest video",
+			files: []debrid.TorrentFile{
+				{Path: "/Movie/sample.mkv", Size: 10},
+				{Path: "/Movie/Movie.mkv", Size: 1000},
+				{Path: "/Movie/Movie.nfo", Size: 1},
+			},
+			want: 1,
+		},
+		{
+			name: "videos before larger files",
+			files: []debrid.TorrentFile{
+				{Path: "/Movie/extras.zip", Size: 5000},
+				{Path: "/Movie/Movie.mp4", Size: 1000},
+			},
+			want: 1,
+		},
+		{
+			name: "largest file without videos",
+			files: []debrid.TorrentFile{
+				{Path: "/Album/cover.jpg", Size: 10},
+				{Path: "/Album/album.flac", Size: 500},
+			},
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := largestVideo(tt.files); got != tt.want {
+				t.Errorf("largestVideo() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTorrent_ChooseVideo(t *testing.T) {
+	torrent := &debrid.Torrent{
+		Filename: "Movie.2024.1080p",
+		Files: []debrid.TorrentFile{
+			{Path: "/Movie.2024.1080p/Movie.2024.1080p.nfo", Size: 2},
+			{Path: "/Movie.2024.1080p/Sample/sample.mkv", Size: 50},
+			{Path: "/Movie.2024.1080p/Movie.2024.1080p.mkv", Size: 4000},
+		},
+		Links:  []string{"https://cdn/nfo", "https://cdn/sample", "https://cdn/movie"},
+		Direct: true,
+	}
+
+	links, err := resolveTorrent(&fakeProvider{torrent: torrent}, "id", chooseVideo(false))
+	if err != nil {
+		t.Fatalf("resolveTorrent() error = %v", err)
+	}
+	if len(links) != 1 || links[0].filename != "Movie.2024.1080p.mkv" || links[0].downloadURL != "https://cdn/movie" {
+		t.Errorf("resolveTorrent() = %+v, want only Movie.2024.1080p.mkv", links)
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/config"
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/diskspace"
+	"github.com/mhrsntrk/venaqui/internal/duplicate"
+	"github.com/mhrsntrk/venaqui/internal/history"
+	"github.com/mhrsntrk/venaqui/internal/notify"
+	"github.com/mhrsntrk/venaqui/internal/organize"
+	"github.com/mhrsntrk/venaqui/internal/schedule"
+	"github.com/mhrsntrk/venaqui/internal/utils"
+)
+
+// followInterval is how often queued downloads are checked for notifications
+const followInterval = 10 * time.Second
+
+// queuer resolves links through a debrid provider and queues them in aria2 for
+// the commands that run unattended. Downloads are handed to aria2 and only
+// followed for notifications, so hooks and history are not involved.
+type queuer struct {
+	cfg           *config.Config
+	accounts      *accounts
+	aria2Client   *aria2.Client
+	organizer     *organize.Organizer
+	downloadDir   string
+	action        duplicate.Action
+	schedule      *schedule.Enforcer    // Nil when no download windows are configured
+	notifications *notify.Notifications // Nil when notifications are disabled
+	sequential    bool                  // Download from the start first, for playing
+
+	mu       sync.Mutex
+	followed map[string]string // GID to file path of the downloads to notify about
+}
+
+// newQueuer connects to the debrid provider and aria2, starting aria2 if needed.
+// It exits on failure like the download command does.
+func newQueuer(cfg *config.Config, action duplicate.Action) *queuer {
+	organizer, err := organize.New(cfg.Organize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid organize configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	downloadSchedule, err := schedule.New(cfg.Schedule)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid schedule configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	downloadDir := utils.NormalizePath(cfg.DefaultDownloadDir)
+	if err := utils.EnsureDirExists(downloadDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create download directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := ensureAria2Running(cfg.Aria2RPCUrl); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start aria2: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Please ensure aria2 is installed and accessible\n")
+		os.Exit(1)
+	}
+
+	debridAccounts := newAccounts(cfg)
+
+	aria2Client, err := aria2.NewClient(cfg.Aria2RPCUrl, cfg.Aria2Secret)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "aria2 connection error: %v\n", err)
+		os.Exit(1)
+	}
+
+	q := &queuer{
+		cfg:           cfg,
+		accounts:      debridAccounts,
+		aria2Client:   aria2Client,
+		organizer:     organizer,
+		downloadDir:   downloadDir,
+		action:        action,
+		notifications: notify.New(cfg.Notifications),
+		followed:      make(map[string]string),
+	}
+	if !downloadSchedule.Empty() {
+		q.schedule = schedule.NewEnforcer(downloadSchedule, aria2Client)
+	}
+	return q
+}
+
+// enforceSchedule pauses and resumes the queued downloads as the download
+// windows close and open, until ctx is cancelled
+func (q *queuer) enforceSchedule(ctx context.Context) {
+	if q.schedule == nil {
+		return
+	}
+
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if event := q.schedule.Poll(now); event != nil {
+				reporter.Status(event.String())
+			}
+		}
+	}
+}
+
+// followDownloads notifies when the queued downloads complete or fail,
+// until ctx is cancelled
+func (q *queuer) followDownloads(ctx context.Context) {
+	if q.notifications == nil {
+		return
+	}
+
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.notifyFinished()
+		}
+	}
+}
+
+// follow remembers a queued download to notify about once it finishes
+func (q *queuer) follow(gid, path string) {
+	if q.notifications == nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.followed[gid] = path
+}
+
+// notifyFinished sends a notification for every followed download that
+// completed or failed since the last check, and stops following it.
+// Downloads removed from aria2 are dropped silently.
+func (q *queuer) notifyFinished() {
+	q.mu.Lock()
+	followed := make(map[string]string, len(q.followed))
+	for gid, path := range q.followed {
+		followed[gid] = path
+	}
+	q.mu.Unlock()
+
+	for gid, path := range followed {
+		status, err := q.aria2Client.GetStatus(gid)
+		if err != nil {
+			continue
+		}
+		switch {
+		case status.IsComplete():
+			q.notifications.SendEvent(notify.EventComplete, "Download complete", path)
+		case status.IsError():
+			event := notify.EventError
+			if status.IsChecksumError() {
+				event = notify.EventRetryExhausted
+			}
+			q.notifications.SendEvent(event, "Download failed", fmt.Sprintf("%s: %s", path, strings.TrimSpace(status.ErrorMessage)))
+		case status.IsRemoved():
+		default:
+			continue
+		}
+
+		q.mu.Lock()
+		delete(q.followed, gid)
+		q.mu.Unlock()
+	}
+}
+
+// notifyQueueFailed notifies that a link could not be queued
+func (q *queuer) notifyQueueFailed(name string, err error) {
+	q.notifications.SendEvent(notify.EventError, "Failed to queue download", fmt.Sprintf("%s: %s", name, strings.TrimSpace(err.Error())))
+}
+
+// queueLink resolves a hoster, magnet or torrent link and queues every
+// resulting file
+func (q *queuer) queueLink(link string) error {
+	if err := utils.ValidateURL(link); err != nil {
+		return err
+	}
+	links, err := q.accounts.resolveLink(link, nil)
+	if err != nil {
+		return err
+	}
+	return q.queueResolved(link, links)
+}
+
+// queueTorrent adds the contents of a torrent file to the debrid provider
+// and queues every file in it
+func (q *queuer) queueTorrent(data []byte) error {
+	links, err := q.accounts.resolve(func(p debrid.Provider) ([]resolvedLink, error) {
+		reporter.Status("Adding torrent to " + p.Name() + "...")
+		torrentID, err := p.SubmitTorrent(data)
+		if err != nil {
+			return nil, err
+		}
+		return resolveTorrent(p, torrentID, nil)
+	})
+	if err != nil {
+		return err
+	}
+	return q.queueResolved("", links)
+}
+
+// queueResolved queues the resolved links of one original link
+func (q *queuer) queueResolved(link string, links []resolvedLink) error {
+	queue, err := q.aria2Client.GetQueue()
+	if err != nil {
+		reporter.Warning(fmt.Sprintf("Failed to check aria2 for duplicates: %v", err))
+	}
+	entries := loadHistory()
+
+	var errs []error
+	for _, resolved := range links {
+		if err := q.add(link, resolved, entries, queue); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", resolved.filename, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// add places a resolved link, checks it for duplicates and free space, and
+// adds it to aria2
+func (q *queuer) add(link string, resolved resolvedLink, entries []history.Entry, queue []*aria2.DownloadStatus) error {
+	unrestrictedLink := resolved.unrestricted
+	placement, err := q.organizer.Target(q.downloadDir, organize.Input{
+		Filename: resolved.filename,
+		Host:     unrestrictedLink.Host,
+		MimeType: unrestrictedLink.MimeType,
+		Size:     resolved.size,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid organize template: %w", err)
+	}
+
+	matches := duplicate.Find(duplicate.Candidate{
+		Link:        link,
+		DownloadURL: resolved.downloadURL,
+		RDID:        unrestrictedLink.ID,
+		TorrentHash: resolved.torrentHash,
+		Path:        placement.Path(),
+		Size:        resolved.size,
+	}, entries, queue)
+
+	// Nobody is around to answer, so ask skips
+	decision, match := resolveDuplicate(q.action, false, resolved.filename, matches)
+	switch decision {
+	case duplicate.ActionSkip:
+		return nil
+	case duplicate.ActionResume:
+		if match.Source == duplicate.SourceAria2 {
+			if q.schedule != nil {
+				q.schedule.Track(match.GID)
+				if !q.schedule.Open(time.Now()) {
+					return nil
+				}
+			}
+			q.follow(match.GID, placement.Path())
+			return resumeQueued(q.aria2Client, match.GID)
+		}
+	case duplicate.ActionForce:
+		placement.Overwrite = true
+	default:
+		err = q.organizer.Resolve(placement)
+		if errors.Is(err, organize.ErrExists) {
+			reporter.Status(fmt.Sprintf("Skipping download, %s already exists", placement.Path()))
+			return nil
+		}
+	}
+	if err := utils.EnsureDirExists(placement.Dir); err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	if decision != duplicate.ActionResume {
+		if err := diskspace.Check(placement.Dir, uint64(resolved.size), q.cfg.DiskReserve); err != nil {
+			return err
+		}
+	}
+
+	// Outside the download windows aria2 holds the download paused
+	held := q.schedule != nil && !q.schedule.Open(time.Now())
+	gid, err := q.aria2Client.AddDownloadWithOptions(resolved.downloadURL, aria2.DownloadOptions{
+		Dir:            placement.Dir,
+		Out:            downloadOut(placement, resolved.filename),
+		AllowOverwrite: placement.Overwrite,
+		Paused:         held,
+		Connections:    q.cfg.Connections,
+		Sequential:     q.sequential,
+	})
+	if err != nil {
+		return err
+	}
+	if q.schedule != nil {
+		q.schedule.Track(gid)
+	}
+	q.follow(gid, placement.Path())
+	if held {
+		reporter.Status(fmt.Sprintf("Queued %s paused (%s). %s", placement.Path(), gid, q.schedule.Status(time.Now())))
+		return nil
+	}
+	reporter.Status(fmt.Sprintf("Queued %s (%s)", placement.Path(), gid))
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/direct"
+	"github.com/mhrsntrk/venaqui/internal/duplicate"
+	"github.com/mhrsntrk/venaqui/internal/organize"
+	"github.com/mhrsntrk/venaqui/internal/utils"
+)
+
+// resolvedLink is a direct download link obtained through a debrid provider
+type resolvedLink struct {
+	unrestricted *debrid.Link
+	provider     debrid.Provider // Provider the link was resolved with, nil for direct links
+	filename     string
+	downloadURL  string
+	torrentHash  string // Info hash of torrent and magnet links
+	size         int64  // Expected size, 0 if unknown
+}
+
+// fileChooser picks the file of a torrent to download when not every file is
+// wanted, returning its index in the torrent's links
+type fileChooser func(torrent *debrid.Torrent) (int, error)
+
+// firstFile chooses the first file of a torrent
+func firstFile(*debrid.Torrent) (int, error) {
+	return 0, nil
+}
+
+// resolveLink turns a hoster, magnet or torrent link into direct download
+// links. Torrents yield a link per file when choose is nil, and only the
+// chosen one otherwise.
+func resolveLink(p debrid.Provider, link string, choose fileChooser) ([]resolvedLink, error) {
+	if !utils.IsTorrentLink(link) && !utils.IsMagnetLink(link) {
+		// Handle regular hoster link
+		reporter.Status("Unrestricting link via " + p.Name() + "...")
+		unrestrictedLink, err := p.Unrestrict(link)
+		if err != nil {
+			return nil, err
+		}
+		resolved := newResolvedLink(unrestrictedLink, unrestrictedLink.Filename, unrestrictedLink.Filesize)
+		resolved.provider = p
+		return []resolvedLink{resolved}, nil
+	}
+
+	reporter.Status("Adding torrent to " + p.Name() + "...")
+	var torrentID string
+	var err error
+	if utils.IsMagnetLink(link) {
+		torrentID, err = p.SubmitMagnet(link)
+	} else {
+		var data []byte
+		if data, err = debrid.DownloadTorrent(link); err == nil {
+			torrentID, err = p.SubmitTorrent(data)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := resolveTorrent(p, torrentID, choose)
+	if hash := duplicate.MagnetHash(link); hash != "" {
+		for i := range links {
+			if links[i].torrentHash == "" {
+				links[i].torrentHash = hash
+			}
+		}
+	}
+	return links, err
+}
+
+// resolveTorrent waits until the provider has downloaded every file of a
+// torrent and unrestricts its links, or only the chosen one if choose is set
+func resolveTorrent(p debrid.Provider, torrentID string, choose fileChooser) ([]resolvedLink, error) {
+	reporter.Status("Waiting for torrent to be processed...")
+	torrent, err := p.WaitForTorrent(torrentID, 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	var torrentSize int64
+	for _, file := range torrent.Files {
+		torrentSize += file.Size
+	}
+
+	if len(torrent.Links) == 0 {
+		return nil, errors.New("no download links available from torrent")
+	}
+	first, downloadLinks := 0, torrent.Links
+	if choose != nil {
+		if first, err = choose(torrent); err != nil {
+			return nil, err
+		}
+		downloadLinks = downloadLinks[first : first+1]
+	}
+
+	var links []resolvedLink
+	for i, downloadLink := range downloadLinks {
+		if downloadLink == "" {
+			return links, errors.New("download link is empty")
+		}
+
+		// A link per file is named and sized after its file; a link bundling
+		// every file is named after the torrent and may need all of its space
+		filename, size := torrent.Filename, torrentSize
+		if len(torrent.Links) == len(torrent.Files) {
+			filename, size = filepath.Base(torrent.Files[first+i].Path), torrent.Files[first+i].Size
+		}
+
+		// Links of some providers are already direct download links; the
+		// others point to the provider's hoster and need unrestricting
+		unrestrictedLink := &debrid.Link{Filename: filename, Download: downloadLink}
+		if !torrent.Direct {
+			reporter.Status("Unrestricting torrent download link...")
+			unrestrictedLink, err = p.Unrestrict(downloadLink)
+			if err != nil {
+				return links, fmt.Errorf("failed to unrestrict %s: %w", downloadLink, err)
+			}
+			// The hoster knows the name of the file it serves
+			if unrestrictedLink.Filename != "" {
+				filename = unrestrictedLink.Filename
+			}
+		}
+
+		if unrestrictedLink.Filesize > 0 {
+			size = unrestrictedLink.Filesize
+		}
+		resolved := newResolvedLink(unrestrictedLink, filename, size)
+		resolved.torrentHash = strings.ToLower(torrent.Hash)
+		resolved.provider = p
+		links = append(links, resolved)
+	}
+
+	return links, nil
+}
+
+// newResolvedLink picks a file name for an unrestricted link
+func newResolvedLink(unrestrictedLink *debrid.Link, filename string, size int64) resolvedLink {
+	if filename == "" {
+		filename = unrestrictedLink.Filename
+		if filename == "" {
+			filename = filepath.Base(unrestrictedLink.Download)
+		}
+	}
+
+	return resolvedLink{
+		unrestricted: unrestrictedLink,
+		filename:     filename,
+		downloadURL:  unrestrictedLink.Download,
+		size:         size,
+	}
+}
+
+// downloadOut returns the file name to pass to aria2 for a placement. The
+// placed name is always passed, so the file lands where the conflict policy,
+// the history and duplicate checks expect it rather than wherever aria2's own
+// renaming puts it. Only a link without a known name is left to aria2.
+func downloadOut(placement *organize.Placement, filename string) string {
+	if filename == "" {
+		return ""
+	}
+	return placement.Out
+}
+
+// resolveDirect describes a link downloaded without a debrid provider
+func resolveDirect(link string, opts direct.Options) resolvedLink {
+	reporter.Status("Checking direct link...")
+	probed := direct.Probe(link, opts)
+	return newResolvedLink(probed, probed.Filename, probed.Filesize)
+}
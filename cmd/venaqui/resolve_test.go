@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/organize"
+)
+
+// fakeProvider returns a fixed torrent and unrestricts its links
+type fakeProvider struct {
+	debrid.Provider
+	torrent *debrid.Torrent
+	links   map[string]*debrid.Link
+}
+
+func (f *fakeProvider) Name() string { return "Fake" }
+
+func (f *fakeProvider) WaitForTorrent(id string, maxWait time.Duration) (*debrid.Torrent, error) {
+	return f.torrent, nil
+}
+
+func (f *fakeProvider) Unrestrict(link string) (*debrid.Link, error) {
+	return f.links[link], nil
+}
+
+func TestResolveTorrent_MultiFileFirstOnly(t *testing.T) {
+	torrent := &debrid.Torrent{
+		Filename: "Show.S01.1080p",
+		Files: []debrid.TorrentFile{
+			{Path: "/Show.S01.1080p/Show.S01E01.mkv", Size: 100},
+			{Path: "/Show.S01.1080p/Show.S01E02.mkv", Size: 200},
+		},
+		Links: []string{"https://hoster/1", "https://hoster/2"},
+	}
+
+	tests := []struct {
+		name   string
+		direct bool
+		links  map[string]*debrid.Link
+	}{
+		{"direct links", true, nil},
+		{"hoster links", false, map[string]*debrid.Link{
+			"https://hoster/1": {Filename: "Show.S01E01.mkv", Download: "https://cdn/1"},
+		}},
+		{"hoster links without a name", false, map[string]*debrid.Link{
+			"https://hoster/1": {Download: "https://cdn/1"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			torrent.Direct = tt.direct
+			links, err := resolveTorrent(&fakeProvider{torrent: torrent, links: tt.links}, "id", firstFile)
+			if err != nil {
+				t.Fatalf("resolveTorrent() error = %v", err)
+			}
+			if len(links) != 1 || links[0].filename != "Show.S01E01.mkv" || links[0].size != 100 {
+				t.Errorf("resolveTorrent() = %+v, want Show.S01E01.mkv of 100 bytes", links)
+			}
+		})
+	}
+}
+
+func TestDownloadOut(t *testing.T) {
+	tests := []struct {
+		out, filename, want string
+	}{
+		{"Show.S01E01.mkv", "Show.S01E01.mkv", "Show.S01E01.mkv"},
+		{"unknown", "", ""},
+		{"Show.S01E01 (1).mkv", "Show.S01E01.mkv", "Show.S01E01 (1).mkv"},
+		{"2026-10-19 Show.S01E01.mkv", "Show.S01E01.mkv", "2026-10-19 Show.S01E01.mkv"},
+	}
+	for _, tt := range tests {
+		placement := &organize.Placement{Dir: "/downloads", Out: tt.out}
+		if got := downloadOut(placement, tt.filename); got != tt.want {
+			t.Errorf("downloadOut(%q, %q) = %q, want %q", tt.out, tt.filename, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/mhrsntrk/venaqui/internal/config"
+	"github.com/mhrsntrk/venaqui/internal/tui"
+)
+
+// loadThemes returns the built-in themes and those in the themes directory
+// next to the configuration, and the index of the named one. Problems are
+// reported and the default theme is used.
+func loadThemes(name string) ([]tui.Theme, int) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		reporter.Warning("Failed to find the themes directory: " + err.Error())
+		return tui.BuiltinThemes, 0
+	}
+
+	themes, warnings := tui.LoadThemes(filepath.Join(dir, "themes"))
+	for _, warning := range warnings {
+		reporter.Warning(warning)
+	}
+	theme, err := tui.SelectTheme(themes, name)
+	if err != nil {
+		reporter.Warning("Invalid tui.theme: " + err.Error())
+	}
+	return themes, theme
+}
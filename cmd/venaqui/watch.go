@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/mhrsntrk/venaqui/internal/config"
+	"github.com/mhrsntrk/venaqui/internal/duplicate"
+	"github.com/mhrsntrk/venaqui/internal/utils"
+	"github.com/mhrsntrk/venaqui/internal/watch"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [dir]",
+	Short: "Queue downloads from .torrent, .magnet and .txt files dropped into a folder",
+	Long: `Watch a folder and queue every .torrent, .magnet and .txt link list dropped
+into it through the debrid provider and aria2. Handled files are moved into the
+processed or failed subfolder. The folder defaults to watch.dir from the
+config file.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runWatch,
+}
+
+func runWatch(cmd *cobra.Command, args []string) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range cfg.Warnings {
+		reporter.Warning(warning)
+	}
+
+	dir := cfg.Watch.Dir
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	if dir == "" {
+		fmt.Fprintln(os.Stderr, "No folder to watch: pass one or set watch.dir in the config file")
+		os.Exit(1)
+	}
+	dir = utils.NormalizePath(dir)
+	if err := utils.EnsureDirExists(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create watch folder: %v\n", err)
+		os.Exit(1)
+	}
+
+	duplicateAction, err := duplicate.ParseAction(cfg.Duplicates.Action)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid duplicate action: %v\n", err)
+		os.Exit(1)
+	}
+	q := newQueuer(cfg, duplicateAction)
+	defer q.aria2Client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go q.enforceSchedule(ctx)
+	go q.followDownloads(ctx)
+
+	reporter.Status(fmt.Sprintf("Watching %s for .torrent, .magnet and .txt files...", dir))
+	onError := func(err error) {
+		reporter.Warning(err.Error())
+	}
+	if err := watch.New(dir, q.handleFile).Run(ctx, onError); err != nil {
+		q.aria2Client.Close()
+		fmt.Fprintf(os.Stderr, "Watch error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// handleFile queues every item of a dropped file and returns the items that
+// failed
+func (q *queuer) handleFile(path string, items []watch.Item) []watch.Failure {
+	reporter.Status(fmt.Sprintf("Processing %s (%d item(s))...", filepath.Base(path), len(items)))
+
+	var failures []watch.Failure
+	for _, item := range items {
+		var err error
+		if item.Link != "" {
+			err = q.queueLink(item.Link)
+		} else {
+			err = q.queueTorrent(item.Torrent)
+		}
+		if err != nil {
+			name := item.Link
+			if name == "" {
+				name = filepath.Base(path)
+			}
+			reporter.Warning(fmt.Sprintf("Failed to queue %s: %v", name, err))
+			q.notifyQueueFailed(name, err)
+			failures = append(failures, watch.Failure{Item: item, Err: fmt.Errorf("%s: %w", name, err)})
+		}
+	}
+	return failures
+}
@@ -0,0 +1,325 @@
+// Package alldebrid is a client for the AllDebrid API, implementing
+// debrid.Provider
+package alldebrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+const BaseURL = "https://api.alldebrid.com/v4"
+
+// agent identifies venaqui to AllDebrid, which requires it on every request
+const agent = "venaqui"
+
+// pollInterval is how often delayed links and torrents are checked
+const pollInterval = 2 * time.Second
+
+// Client handles communication with the AllDebrid API
+type Client struct {
+	apiToken   string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Client is a debrid.Provider
+var _ debrid.Provider = (*Client)(nil)
+
+// NewClient creates a new AllDebrid API client
+func NewClient(apiToken string) *Client {
+	return NewClientWithBaseURL(apiToken, BaseURL)
+}
+
+// NewClientWithBaseURL creates a new AllDebrid API client with a custom base URL (for testing)
+func NewClientWithBaseURL(apiToken, baseURL string) *Client {
+	return &Client{
+		apiToken: apiToken,
+		baseURL:  baseURL,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// response is the envelope of every AllDebrid response
+type response struct {
+	Status string          `json:"status"` // success or error
+	Data   json.RawMessage `json:"data"`
+	Error  *errorBody      `json:"error"`
+}
+
+// do sends a request and decodes the data of the response into result
+func (c *Client) do(req *http.Request, result interface{}) error {
+	query := req.URL.Query()
+	query.Set("agent", agent)
+	req.URL.RawQuery = query.Encode()
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Errors usually come with status 200
+	var envelope response
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Status != "success" {
+		return decodeError(resp.StatusCode, body)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// get calls an endpoint with query parameters
+func (c *Client) get(path string, params url.Values, result interface{}) error {
+	req, err := http.NewRequest("GET", c.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return c.do(req, result)
+}
+
+// post calls an endpoint with form data
+func (c *Client) post(path string, form url.Values, result interface{}) error {
+	req, err := http.NewRequest("POST", c.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req, result)
+}
+
+// Name implements debrid.Provider
+func (c *Client) Name() string {
+	return "AllDebrid"
+}
+
+// user is the account returned by /user
+type user struct {
+	Username     string `json:"username"`
+	IsPremium    bool   `json:"isPremium"`
+	PremiumUntil int64  `json:"premiumUntil"` // Unix time
+}
+
+// ValidateToken implements debrid.Provider
+func (c *Client) ValidateToken() error {
+	_, err := c.GetAccount()
+	return err
+}
+
+// GetAccount implements debrid.Provider
+func (c *Client) GetAccount() (*debrid.Account, error) {
+	var result struct {
+		User user `json:"user"`
+	}
+	if err := c.get("/user", url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	account := &debrid.Account{Username: result.User.Username, Premium: result.User.IsPremium}
+	if result.User.PremiumUntil > 0 {
+		account.Expiration = time.Unix(result.User.PremiumUntil, 0)
+	}
+	return account, nil
+}
+
+// unlockedLink is the response of /link/unlock
+type unlockedLink struct {
+	ID       string `json:"id"`
+	Link     string `json:"link"` // Direct download link, empty while delayed
+	Host     string `json:"host"`
+	Filename string `json:"filename"`
+	Filesize int64  `json:"filesize"`
+	Delayed  int64  `json:"delayed"` // ID to wait for when the link is generated later
+}
+
+// Unrestrict implements debrid.Unrestrictor
+func (c *Client) Unrestrict(link string) (*debrid.Link, error) {
+	var unlocked unlockedLink
+	if err := c.get("/link/unlock", url.Values{"link": {link}}, &unlocked); err != nil {
+		return nil, err
+	}
+
+	download := unlocked.Link
+	if download == "" && unlocked.Delayed != 0 {
+		var err error
+		if download, err = c.waitForDelayed(unlocked.Delayed, 5*time.Minute); err != nil {
+			return nil, err
+		}
+	}
+	return &debrid.Link{
+		ID:       unlocked.ID,
+		Filename: unlocked.Filename,
+		Filesize: unlocked.Filesize,
+		Host:     unlocked.Host,
+		Download: download,
+	}, nil
+}
+
+// waitForDelayed waits for a link AllDebrid generates in the background
+func (c *Client) waitForDelayed(id int64, maxWait time.Duration) (string, error) {
+	deadline := time.Now().Add(maxWait)
+	for {
+		var delayed struct {
+			Status int    `json:"status"` // 1 processing, 2 ready, 3 failed
+			Link   string `json:"link"`
+		}
+		if err := c.get("/link/delayed", url.Values{"id": {strconv.FormatInt(id, 10)}}, &delayed); err != nil {
+			return "", err
+		}
+
+		switch delayed.Status {
+		case 2:
+			return delayed.Link, nil
+		case 3:
+			return "", fmt.Errorf("AllDebrid failed to generate the link")
+		}
+
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("timeout waiting for the link to be generated")
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// GetHostRegexes implements debrid.Unrestrictor
+func (c *Client) GetHostRegexes() ([]string, error) {
+	var result struct {
+		Hosts map[string]struct {
+			Regexp  string   `json:"regexp"`
+			Regexps []string `json:"regexps"`
+		} `json:"hosts"`
+	}
+	if err := c.get("/hosts", url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	var regexes []string
+	for _, host := range result.Hosts {
+		if host.Regexp != "" {
+			regexes = append(regexes, host.Regexp)
+		}
+		regexes = append(regexes, host.Regexps...)
+	}
+	return regexes, nil
+}
+
+// uploadedMagnet is a magnet or torrent file added with /magnet/upload
+type uploadedMagnet struct {
+	ID    int64      `json:"id"`
+	Error *errorBody `json:"error"`
+}
+
+// magnetID returns the ID of the only uploaded magnet, or its error
+func magnetID(uploaded []uploadedMagnet) (string, error) {
+	if len(uploaded) == 0 {
+		return "", fmt.Errorf("AllDebrid returned no torrent")
+	}
+	if uploaded[0].Error != nil {
+		return "", newAPIError(200, uploaded[0].Error)
+	}
+	return strconv.FormatInt(uploaded[0].ID, 10), nil
+}
+
+// SubmitMagnet implements debrid.TorrentProvider
+func (c *Client) SubmitMagnet(magnet string) (string, error) {
+	var result struct {
+		Magnets []uploadedMagnet `json:"magnets"`
+	}
+	if err := c.post("/magnet/upload", url.Values{"magnets[]": {magnet}}, &result); err != nil {
+		return "", err
+	}
+	return magnetID(result.Magnets)
+}
+
+// SubmitTorrent implements debrid.TorrentProvider
+func (c *Client) SubmitTorrent(data []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("files[]", "venaqui.torrent")
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req, err := http.NewRequest("POST", c.baseURL+"/magnet/upload/file", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var result struct {
+		Files []uploadedMagnet `json:"files"`
+	}
+	if err := c.do(req, &result); err != nil {
+		return "", err
+	}
+	return magnetID(result.Files)
+}
+
+// magnetStatus is a torrent returned by /magnet/status
+type magnetStatus struct {
+	ID         int64  `json:"id"`
+	Filename   string `json:"filename"`
+	Hash       string `json:"hash"`
+	Status     string `json:"status"`
+	StatusCode int    `json:"statusCode"` // 0-3 processing, 4 ready, higher failed
+	Links      []struct {
+		Link     string `json:"link"`
+		Filename string `json:"filename"`
+		Size     int64  `json:"size"`
+	} `json:"links"`
+}
+
+// WaitForTorrent implements debrid.TorrentProvider. AllDebrid downloads
+// every file of a torrent and returns a link per file.
+func (c *Client) WaitForTorrent(id string, maxWait time.Duration) (*debrid.Torrent, error) {
+	deadline := time.Now().Add(maxWait)
+	for {
+		var result struct {
+			Magnets magnetStatus `json:"magnets"`
+		}
+		if err := c.get("/magnet/status", url.Values{"id": {id}}, &result); err != nil {
+			return nil, err
+		}
+
+		magnet := result.Magnets
+		if magnet.StatusCode == 4 {
+			torrent := &debrid.Torrent{ID: id, Filename: magnet.Filename, Hash: magnet.Hash}
+			for _, link := range magnet.Links {
+				torrent.Files = append(torrent.Files, debrid.TorrentFile{Path: link.Filename, Size: link.Size})
+				torrent.Links = append(torrent.Links, link.Link)
+			}
+			return torrent, nil
+		}
+		if magnet.StatusCode > 4 {
+			return nil, fmt.Errorf("torrent failed: %s", magnet.Status)
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout waiting for torrent to be ready")
+		}
+		time.Sleep(pollInterval)
+	}
+}
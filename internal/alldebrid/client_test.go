@@ -0,0 +1,118 @@
+package alldebrid
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+// newFakeServer serves canned AllDebrid responses by path
+func newFakeServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("agent") != "venaqui" {
+			t.Errorf("%s called without the agent parameter", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("%s called with %q, want Bearer test-key", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestUnrestrict(t *testing.T) {
+	server := newFakeServer(t, map[string]string{
+		"/link/unlock":  `{"status":"success","data":{"id":"abc","link":"","host":"rapidgator","filename":"file.zip","filesize":1024,"delayed":42}}`,
+		"/link/delayed": `{"status":"success","data":{"status":2,"link":"https://cdn.alldebrid.com/dl/file.zip"}}`,
+	})
+
+	link, err := NewClientWithBaseURL("test-key", server.URL).Unrestrict("https://rapidgator.net/file/abc")
+	if err != nil {
+		t.Fatalf("Unrestrict() error = %v", err)
+	}
+	want := debrid.Link{ID: "abc", Filename: "file.zip", Filesize: 1024, Host: "rapidgator", Download: "https://cdn.alldebrid.com/dl/file.zip"}
+	if *link != want {
+		t.Errorf("Unrestrict() = %+v, want %+v", *link, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		body string
+		want error
+	}{
+		{body: `{"status":"error","error":{"code":"AUTH_BAD_APIKEY","message":"The auth apikey is invalid"}}`, want: debrid.ErrBadToken},
+		{body: `{"status":"error","error":{"code":"LINK_DOWN","message":"This link is not available"}}`, want: debrid.ErrFileUnavailable},
+		{body: `{"status":"error","error":{"code":"MUST_BE_PREMIUM","message":"You must be premium"}}`, want: debrid.ErrPremiumRequired},
+		{body: `not json`, want: nil},
+	}
+
+	for _, tt := range tests {
+		server := newFakeServer(t, map[string]string{"/link/unlock": tt.body})
+		_, err := NewClientWithBaseURL("test-key", server.URL).Unrestrict("https://example.com/file")
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("Unrestrict() with %s error = %v, want *APIError", tt.body, err)
+			continue
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("Unrestrict() with %s error = %v, want %v", tt.body, err, tt.want)
+		}
+	}
+}
+
+func TestTorrent(t *testing.T) {
+	server := newFakeServer(t, map[string]string{
+		"/magnet/upload": `{"status":"success","data":{"magnets":[{"magnet":"magnet:?xt=urn:btih:abc","hash":"abc","id":7}]}}`,
+		"/magnet/status": `{"status":"success","data":{"magnets":{"id":7,"filename":"Show","hash":"abc","status":"Ready","statusCode":4,` +
+			`"links":[{"link":"https://alldebrid.com/f/1","filename":"e01.mkv","size":100},{"link":"https://alldebrid.com/f/2","filename":"e02.mkv","size":200}]}}}`,
+	})
+	client := NewClientWithBaseURL("test-key", server.URL)
+
+	id, err := client.SubmitMagnet("magnet:?xt=urn:btih:abc")
+	if err != nil || id != "7" {
+		t.Fatalf("SubmitMagnet() = %q, %v, want 7", id, err)
+	}
+	torrent, err := client.WaitForTorrent(id, 0)
+	if err != nil {
+		t.Fatalf("WaitForTorrent() error = %v", err)
+	}
+	if torrent.Filename != "Show" || len(torrent.Links) != 2 || torrent.Files[1] != (debrid.TorrentFile{Path: "e02.mkv", Size: 200}) {
+		t.Errorf("WaitForTorrent() = %+v, want both episodes", torrent)
+	}
+
+	// Errors of a single magnet come inside a successful response
+	server = newFakeServer(t, map[string]string{
+		"/magnet/upload": `{"status":"success","data":{"magnets":[{"magnet":"x","error":{"code":"MAGNET_INVALID_URI","message":"Invalid magnet"}}]}}`,
+	})
+	if _, err := NewClientWithBaseURL("test-key", server.URL).SubmitMagnet("x"); !errors.Is(err, debrid.ErrTorrentInvalid) {
+		t.Errorf("SubmitMagnet() error = %v, want ErrTorrentInvalid", err)
+	}
+}
+
+func TestGetAccount(t *testing.T) {
+	server := newFakeServer(t, map[string]string{
+		"/user": `{"status":"success","data":{"user":{"username":"alice","isPremium":true,"premiumUntil":1800000000}}}`,
+	})
+
+	account, err := NewClientWithBaseURL("test-key", server.URL).GetAccount()
+	if err != nil {
+		t.Fatalf("GetAccount() error = %v", err)
+	}
+	if account.Username != "alice" || !account.Premium || account.Expiration.Unix() != 1800000000 {
+		t.Errorf("GetAccount() = %+v, want alice with premium", account)
+	}
+}
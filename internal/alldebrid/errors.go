@@ -0,0 +1,93 @@
+package alldebrid
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+// codeErrors maps AllDebrid error codes to sentinel errors
+// See https://docs.alldebrid.com/#all-errors
+var codeErrors = map[string]error{
+	"AUTH_MISSING_APIKEY":       debrid.ErrBadToken,
+	"AUTH_BAD_APIKEY":           debrid.ErrBadToken,
+	"AUTH_BLOCKED":              debrid.ErrIPNotAllowed,
+	"AUTH_USER_BANNED":          debrid.ErrAccountLocked,
+	"NO_SERVER":                 debrid.ErrIPNotAllowed,
+	"MUST_BE_PREMIUM":           debrid.ErrPremiumRequired,
+	"FREE_TRIAL_LIMIT_REACHED":  debrid.ErrTrafficExhausted,
+	"LINK_HOST_NOT_SUPPORTED":   debrid.ErrHosterUnsupported,
+	"LINK_DOWN":                 debrid.ErrFileUnavailable,
+	"LINK_HOST_UNAVAILABLE":     debrid.ErrHosterUnavailable,
+	"LINK_HOST_FULL":            debrid.ErrHosterUnavailable,
+	"LINK_HOST_LIMIT_REACHED":   debrid.ErrHosterLimitReached,
+	"LINK_TOO_MANY_DOWNLOADS":   debrid.ErrTooManyActiveDownloads,
+	"DELAYED_INVALID_ID":        debrid.ErrNotFound,
+	"MAGNET_INVALID_ID":         debrid.ErrNotFound,
+	"MAGNET_NO_URI":             debrid.ErrTorrentInvalid,
+	"MAGNET_INVALID_URI":        debrid.ErrTorrentInvalid,
+	"MAGNET_INVALID_FILE":       debrid.ErrTorrentInvalid,
+	"MAGNET_FILE_UPLOAD_FAILED": debrid.ErrTorrentInvalid,
+	"MAGNET_TOO_LARGE":          debrid.ErrTorrentTooBig,
+	"MAGNET_MUST_BE_PREMIUM":    debrid.ErrPremiumRequired,
+	"MAGNET_TOO_MANY_ACTIVE":    debrid.ErrTooManyActiveDownloads,
+	"MAGNET_NO_SERVER":          debrid.ErrIPNotAllowed,
+}
+
+// statusErrors is used when the response carries no error code
+var statusErrors = map[int]error{
+	401: debrid.ErrBadToken,
+	403: debrid.ErrPermissionDenied,
+	404: debrid.ErrNotFound,
+	429: debrid.ErrRateLimited,
+	503: debrid.ErrServiceUnavailable,
+}
+
+// errorBody is the error of an AllDebrid response
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// APIError is an error response returned by the AllDebrid API
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Code == "" && e.Message == "" {
+		return fmt.Sprintf("AllDebrid API error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("AllDebrid API error: %s [%s]", e.Message, e.Code)
+}
+
+// Unwrap returns the sentinel error matching the response, if any
+func (e *APIError) Unwrap() error {
+	if err, ok := codeErrors[e.Code]; ok {
+		return err
+	}
+	return statusErrors[e.StatusCode]
+}
+
+// newAPIError builds an APIError from the error of a response, which may
+// be nil when the body couldn't be decoded
+func newAPIError(statusCode int, body *errorBody) error {
+	apiErr := &APIError{StatusCode: statusCode}
+	if body != nil {
+		apiErr.Code, apiErr.Message = body.Code, body.Message
+	}
+	return apiErr
+}
+
+// decodeError decodes the error of a failed response body
+func decodeError(statusCode int, body []byte) error {
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return newAPIError(statusCode, nil)
+	}
+	return newAPIError(statusCode, resp.Error)
+}
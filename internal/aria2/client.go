@@ -9,7 +9,7 @@ import (
 
 // Client wraps the aria2 RPC client
 type Client struct {
-	rpc arigo.Client
+	rpc *arigo.Client
 	ctx context.Context
 }
 
@@ -42,7 +42,7 @@ func NewClient(rpcURL, secret string) (*Client, error) {
 	}
 
 	return &Client{
-		rpc: client,
+		rpc: &client,
 		ctx: context.Background(),
 	}, nil
 }
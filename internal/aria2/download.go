@@ -2,17 +2,61 @@ package aria2
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/siku2/arigo"
 )
 
+// DownloadOptions holds per-download aria2 options
+type DownloadOptions struct {
+	Dir            string
+	Out            string // Output file name, empty to let aria2 decide
+	Checksum       string // aria2 checksum option, e.g. "sha-256=<hex>"
+	AllowOverwrite bool
+	Paused         bool     // Add the download paused, to be resumed later
+	Connections    int      // Connections to the server, 0 for the default of 16
+	Sequential     bool     // Download pieces roughly in order, for playing while downloading
+	Headers        []string // Extra HTTP headers such as "Referer: https://example.com"
+	UserAgent      string
+	User           string // HTTP or FTP user name
+	Password       string
+}
+
+// defaultConnections is used when DownloadOptions.Connections is not set
+const defaultConnections = 16
+
 // AddDownload adds a new download to aria2
 func (c *Client) AddDownload(url, downloadDir string) (string, error) {
+	return c.AddDownloadWithOptions(url, DownloadOptions{Dir: downloadDir})
+}
+
+// AddDownloadWithOptions adds a new download to aria2 with extra options
+func (c *Client) AddDownloadWithOptions(url string, opts DownloadOptions) (string, error) {
+	connections := opts.Connections
+	if connections <= 0 {
+		connections = defaultConnections
+	}
+
 	options := &arigo.Options{
-		Dir:                   downloadDir,
-		MaxConnectionPerServer: 16,
-		Split:                  16,
-		MinSplitSize:          1048576, // 1M in bytes
+		Dir:                    opts.Dir,
+		Out:                    opts.Out,
+		Checksum:               opts.Checksum,
+		AllowOverwrite:         opts.AllowOverwrite,
+		Pause:                  opts.Paused,
+		MaxConnectionPerServer: uint(connections),
+		Split:                  uint(connections),
+		MinSplitSize:           1048576, // 1M in bytes
+		// aria2 splits the header option on newlines
+		Header:     strings.Join(opts.Headers, "\n"),
+		UserAgent:  opts.UserAgent,
+		HTTPUser:   opts.User,
+		HTTPPasswd: opts.Password,
+		FTPUser:    opts.User,
+		FTPPasswd:  opts.Password,
+	}
+	if opts.Sequential {
+		options.StreamPieceSelector = "inorder"
 	}
 
 	gid, err := c.rpc.AddURI([]string{url}, options)
@@ -23,6 +67,27 @@ func (c *Client) AddDownload(url, downloadDir string) (string, error) {
 	return gid.GID, nil
 }
 
+// Redownload adds a finished download again under the same name,
+// overwriting the existing file. It returns the GID of the new download.
+func (c *Client) Redownload(status *DownloadStatus, opts DownloadOptions) (string, error) {
+	uri := status.GetURI()
+	if uri == "" {
+		return "", fmt.Errorf("failed to re-download: no URI for %s", status.GID)
+	}
+
+	opts.Dir = status.GetFileDirectory()
+	if len(status.Files) > 0 && status.Files[0].Path != "" {
+		opts.Out = filepath.Base(status.Files[0].Path)
+	}
+	opts.AllowOverwrite = true
+	opts.Paused = false
+
+	// Forget the old result so aria2 does not keep both entries around
+	_ = c.rpc.RemoveDownloadResult(status.GID)
+
+	return c.AddDownloadWithOptions(uri, opts)
+}
+
 // RemoveDownload removes a download from aria2
 func (c *Client) RemoveDownload(gid string) error {
 	return c.rpc.Remove(gid)
@@ -38,17 +103,59 @@ func (c *Client) ResumeDownload(gid string) error {
 	return c.rpc.Unpause(gid)
 }
 
-// GetActiveDownloads returns all active downloads
-func (c *Client) GetActiveDownloads() ([]string, error) {
-	statuses, err := c.rpc.TellActive("gid")
+// GetActiveDownloads returns the directories of all active downloads, by GID
+func (c *Client) GetActiveDownloads() (map[string]string, error) {
+	statuses, err := c.rpc.TellActive("gid", "dir")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active downloads: %w", err)
 	}
 
-	result := make([]string, len(statuses))
-	for i, status := range statuses {
-		result[i] = status.GID
+	result := make(map[string]string, len(statuses))
+	for _, status := range statuses {
+		result[status.GID] = status.Dir
 	}
 
 	return result, nil
 }
+
+// GetQueue returns the active and waiting downloads, including paused ones
+func (c *Client) GetQueue() ([]*DownloadStatus, error) {
+	keys := []string{"gid", "status", "totalLength", "completedLength", "dir", "files"}
+	active, err := c.rpc.TellActive(keys...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active downloads: %w", err)
+	}
+	waiting, err := c.rpc.TellWaiting(0, 1000, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waiting downloads: %w", err)
+	}
+
+	var queue []*DownloadStatus
+	for _, status := range append(active, waiting...) {
+		queue = append(queue, newDownloadStatus(status))
+	}
+	return queue, nil
+}
+
+// GetPendingFiles returns the paths of files that active and waiting downloads write to
+func (c *Client) GetPendingFiles() ([]string, error) {
+	active, err := c.rpc.TellActive("gid", "files")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active downloads: %w", err)
+	}
+	waiting, err := c.rpc.TellWaiting(0, 1000, "gid", "files")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waiting downloads: %w", err)
+	}
+
+	var paths []string
+	for _, status := range append(active, waiting...) {
+		for _, file := range status.Files {
+			if file.Path != "" {
+				paths = append(paths, file.Path)
+			}
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,111 @@
+package aria2
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// etaHalfLife is how long it takes for a speed sample to count half
+	// as much in the smoothed speed
+	etaHalfLife = 10 * time.Second
+
+	// etaStallGrace is how long the speed may drop to zero before the stall
+	// counts against the smoothed speed
+	etaStallGrace = 5 * time.Second
+)
+
+// ETA is an estimate of the time left, with the range the speed has
+// varied in
+type ETA struct {
+	Expected time.Duration
+	Min, Max time.Duration // Times left at the smoothed speed plus and minus its deviation
+	Known    bool          // False until the speed and size are known
+}
+
+// ETAEstimator smooths the download speed with an exponentially weighted
+// moving average, so the time left doesn't jump with each sample. Brief
+// stalls are ignored; longer ones slow the estimate down gradually.
+type ETAEstimator struct {
+	mean     float64 // Smoothed speed in bytes per second
+	variance float64 // Smoothed variance of the speed
+	last     time.Time
+	stalled  time.Time // When the speed dropped to zero, zero while moving
+	samples  int
+}
+
+// NewETAEstimator creates an estimator without samples
+func NewETAEstimator() *ETAEstimator {
+	return &ETAEstimator{}
+}
+
+// Update adds the speed of a status taken at a point in time
+func (e *ETAEstimator) Update(at time.Time, status *DownloadStatus) {
+	speed := float64(status.DownloadSpeed)
+	if speed == 0 {
+		if e.stalled.IsZero() {
+			e.stalled = at
+		}
+		// Hold the estimate through brief stalls
+		if at.Sub(e.stalled) < etaStallGrace || e.samples == 0 {
+			return
+		}
+	} else {
+		e.stalled = time.Time{}
+	}
+
+	if e.samples == 0 {
+		e.mean, e.variance = speed, 0
+		e.last = at
+		e.samples++
+		return
+	}
+
+	// Weigh the sample by the time since the last one, so irregular
+	// polling doesn't change how fast the estimate follows the speed
+	elapsed := at.Sub(e.last)
+	if elapsed <= 0 {
+		elapsed = time.Millisecond
+	}
+	alpha := 1 - math.Exp(-math.Ln2*float64(elapsed)/float64(etaHalfLife))
+	diff := speed - e.mean
+	e.mean += alpha * diff
+	e.variance = (1 - alpha) * (e.variance + alpha*diff*diff)
+	e.last = at
+	e.samples++
+}
+
+// Speed returns the smoothed speed in bytes per second
+func (e *ETAEstimator) Speed() int64 {
+	return int64(e.mean)
+}
+
+// Estimate returns the time left for the rest of a download at the smoothed speed
+func (e *ETAEstimator) Estimate(status *DownloadStatus) ETA {
+	if status.TotalLength == 0 {
+		return ETA{}
+	}
+	remaining := float64(status.TotalLength - status.CompletedLength)
+	if remaining <= 0 {
+		return ETA{Known: true}
+	}
+	// Less than a byte a second is no estimate
+	if e.samples == 0 || e.mean < 1 {
+		return ETA{}
+	}
+
+	deviation := math.Sqrt(e.variance)
+	eta := ETA{
+		Expected: timeLeft(remaining, e.mean),
+		Min:      timeLeft(remaining, e.mean+deviation),
+		Known:    true,
+	}
+	// The slowest speed is at least a tenth of the smoothed one, so the range stays finite
+	eta.Max = timeLeft(remaining, math.Max(e.mean-deviation, e.mean/10))
+	return eta
+}
+
+// timeLeft returns how long remaining bytes take at a speed, rounded to the second
+func timeLeft(remaining, speed float64) time.Duration {
+	return time.Duration(math.Ceil(remaining/speed)) * time.Second
+}
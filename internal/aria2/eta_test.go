@@ -0,0 +1,113 @@
+package aria2
+
+import (
+	"testing"
+	"time"
+)
+
+const mb = 1000 * 1000
+
+// feed adds a sample a second from a speed trace, starting at start, and
+// returns the time of the last one
+func feed(e *ETAEstimator, start time.Time, speeds []int64) time.Time {
+	at := start
+	for i, speed := range speeds {
+		at = start.Add(time.Duration(i) * time.Second)
+		e.Update(at, &DownloadStatus{DownloadSpeed: speed})
+	}
+	return at
+}
+
+// steady returns n seconds of a speed
+func steady(speed int64, n int) []int64 {
+	speeds := make([]int64, n)
+	for i := range speeds {
+		speeds[i] = speed
+	}
+	return speeds
+}
+
+func TestETAEstimator_NoisySpeed(t *testing.T) {
+	// 1 MB/s, alternating 20% above and below
+	var speeds []int64
+	for i := 0; i < 120; i++ {
+		if i%2 == 0 {
+			speeds = append(speeds, 1200*1000)
+		} else {
+			speeds = append(speeds, 800*1000)
+		}
+	}
+	e := NewETAEstimator()
+	feed(e, time.Unix(0, 0), speeds)
+
+	eta := e.Estimate(&DownloadStatus{TotalLength: 700 * mb, CompletedLength: 100 * mb})
+	if !eta.Known {
+		t.Fatal("Estimate() is unknown after two minutes of samples")
+	}
+	if eta.Expected < 570*time.Second || eta.Expected > 630*time.Second {
+		t.Errorf("Estimate().Expected = %v, want about 10m", eta.Expected)
+	}
+	if eta.Min > eta.Expected || eta.Max < eta.Expected || eta.Min == eta.Max {
+		t.Errorf("Estimate() range = %v to %v, want it around %v", eta.Min, eta.Max, eta.Expected)
+	}
+	if eta.Max-eta.Min > 5*time.Minute {
+		t.Errorf("Estimate() range = %v to %v, too wide for a ±20%% speed", eta.Min, eta.Max)
+	}
+}
+
+func TestETAEstimator_BriefStall(t *testing.T) {
+	status := &DownloadStatus{TotalLength: 100 * mb, CompletedLength: 40 * mb}
+	e := NewETAEstimator()
+	last := feed(e, time.Unix(0, 0), steady(2*mb, 30))
+	before := e.Estimate(status)
+
+	// Three seconds without data
+	feed(e, last.Add(time.Second), steady(0, 3))
+	if after := e.Estimate(status); after != before {
+		t.Errorf("Estimate() after a 3s stall = %+v, want %+v as before it", after, before)
+	}
+}
+
+func TestETAEstimator_LongStall(t *testing.T) {
+	status := &DownloadStatus{TotalLength: 100 * mb, CompletedLength: 40 * mb}
+	e := NewETAEstimator()
+	last := feed(e, time.Unix(0, 0), steady(2*mb, 30))
+	before := e.Estimate(status)
+
+	feed(e, last.Add(time.Second), steady(0, 30))
+	after := e.Estimate(status)
+	if !after.Known || after.Expected < 4*before.Expected {
+		t.Errorf("Estimate() after a 30s stall = %v, want well above %v", after.Expected, before.Expected)
+	}
+}
+
+func TestETAEstimator_FollowsSpeedChange(t *testing.T) {
+	e := NewETAEstimator()
+	last := feed(e, time.Unix(0, 0), steady(1*mb, 60))
+	feed(e, last.Add(time.Second), steady(4*mb, 60))
+
+	if speed := e.Speed(); speed < 3900*1000 || speed > 4*mb {
+		t.Errorf("Speed() a minute after going from 1 to 4 MB/s = %d, want about 4 MB/s", speed)
+	}
+}
+
+func TestETAEstimator_Unknown(t *testing.T) {
+	e := NewETAEstimator()
+	if eta := e.Estimate(&DownloadStatus{TotalLength: 1000}); eta.Known {
+		t.Errorf("Estimate() without samples = %+v, want unknown", eta)
+	}
+
+	// A download that starts stalled has no speed yet
+	feed(e, time.Unix(0, 0), steady(0, 10))
+	if eta := e.Estimate(&DownloadStatus{TotalLength: 1000}); eta.Known {
+		t.Errorf("Estimate() after only stalls = %+v, want unknown", eta)
+	}
+
+	feed(e, time.Unix(10, 0), steady(100, 1))
+	if eta := e.Estimate(&DownloadStatus{}); eta.Known {
+		t.Errorf("Estimate() without a size = %+v, want unknown", eta)
+	}
+	if eta := e.Estimate(&DownloadStatus{TotalLength: 1000, CompletedLength: 1000}); !eta.Known || eta.Expected != 0 {
+		t.Errorf("Estimate() of a finished download = %+v, want 0", eta)
+	}
+}
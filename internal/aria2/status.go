@@ -1,12 +1,23 @@
 package aria2
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 
 	"github.com/siku2/arigo"
 )
 
+// errorCodeChecksumFailed is aria2's exit status for a failed checksum validation.
+// arigo.ChecksumValidationFailed is off by one, so it is not used here.
+const errorCodeChecksumFailed = 32
+
+// ErrDownloadFailed is wrapped by errors reporting that aria2 gave up on a download
+var ErrDownloadFailed = errors.New("download failed")
+
+// ErrDownloadRemoved is wrapped by errors reporting that a download was removed from aria2
+var ErrDownloadRemoved = errors.New("download removed")
+
 // DownloadStatus represents the status of a download
 type DownloadStatus struct {
 	GID             string
@@ -20,6 +31,7 @@ type DownloadStatus struct {
 	PieceLength     int64
 	Dir             string
 	Files           []arigo.File
+	ErrorCode       int
 	ErrorMessage    string
 }
 
@@ -28,12 +40,17 @@ func (c *Client) GetStatus(gid string) (*DownloadStatus, error) {
 	status, err := c.rpc.TellStatus(gid,
 		"gid", "status", "totalLength", "completedLength",
 		"downloadSpeed", "uploadSpeed", "connections", "numPieces",
-		"pieceLength", "dir", "files", "errorMessage")
+		"pieceLength", "dir", "files", "errorCode", "errorMessage")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
 
-	ds := &DownloadStatus{
+	return newDownloadStatus(status), nil
+}
+
+// newDownloadStatus converts an aria2 status
+func newDownloadStatus(status arigo.Status) *DownloadStatus {
+	return &DownloadStatus{
 		GID:             status.GID,
 		Status:          string(status.Status),
 		TotalLength:     int64(status.TotalLength),
@@ -45,10 +62,9 @@ func (c *Client) GetStatus(gid string) (*DownloadStatus, error) {
 		PieceLength:     int64(status.PieceLength),
 		Dir:             status.Dir,
 		Files:           status.Files,
+		ErrorCode:       int(status.ErrorCode),
 		ErrorMessage:    status.ErrorMessage,
 	}
-
-	return ds, nil
 }
 
 // GetProgress returns the download progress as a percentage (0-100)
@@ -69,6 +85,16 @@ func (ds *DownloadStatus) IsError() bool {
 	return ds.Status == "error"
 }
 
+// IsRemoved returns true if the download was removed from aria2
+func (ds *DownloadStatus) IsRemoved() bool {
+	return ds.Status == "removed"
+}
+
+// IsChecksumError returns true if aria2 gave up because checksum validation failed
+func (ds *DownloadStatus) IsChecksumError() bool {
+	return ds.IsError() && ds.ErrorCode == errorCodeChecksumFailed
+}
+
 // IsActive returns true if the download is active
 func (ds *DownloadStatus) IsActive() bool {
 	return ds.Status == "active"
@@ -102,6 +128,14 @@ func (ds *DownloadStatus) GetFilePath() string {
 	return filePath
 }
 
+// GetURI returns the URI the download was added with
+func (ds *DownloadStatus) GetURI() string {
+	if len(ds.Files) == 0 || len(ds.Files[0].URIs) == 0 {
+		return ""
+	}
+	return ds.Files[0].URIs[0].URI
+}
+
 // GetFileDirectory returns the directory containing the downloaded file
 func (ds *DownloadStatus) GetFileDirectory() string {
 	if ds.Dir != "" {
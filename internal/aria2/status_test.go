@@ -163,3 +163,44 @@ func TestDownloadStatus_IsActive(t *testing.T) {
 		})
 	}
 }
+
+func TestDownloadStatus_IsChecksumError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		errorCode int
+		expected  bool
+	}{
+		{
+			name:      "checksum validation failed",
+			status:    "error",
+			errorCode: 32,
+			expected:  true,
+		},
+		{
+			name:      "other error",
+			status:    "error",
+			errorCode: 3,
+			expected:  false,
+		},
+		{
+			name:      "complete status",
+			status:    "complete",
+			errorCode: 0,
+			expected:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &DownloadStatus{
+				Status:    tt.status,
+				ErrorCode: tt.errorCode,
+			}
+			result := ds.IsChecksumError()
+			if result != tt.expected {
+				t.Errorf("DownloadStatus.IsChecksumError() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
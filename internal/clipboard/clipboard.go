@@ -0,0 +1,91 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// ErrNoHelper is returned when no clipboard helper is installed
+var ErrNoHelper = errors.New("no clipboard helper found: install wl-clipboard (Wayland), xclip or xsel (X11)")
+
+// readTimeout bounds a single clipboard read
+const readTimeout = 5 * time.Second
+
+// Reader returns the current text on the clipboard
+type Reader interface {
+	Read() (string, error)
+}
+
+// commandReader reads the clipboard by running a helper program
+type commandReader struct {
+	name string
+	args []string
+}
+
+// Read runs the helper and returns its output. Helpers that fail without
+// printing anything are treated as an empty clipboard, since wl-paste and
+// xclip exit with an error when nothing has been copied.
+func (r commandReader) Read() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), readTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.name, r.args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stdout.Len() == 0 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read clipboard with %s: %w", r.name, err)
+	}
+	return stdout.String(), nil
+}
+
+// helpers lists the clipboard helpers to try, in order of preference
+func helpers(goos string, getenv func(string) string) []commandReader {
+	switch goos {
+	case "darwin":
+		return []commandReader{{name: "pbpaste"}}
+	case "windows":
+		return []commandReader{{name: "powershell", args: []string{"-NoProfile", "-NonInteractive", "-Command", "Get-Clipboard"}}}
+	}
+
+	var list []commandReader
+	if getenv("WAYLAND_DISPLAY") != "" {
+		list = append(list, commandReader{name: "wl-paste", args: []string{"--no-newline", "--type", "text"}})
+	}
+	if getenv("DISPLAY") != "" {
+		list = append(list,
+			commandReader{name: "xclip", args: []string{"-selection", "clipboard", "-out"}},
+			commandReader{name: "xsel", args: []string{"--clipboard", "--output"}},
+		)
+	}
+	return list
+}
+
+// NewReader returns a reader for the system clipboard. A non-empty command
+// is run through the shell instead of a detected helper, and must print
+// the clipboard contents.
+func NewReader(command string) (Reader, error) {
+	if command != "" {
+		if runtime.GOOS == "windows" {
+			return commandReader{name: "cmd", args: []string{"/C", command}}, nil
+		}
+		return commandReader{name: "sh", args: []string{"-c", command}}, nil
+	}
+
+	for _, helper := range helpers(runtime.GOOS, os.Getenv) {
+		if _, err := exec.LookPath(helper.name); err == nil {
+			return helper, nil
+		}
+	}
+	return nil, ErrNoHelper
+}
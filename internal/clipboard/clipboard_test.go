@@ -0,0 +1,140 @@
+package clipboard
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMatcher_Links(t *testing.T) {
+	m, skipped := NewMatcher([]string{
+		`/https?:\/\/(www\.)?1fichier\.com\/\?[a-z0-9]+/`,
+		`/https?:\/\/mega\.nz\/file\/.+/i`,
+		`/(?<=lookbehind)unsupported/`,
+	})
+	if skipped != 1 {
+		t.Errorf("NewMatcher() skipped %d patterns, want 1", skipped)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "hoster link", text: "https://1fichier.com/?abc123", want: []string{"https://1fichier.com/?abc123"}},
+		{name: "case-insensitive pattern", text: "HTTPS://MEGA.NZ/file/xyz", want: []string{"HTTPS://MEGA.NZ/file/xyz"}},
+		{name: "magnet", text: "magnet:?xt=urn:btih:abc", want: []string{"magnet:?xt=urn:btih:abc"}},
+		{name: "torrent URL with query", text: "https://example.com/ubuntu.torrent?key=1", want: []string{"https://example.com/ubuntu.torrent?key=1"}},
+		{
+			name: "links in prose",
+			text: "grab (https://1fichier.com/?abc123) and \"magnet:?xt=urn:btih:abc\", then https://1fichier.com/?abc123 again",
+			want: []string{"https://1fichier.com/?abc123", "magnet:?xt=urn:btih:abc"},
+		},
+		{name: "unsupported host", text: "https://example.com/file.zip", want: nil},
+		{name: "plain text", text: "meeting at 10", want: nil},
+		{name: "torrent without scheme", text: "ubuntu.torrent", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Links(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Links() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelpers(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want []string
+	}{
+		{name: "wayland with xwayland", goos: "linux", env: map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, want: []string{"wl-paste", "xclip", "xsel"}},
+		{name: "x11", goos: "linux", env: map[string]string{"DISPLAY": ":0"}, want: []string{"xclip", "xsel"}},
+		{name: "no display", goos: "linux", want: nil},
+		{name: "macos", goos: "darwin", want: []string{"pbpaste"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, h := range helpers(tt.goos, env(tt.env)) {
+				got = append(got, h.name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("helpers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewReader_Command(t *testing.T) {
+	reader, err := NewReader("printf 'magnet:?xt=urn:btih:abc'")
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	text, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if text != "magnet:?xt=urn:btih:abc" {
+		t.Errorf("Read() = %q, want the magnet link", text)
+	}
+
+	// Helpers exit with an error on an empty clipboard
+	reader, _ = NewReader("exit 1")
+	if text, err := reader.Read(); err != nil || text != "" {
+		t.Errorf("Read() = %q, %v, want an empty clipboard", text, err)
+	}
+}
+
+// stubReader returns queued clipboard contents, repeating the last one
+type stubReader struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (s *stubReader) Read() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	text := s.texts[0]
+	if len(s.texts) > 1 {
+		s.texts = s.texts[1:]
+	}
+	return text, nil
+}
+
+func TestMonitor_Run(t *testing.T) {
+	reader := &stubReader{texts: []string{
+		"magnet:?xt=urn:btih:old", // Already copied before starting
+		"magnet:?xt=urn:btih:old",
+		"hello",
+		"magnet:?xt=urn:btih:one",
+		"https://example.com/a.torrent magnet:?xt=urn:btih:one",
+		"magnet:?xt=urn:btih:one", // Copied again
+	}}
+	matcher, _ := NewMatcher(nil)
+	monitor := NewMonitor(reader, matcher, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var offers [][]string
+	monitor.Run(ctx, func(links []string) { offers = append(offers, links) }, nil)
+
+	want := [][]string{
+		{"magnet:?xt=urn:btih:one"},
+		{"https://example.com/a.torrent"},
+	}
+	if !reflect.DeepEqual(offers, want) {
+		t.Errorf("Run() offered %v, want %v", offers, want)
+	}
+}
@@ -0,0 +1,157 @@
+package clipboard
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultInterval is how often the clipboard is read
+const DefaultInterval = time.Second
+
+// Matcher picks the downloadable links out of copied text
+type Matcher struct {
+	hosts []*regexp.Regexp
+}
+
+// NewMatcher creates a matcher for the given host patterns. Patterns may be
+// JavaScript literals such as /https?:\/\/host\.com\/.+/, as returned by
+// Real-Debrid. Patterns Go cannot compile are skipped and counted.
+func NewMatcher(patterns []string) (*Matcher, int) {
+	m := &Matcher{}
+	skipped := 0
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(stripDelimiters(pattern))
+		if err != nil {
+			skipped++
+			continue
+		}
+		m.hosts = append(m.hosts, re)
+	}
+	return m, skipped
+}
+
+// stripDelimiters turns a JavaScript regex literal into a plain pattern
+func stripDelimiters(pattern string) string {
+	if len(pattern) < 2 || pattern[0] != '/' {
+		return pattern
+	}
+	end := strings.LastIndex(pattern, "/")
+	if end == 0 {
+		return pattern
+	}
+	flags := pattern[end+1:]
+	pattern = pattern[1:end]
+	if strings.Contains(flags, "i") {
+		pattern = "(?i)" + pattern
+	}
+	return pattern
+}
+
+// Links returns the magnet links, .torrent URLs and supported hoster links
+// in text, in order and without repeats
+func (m *Matcher) Links(text string) []string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Fields(text) {
+		link := strings.Trim(field, `"'<>()[],`)
+		if seen[link] || !m.Match(link) {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+// Match reports whether a single link can be downloaded
+func (m *Matcher) Match(link string) bool {
+	if strings.HasPrefix(strings.ToLower(link), "magnet:?") {
+		return true
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(path.Ext(u.Path), ".torrent") {
+		return true
+	}
+	for _, re := range m.hosts {
+		if re.MatchString(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// Monitor polls the clipboard and reports links copied while it runs
+type Monitor struct {
+	reader   Reader
+	matcher  *Matcher
+	interval time.Duration
+}
+
+// NewMonitor creates a monitor reading the clipboard every interval
+func NewMonitor(reader Reader, matcher *Matcher, interval time.Duration) *Monitor {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Monitor{reader: reader, matcher: matcher, interval: interval}
+}
+
+// Run calls offer with the new links each time the clipboard changes, until
+// ctx is cancelled. Whatever is on the clipboard when it starts is ignored,
+// and a link is offered only once. Read errors are passed to onError, once
+// until a different error occurs, and polling continues.
+func (m *Monitor) Run(ctx context.Context, offer func(links []string), onError func(error)) {
+	lastErr := ""
+	report := func(err error) {
+		if err.Error() != lastErr && onError != nil {
+			onError(err)
+		}
+		lastErr = err.Error()
+	}
+
+	last, err := m.reader.Read()
+	if err != nil {
+		report(err)
+	}
+	offered := make(map[string]bool)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		text, err := m.reader.Read()
+		if err != nil {
+			report(err)
+			continue
+		}
+		lastErr = ""
+		if text == last {
+			continue
+		}
+		last = text
+
+		var links []string
+		for _, link := range m.matcher.Links(text) {
+			if !offered[link] {
+				offered[link] = true
+				links = append(links, link)
+			}
+		}
+		if len(links) > 0 {
+			offer(links)
+		}
+	}
+}
@@ -1,49 +1,197 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/viper"
 )
 
 // Config holds the application configuration
 type Config struct {
-	RealDebridAPIToken string
+	Profile            string    // Profile applied on top of the other settings, empty for none
+	Provider           string    // Debrid service links and torrents are sent to, such as realdebrid
+	APIToken           string    // API token of Provider, empty when not required
+	Failover           []Account // Accounts used in order when the token's traffic or premium runs out
+	Routes             []Route   // Other providers the links of some hosts are sent to
 	Aria2RPCUrl        string
 	Aria2Secret        string
 	DefaultDownloadDir string
+	DiskReserve        uint64 // Bytes to keep free on the download filesystem
+	Connections        int    // Connections per download
+	Extract            ExtractConfig
+	Hooks              HooksConfig
+	Notifications      NotificationsConfig
+	TUI                TUIConfig
+	Organize           OrganizeConfig
+	Duplicates         DuplicatesConfig
+	Watch              WatchConfig
+	Clip               ClipConfig
+	Play               PlayConfig
+	Schedule           ScheduleConfig
+	Warnings           []string // Problems worth telling the user about that don't stop venaqui
+}
+
+// Account is the debrid account of a profile
+type Account struct {
+	Profile  string
+	Provider string
+	APIToken string
+}
+
+// Route sends the links of hosts to a provider other than the one in use
+type Route struct {
+	Provider string
+	APIToken string
+	Hosts    []string // Domains, matching their subdomains too
+}
+
+// ExtractConfig holds settings for extracting downloaded archives
+type ExtractConfig struct {
+	Enabled        bool
+	Dir            string // Destination; relative paths are resolved against the archive directory
+	Passwords      []string
+	DeleteArchives bool
+}
+
+// OrganizeConfig holds the templates that choose where downloads are saved
+type OrganizeConfig struct {
+	Template   string       `mapstructure:"template"`    // Path relative to the download directory
+	OnConflict string       `mapstructure:"on_conflict"` // skip, rename or overwrite
+	Rules      []RuleConfig `mapstructure:"rules"`
+}
+
+// RuleConfig picks a template for downloads matching every condition set
+type RuleConfig struct {
+	Name     string   `mapstructure:"name"`
+	Ext      []string `mapstructure:"ext"`
+	Mime     []string `mapstructure:"mime"` // Patterns such as video/*
+	Host     []string `mapstructure:"host"`
+	Regex    string   `mapstructure:"regex"` // Matched against the file name
+	MinSize  string   `mapstructure:"min_size"`
+	MaxSize  string   `mapstructure:"max_size"`
+	Template string   `mapstructure:"template"`
+}
+
+// DuplicatesConfig holds settings for downloads that were seen before
+type DuplicatesConfig struct {
+	Action string // ask, skip, resume or force
+}
+
+// WatchConfig holds settings for the watch command
+type WatchConfig struct {
+	Dir string // Folder watched when none is given on the command line
+}
+
+// ClipConfig holds settings for the clipboard monitor
+type ClipConfig struct {
+	AutoAccept bool          // Queue copied links without asking
+	Command    string        // Prints the clipboard; replaces the detected helper
+	Hosts      []string      // Extra link patterns on top of Real-Debrid's hosts
+	Interval   time.Duration // How often the clipboard is read
+}
+
+// PlayConfig holds settings for the play command
+type PlayConfig struct {
+	Player   string   // Player command; empty to find mpv or VLC
+	Args     []string // Extra arguments before the stream URL
+	Quality  string   // Stream quality; empty to ask
+	Download bool     // Download with aria2 while playing
+}
+
+// ScheduleConfig holds the windows downloads are allowed to run in
+type ScheduleConfig struct {
+	Windows []WindowConfig `mapstructure:"windows"`
+}
+
+// WindowConfig is a daily download window. It ends the next day when end is
+// not after start.
+type WindowConfig struct {
+	Days  []string `mapstructure:"days"`  // mon, tue, ...; empty for every day
+	Start string   `mapstructure:"start"` // Time of day such as 01:00
+	End   string   `mapstructure:"end"`
+}
+
+// TUIConfig holds settings for the download screen
+type TUIConfig struct {
+	Theme      string // Theme name; auto follows the terminal's background
+	Fullscreen bool   // Use the terminal's alternate screen
+}
+
+// NotificationsConfig holds settings for desktop notifications
+type NotificationsConfig struct {
+	Enabled          bool
+	Bell             bool // Ring the terminal bell when desktop notifications fail
+	OnComplete       bool
+	OnError          bool
+	OnRetryExhausted bool
+}
+
+// HooksConfig holds the hooks run on download events
+type HooksConfig struct {
+	OnStart    []HookConfig `mapstructure:"on_start"`
+	OnComplete []HookConfig `mapstructure:"on_complete"`
+	OnError    []HookConfig `mapstructure:"on_error"`
+}
+
+// HookConfig is a single command or webhook. Command, URL and Body are
+// Go templates; see the hooks package for the available variables.
+type HookConfig struct {
+	Command string            `mapstructure:"command"`
+	URL     string            `mapstructure:"url"`
+	Method  string            `mapstructure:"method"`
+	Headers map[string]string `mapstructure:"headers"`
+	Body    string            `mapstructure:"body"`
+	Timeout time.Duration     `mapstructure:"timeout"`
 }
 
 // Load reads configuration from file and environment variables
 func Load() (*Config, error) {
-	homeDir, err := os.UserHomeDir()
+	configFile, err := GetConfigFile()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	configPath := filepath.Join(homeDir, ".venaqui")
-	configFile := filepath.Join(configPath, "config.yaml")
-
 	// Set up Viper
-	viper.SetConfigName("config")
+	viper.SetConfigFile(configFile)
 	viper.SetConfigType("yaml")
-	viper.AddConfigPath(configPath)
+
+	// VENAQUI_REALDEBRID_API_TOKEN overrides realdebrid.api_token
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
 
 	// Set defaults
-	viper.SetDefault("aria2.rpc_url", "http://localhost:6800/jsonrpc")
-	viper.SetDefault("aria2.secret", "")
-	viper.SetDefault("download.default_dir", "")
+	for _, key := range Keys {
+		if key.Default != nil {
+			viper.SetDefault(key.Name, key.Default)
+		}
+	}
 
-	// Read config file (ignore error if file doesn't exist)
+	// Read config file (a missing file is okay unless it was given explicitly)
 	if err := viper.ReadInConfig(); err != nil {
-		// Config file not found is okay, we'll use defaults
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+		if !errors.Is(err, os.ErrNotExist) || explicitConfigFile() {
 			return nil, fmt.Errorf("failed to read config file: %w", err)
 		}
 	}
 
+	// A profile takes precedence over the settings outside profiles, but
+	// not over environment variables
+	profileName := selectedProfile(viper.GetViper())
+	if profileName != "" {
+		settings, err := profileSettings(viper.GetViper(), profileName)
+		if err != nil {
+			return nil, err
+		}
+		if err := viper.MergeConfigMap(settings); err != nil {
+			return nil, fmt.Errorf("failed to apply profile %s: %w", profileName, err)
+		}
+	}
+
 	// Get default download directory
 	defaultDir, err := GetDefaultDownloadDir()
 	if err != nil {
@@ -55,17 +203,130 @@ func Load() (*Config, error) {
 		defaultDir = viper.GetString("download.default_dir")
 	}
 
-	// Get Real-Debrid API token (required)
-	apiToken := viper.GetString("realdebrid.api_token")
-	if apiToken == "" {
-		return nil, fmt.Errorf("realdebrid.api_token is required in %s", configFile)
+	// Secrets may come from a command or secret backend instead of the file
+	backend := viper.GetString("secrets.backend")
+	store, err := NewSecretStore(backend, viper.GetString("secrets.file"), nil)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+
+	// Get the API token of the provider in use (required)
+	providerName, err := selectedProvider(viper.GetViper(), "")
+	if err != nil {
+		return nil, err
+	}
+	tokenKey := providerName + ".api_token"
+	apiToken, warning, err := resolveSecret(viper.GetViper(), tokenKey, profileName, store, backend)
+	if err != nil {
+		return nil, err
+	}
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+	if apiToken == "" && !tokenOptional {
+		return nil, fmt.Errorf("%s is required in %s or %s", tokenKey, configFile, EnvVar(tokenKey))
+	}
+
+	diskReserve, err := humanize.ParseBytes(viper.GetString("download.reserve"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid download.reserve: %w", err)
+	}
+
+	// aria2 allows at most 16 connections per server
+	connections := viper.GetInt("download.connections")
+	if connections < 1 || connections > 16 {
+		return nil, fmt.Errorf("download.connections must be between 1 and 16, got %d", connections)
+	}
+
+	failover, failoverWarnings, err := failoverAccounts(viper.GetViper(), providerName, apiToken, store, backend)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, failoverWarnings...)
+
+	routes, routeWarnings, err := providerRoutes(viper.GetViper(), providerName, profileName, store, backend)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, routeWarnings...)
+
+	aria2Secret, warning, err := resolveSecret(viper.GetViper(), "aria2.secret", profileName, store, backend)
+	if err != nil {
+		return nil, err
+	}
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+
+	var hooks HooksConfig
+	if err := viper.UnmarshalKey("hooks", &hooks); err != nil {
+		return nil, fmt.Errorf("invalid hooks configuration: %w", err)
+	}
+
+	var organize OrganizeConfig
+	if err := viper.UnmarshalKey("organize", &organize); err != nil {
+		return nil, fmt.Errorf("invalid organize configuration: %w", err)
+	}
+	// UnmarshalKey doesn't see environment variables
+	organize.Template = viper.GetString("organize.template")
+	organize.OnConflict = viper.GetString("organize.on_conflict")
+
+	var schedule ScheduleConfig
+	if err := viper.UnmarshalKey("schedule", &schedule); err != nil {
+		return nil, fmt.Errorf("invalid schedule configuration: %w", err)
 	}
 
 	cfg := &Config{
-		RealDebridAPIToken: apiToken,
+		Profile:            profileName,
+		Provider:           providerName,
+		APIToken:           apiToken,
+		Failover:           failover,
+		Routes:             routes,
 		Aria2RPCUrl:        viper.GetString("aria2.rpc_url"),
-		Aria2Secret:        viper.GetString("aria2.secret"),
+		Aria2Secret:        aria2Secret,
 		DefaultDownloadDir: defaultDir,
+		DiskReserve:        diskReserve,
+		Connections:        connections,
+		Extract: ExtractConfig{
+			Enabled:        viper.GetBool("extract.enabled"),
+			Dir:            viper.GetString("extract.dir"),
+			Passwords:      viper.GetStringSlice("extract.passwords"),
+			DeleteArchives: viper.GetBool("extract.delete_archives"),
+		},
+		Hooks:    hooks,
+		Organize: organize,
+		Schedule: schedule,
+		Duplicates: DuplicatesConfig{
+			Action: viper.GetString("duplicates.action"),
+		},
+		Watch: WatchConfig{
+			Dir: viper.GetString("watch.dir"),
+		},
+		Clip: ClipConfig{
+			AutoAccept: viper.GetBool("clip.auto_accept"),
+			Command:    viper.GetString("clip.command"),
+			Hosts:      viper.GetStringSlice("clip.hosts"),
+			Interval:   viper.GetDuration("clip.interval"),
+		},
+		Play: PlayConfig{
+			Player:   viper.GetString("play.player"),
+			Args:     viper.GetStringSlice("play.args"),
+			Quality:  viper.GetString("play.quality"),
+			Download: viper.GetBool("play.download"),
+		},
+		TUI: TUIConfig{
+			Theme:      viper.GetString("tui.theme"),
+			Fullscreen: viper.GetBool("tui.fullscreen"),
+		},
+		Notifications: NotificationsConfig{
+			Enabled:          viper.GetBool("notifications.enabled"),
+			Bell:             viper.GetBool("notifications.bell"),
+			OnComplete:       viper.GetBool("notifications.on_complete"),
+			OnError:          viper.GetBool("notifications.on_error"),
+			OnRetryExhausted: viper.GetBool("notifications.on_retry_exhausted"),
+		},
+		Warnings: warnings,
 	}
 
 	return cfg, nil
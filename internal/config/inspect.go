@@ -0,0 +1,261 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Source says where the effective value of a setting comes from
+type Source string
+
+const (
+	SourceUnset   Source = "unset" // Neither set nor defaulted
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceProfile Source = "profile" // The active profile in the file
+	SourceFlag    Source = "flag"    // A command line flag such as --provider
+)
+
+// Setting is the effective value of a setting
+type Setting struct {
+	Key    Key
+	Value  interface{}
+	Source Source
+}
+
+// Inspect returns every known setting with its effective value and source,
+// applying the active profile. Unlike Load it does not validate the
+// configuration.
+func Inspect(path string) ([]Setting, error) {
+	file, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	active := selectedProfile(file)
+
+	settings := make([]Setting, 0, len(Keys))
+	for _, key := range Keys {
+		setting := Setting{Key: key, Source: SourceUnset}
+		flag, inFlag := lookupFlag(key)
+		env, inEnv := lookupEnv(key)
+		switch {
+		case inFlag:
+			setting.Value, setting.Source = flag, SourceFlag
+		case inEnv:
+			setting.Value, setting.Source = env, SourceEnv
+		case active != "" && !key.Global && file.IsSet(ProfileKey(active, key.Name)):
+			setting.Value, setting.Source = file.Get(ProfileKey(active, key.Name)), SourceProfile
+		case file.IsSet(key.Name):
+			setting.Value, setting.Source = file.Get(key.Name), SourceFile
+		case key.Default != nil:
+			setting.Value, setting.Source = key.Default, SourceDefault
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// lookupFlag returns the command line flag overriding a setting, as given
+// with SetProfile or SetProvider
+func lookupFlag(key Key) (string, bool) {
+	switch key.Name {
+	case "profile":
+		return profile, profile != ""
+	case "provider":
+		return provider, provider != ""
+	}
+	return "", false
+}
+
+// lookupEnv returns the environment variable overriding a setting.
+// Structured settings can't be set from the environment.
+func lookupEnv(key Key) (string, bool) {
+	if key.Kind == KindStructured {
+		return "", false
+	}
+	return os.LookupEnv(EnvVar(key.Name))
+}
+
+// Validate checks the configuration file at path and the environment
+// variables overriding it for unknown keys, values of the wrong type and
+// missing required settings. It returns every problem found.
+func Validate(path string) []error {
+	file, err := readFile(path)
+	if err != nil {
+		return []error{err}
+	}
+
+	var problems []error
+	checked := map[string]bool{}
+	for _, name := range file.AllKeys() {
+		// Profiles hold settings of their own
+		profile := ""
+		if rest, ok := strings.CutPrefix(name, "profiles."); ok {
+			var found bool
+			if profile, name, found = strings.Cut(rest, "."); !found {
+				problems = append(problems, fmt.Errorf("profile %s must be a section of settings", profile))
+				continue
+			}
+		}
+
+		key, ok := findKey(name)
+		if !ok {
+			problems = append(problems, unknownKey(ProfileKey(profile, name)))
+			continue
+		}
+		if profile != "" && key.Global {
+			problems = append(problems, fmt.Errorf("%s can't be set in profile %s", key.Name, profile))
+			continue
+		}
+		full := ProfileKey(profile, key.Name)
+		if checked[full] {
+			continue
+		}
+		checked[full] = true
+		if err := key.Check(file.Get(full)); err != nil {
+			if profile != "" {
+				err = fmt.Errorf("profile %s: %w", profile, err)
+			}
+			problems = append(problems, err)
+			continue
+		}
+		if target, ok := structuredTargets[key.Name]; ok {
+			if err := file.UnmarshalKey(full, target()); err != nil {
+				problems = append(problems, fmt.Errorf("invalid %s: %w", full, err))
+			}
+		}
+	}
+
+	active := selectedProfile(file)
+	if active != "" && !file.IsSet("profiles."+strings.ToLower(active)) {
+		problems = append(problems, unknownProfile(file, active))
+	}
+	for _, name := range file.GetStringSlice("realdebrid.failover") {
+		if !file.IsSet("profiles." + strings.ToLower(name)) {
+			problems = append(problems, fmt.Errorf("invalid realdebrid.failover: %w", unknownProfile(file, name)))
+		}
+	}
+
+	for _, key := range Keys {
+		env, ok := lookupEnv(key)
+		// Lists are separated by spaces in the environment
+		if !ok || key.Kind == KindList {
+			continue
+		}
+		if err := key.Check(env); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", EnvVar(key.Name), err))
+		}
+	}
+
+	// An invalid provider was reported above
+	if providerName, err := selectedProvider(file, active); err == nil && !hasToken(file, active, providerName) {
+		tokenKey := providerName + ".api_token"
+		problems = append(problems, fmt.Errorf("%s is required in the file or %s", tokenKey, EnvVar(tokenKey)))
+	}
+	return problems
+}
+
+// hasToken reports whether the API token of a provider is set for a
+// profile, or outside profiles when profile is empty. A token kept outside
+// the file is checked when it is loaded.
+func hasToken(file *viper.Viper, profile, provider string) bool {
+	if token := os.Getenv(EnvVar(provider + ".api_token")); token != "" {
+		return true
+	}
+	if backend := file.GetString("secrets.backend"); backend == BackendKeyring || backend == BackendFile {
+		return true
+	}
+	for _, p := range []string{profile, ""} {
+		if file.GetString(ProfileKey(p, provider+".token_command")) != "" || file.GetString(ProfileKey(p, provider+".api_token")) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// structuredTargets returns the types structured settings are read into
+var structuredTargets = map[string]func() interface{}{
+	"organize.rules":    func() interface{} { return &[]RuleConfig{} },
+	"schedule.windows":  func() interface{} { return &[]WindowConfig{} },
+	"hooks.on_start":    func() interface{} { return &[]HookConfig{} },
+	"hooks.on_complete": func() interface{} { return &[]HookConfig{} },
+	"hooks.on_error":    func() interface{} { return &[]HookConfig{} },
+}
+
+// findKey returns the setting a key in the file belongs to. Keys below a
+// structured setting belong to it.
+func findKey(name string) (Key, bool) {
+	for _, key := range Keys {
+		if name == key.Name || (key.Kind == KindStructured && strings.HasPrefix(name, key.Name+".")) {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// unknownKey reports a key venaqui doesn't read, suggesting a known key
+// with the same last part
+func unknownKey(name string) error {
+	last := name[strings.LastIndex(name, ".")+1:]
+	for _, key := range Keys {
+		if strings.HasSuffix(key.Name, "."+last) {
+			return fmt.Errorf("unknown key %s, did you mean %s?", name, key.Name)
+		}
+	}
+	return fmt.Errorf("unknown key %s", name)
+}
+
+// Unset removes a setting from the configuration file at path. It reports
+// whether the setting was in the file.
+func Unset(path, name string) (bool, error) {
+	file, err := readFile(path)
+	if err != nil {
+		return false, err
+	}
+	if !file.InConfig(name) {
+		return false, nil
+	}
+
+	settings := file.AllSettings()
+	deleteKey(settings, strings.Split(strings.ToLower(name), "."))
+
+	v := viper.New()
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+	return true, write(v, path)
+}
+
+// deleteKey removes a dotted key from nested settings, dropping sections
+// left empty
+func deleteKey(settings map[string]interface{}, parts []string) {
+	if len(parts) == 1 {
+		delete(settings, parts[0])
+		return
+	}
+	section, ok := settings[parts[0]].(map[string]interface{})
+	if !ok {
+		return
+	}
+	deleteKey(section, parts[1:])
+	if len(section) == 0 {
+		delete(settings, parts[0])
+	}
+}
+
+// readFile reads the configuration file at path without defaults. A
+// missing file reads as empty.
+func readFile(path string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a configuration file to a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInspect(t *testing.T) {
+	path := writeConfig(t, "realdebrid:\n  api_token: abc\ndownload:\n  connections: 8\n")
+
+	settings, err := Inspect(path)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+
+	want := map[string]Source{
+		"realdebrid.api_token": SourceFile,
+		"download.connections": SourceFile,
+		"aria2.rpc_url":        SourceDefault,
+		"organize.rules":       SourceUnset,
+	}
+	for _, setting := range settings {
+		if source, ok := want[setting.Key.Name]; ok && setting.Source != source {
+			t.Errorf("Inspect() source of %s = %v, want %v", setting.Key.Name, setting.Source, source)
+		}
+		if setting.Key.Name == "download.connections" && setting.Value != 8 {
+			t.Errorf("Inspect() download.connections = %v, want 8", setting.Value)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	path := writeConfig(t, `realdebrid:
+  api_token: abc
+aria2:
+  rpcurl: http://nas:6800/jsonrpc
+download:
+  connections: 32
+  reserve: lots
+notifications:
+  bell: "false"
+duplicates:
+  action: maybe
+hooks:
+  on_complete:
+    - command: echo done
+      timeout: soon
+`)
+
+	var got []string
+	for _, err := range Validate(path) {
+		got = append(got, err.Error())
+	}
+	for _, want := range []string{
+		"unknown key aria2.rpcurl",
+		"download.connections must be between 1 and 16",
+		"download.reserve must be a size",
+		"duplicates.action must be one of ask, skip, resume, force",
+		"invalid hooks.on_complete",
+	} {
+		found := false
+		for _, problem := range got {
+			found = found || strings.Contains(problem, want)
+		}
+		if !found {
+			t.Errorf("Validate() = %q, want a problem containing %q", got, want)
+		}
+	}
+	if len(got) != 5 {
+		t.Errorf("Validate() returned %d problems, want 5: %q", len(got), got)
+	}
+
+	valid := writeConfig(t, "realdebrid:\n  api_token: abc\nclip:\n  hosts: [\"example\\\\.com\"]\n")
+	if problems := Validate(valid); len(problems) != 0 {
+		t.Errorf("Validate() of a valid file = %v, want no problems", problems)
+	}
+}
+
+func TestUnset(t *testing.T) {
+	path := writeConfig(t, "realdebrid:\n  api_token: abc\nwatch:\n  dir: /tmp/in\n")
+
+	removed, err := Unset(path, "watch.dir")
+	if err != nil || !removed {
+		t.Fatalf("Unset() = %v, %v, want true, nil", removed, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := string(data); strings.Contains(content, "watch") || !strings.Contains(content, "api_token: abc") {
+		t.Errorf("Unset() wrote %q, want watch removed and the token kept", content)
+	}
+
+	if removed, _ := Unset(path, "watch.dir"); removed {
+		t.Error("Unset() of a missing key = true, want false")
+	}
+}
+
+func TestKey_Parse(t *testing.T) {
+	tests := []struct {
+		key     string
+		in      string
+		want    interface{}
+		wantErr bool
+	}{
+		{key: "extract.enabled", in: "true", want: true},
+		{key: "extract.enabled", in: "sometimes", wantErr: true},
+		{key: "download.connections", in: "4", want: 4},
+		{key: "download.connections", in: "0", wantErr: true},
+		{key: "clip.interval", in: "2s", want: "2s"},
+		{key: "duplicates.action", in: "skip", want: "skip"},
+		{key: "organize.rules", in: "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		key, _ := LookupKey(tt.key)
+		got, err := key.Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%s, %q) error = %v, wantErr %v", tt.key, tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("Parse(%s, %q) = %v, want %v", tt.key, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInspect_Env(t *testing.T) {
+	path := writeConfig(t, "realdebrid:\n  api_token: abc\n")
+	t.Setenv("VENAQUI_REALDEBRID_API_TOKEN", "from-env")
+	t.Setenv("VENAQUI_DOWNLOAD_CONNECTIONS", "many")
+
+	settings, err := Inspect(path)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	for _, setting := range settings {
+		if setting.Key.Name == "realdebrid.api_token" && (setting.Source != SourceEnv || setting.Value != "from-env") {
+			t.Errorf("Inspect() api token = %v from %v, want from-env from env", setting.Value, setting.Source)
+		}
+	}
+
+	problems := Validate(path)
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "VENAQUI_DOWNLOAD_CONNECTIONS") {
+		t.Errorf("Validate() = %v, want a problem with VENAQUI_DOWNLOAD_CONNECTIONS", problems)
+	}
+}
+
+func TestInspect_Flags(t *testing.T) {
+	path := writeConfig(t, "provider: realdebrid\nrealdebrid:\n  api_token: abc\n")
+	t.Setenv("VENAQUI_PROVIDER", "premiumize")
+	SetProvider("alldebrid")
+	SetProfile("work")
+	t.Cleanup(func() {
+		SetProvider("")
+		SetProfile("")
+	})
+
+	settings, err := Inspect(path)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	want := map[string]string{"provider": "alldebrid", "profile": "work"}
+	for _, setting := range settings {
+		if value, ok := want[setting.Key.Name]; ok && (setting.Source != SourceFlag || setting.Value != value) {
+			t.Errorf("Inspect() %s = %v from %v, want %s from flag", setting.Key.Name, setting.Value, setting.Source, value)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Kind is the type of value a setting holds
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindBool
+	KindInt
+	KindDuration
+	KindSize       // Byte size such as 1GB
+	KindChoice     // One of Key.Choices
+	KindList       // List of strings
+	KindStructured // List or map only editable in the file
+)
+
+// String returns the name of the kind shown in help and errors
+func (k Kind) String() string {
+	switch k {
+	case KindBool:
+		return "bool"
+	case KindInt:
+		return "int"
+	case KindDuration:
+		return "duration"
+	case KindSize:
+		return "size"
+	case KindChoice:
+		return "choice"
+	case KindList:
+		return "list"
+	case KindStructured:
+		return "structured"
+	}
+	return "string"
+}
+
+// Key describes a configuration setting
+type Key struct {
+	Name        string
+	Kind        Kind
+	Default     interface{} // Nil when the setting has no default
+	Choices     []string    // Allowed values of a KindChoice setting
+	Min, Max    int         // Allowed range of a KindInt setting
+	Secret      bool        // Redacted when shown
+	Global      bool        // Can't be changed by a profile
+	Description string
+}
+
+// Keys lists every setting venaqui reads from the configuration file
+var Keys = []Key{
+	{Name: "provider", Kind: KindChoice, Default: "realdebrid", Choices: []string{"realdebrid", "alldebrid", "premiumize"}, Description: "Debrid service links and torrents are sent to"},
+	{Name: "realdebrid.api_token", Kind: KindString, Secret: true, Description: "Real-Debrid API token (required with the realdebrid provider)"},
+	{Name: "realdebrid.token_command", Kind: KindString, Default: "", Description: "Command printing the API token, such as pass show real-debrid"},
+	{Name: "realdebrid.failover", Kind: KindList, Default: []string{}, Description: "Profiles whose accounts take over when traffic or premium runs out"},
+	{Name: "realdebrid.hosts", Kind: KindList, Default: []string{}, Description: "Hosts whose links go to Real-Debrid whatever the provider"},
+	{Name: "alldebrid.api_token", Kind: KindString, Secret: true, Description: "AllDebrid API key"},
+	{Name: "alldebrid.token_command", Kind: KindString, Default: "", Description: "Command printing the AllDebrid API key"},
+	{Name: "alldebrid.hosts", Kind: KindList, Default: []string{}, Description: "Hosts whose links go to AllDebrid whatever the provider"},
+	{Name: "premiumize.api_token", Kind: KindString, Secret: true, Description: "Premiumize API key"},
+	{Name: "premiumize.token_command", Kind: KindString, Default: "", Description: "Command printing the Premiumize API key"},
+	{Name: "premiumize.hosts", Kind: KindList, Default: []string{}, Description: "Hosts whose links go to Premiumize whatever the provider"},
+	{Name: "aria2.rpc_url", Kind: KindString, Default: "http://localhost:6800/jsonrpc", Description: "aria2 RPC endpoint"},
+	{Name: "aria2.secret", Kind: KindString, Default: "", Secret: true, Description: "aria2 RPC secret"},
+	{Name: "aria2.secret_command", Kind: KindString, Default: "", Description: "Command printing the aria2 RPC secret"},
+	{Name: "secrets.backend", Kind: KindChoice, Choices: []string{"plaintext", "keyring", "file"}, Global: true, Description: "Where set-secret stores secrets; plaintext keeps them in this file"},
+	{Name: "secrets.file", Kind: KindString, Default: "", Global: true, Description: "Encrypted secrets file; empty for secrets.enc in the config directory"},
+	{Name: "download.default_dir", Kind: KindString, Default: "", Description: "Download directory; empty for the OS Downloads folder"},
+	{Name: "download.reserve", Kind: KindSize, Default: "1GB", Description: "Free space to keep on the download filesystem"},
+	{Name: "download.connections", Kind: KindInt, Default: 16, Min: 1, Max: 16, Description: "Connections aria2 opens per download"},
+	{Name: "extract.enabled", Kind: KindBool, Default: false, Description: "Extract archives after download"},
+	{Name: "extract.dir", Kind: KindString, Default: "", Description: "Where archives are extracted; empty for next to the archive"},
+	{Name: "extract.passwords", Kind: KindList, Default: []string{}, Secret: true, Description: "Passwords tried on encrypted archives"},
+	{Name: "extract.delete_archives", Kind: KindBool, Default: false, Description: "Delete archive parts after extraction"},
+	{Name: "organize.template", Kind: KindString, Default: "{filename}", Description: "Path template for downloads, relative to the download directory"},
+	{Name: "organize.on_conflict", Kind: KindChoice, Default: "rename", Choices: []string{"skip", "rename", "overwrite"}, Description: "What to do when the target file exists"},
+	{Name: "organize.rules", Kind: KindStructured, Description: "Rules choosing a template per download"},
+	{Name: "duplicates.action", Kind: KindChoice, Default: "ask", Choices: []string{"ask", "skip", "resume", "force"}, Description: "What to do with downloads seen before"},
+	{Name: "watch.dir", Kind: KindString, Default: "", Description: "Folder watched by venaqui watch"},
+	{Name: "schedule.windows", Kind: KindStructured, Description: "Daily windows downloads may run in"},
+	{Name: "clip.auto_accept", Kind: KindBool, Default: false, Description: "Queue copied links without asking"},
+	{Name: "clip.command", Kind: KindString, Default: "", Description: "Command printing the clipboard"},
+	{Name: "clip.hosts", Kind: KindList, Default: []string{}, Description: "Extra link patterns offered by venaqui clip"},
+	{Name: "clip.interval", Kind: KindDuration, Default: "1s", Description: "How often the clipboard is read"},
+	{Name: "play.player", Kind: KindString, Default: "", Description: "Player run by venaqui play; empty for mpv or VLC"},
+	{Name: "play.args", Kind: KindList, Default: []string{}, Description: "Extra arguments passed to the player"},
+	{Name: "play.quality", Kind: KindString, Default: "", Description: "Stream quality such as 720p, or original; empty to ask"},
+	{Name: "play.download", Kind: KindBool, Default: false, Description: "Download the file with aria2 while it plays"},
+	{Name: "tui.theme", Kind: KindString, Default: "auto", Description: "TUI theme: auto, dark, light, high-contrast, no-color or a file in themes/"},
+	{Name: "tui.fullscreen", Kind: KindBool, Default: false, Description: "Run the TUI in the terminal's alternate screen"},
+	{Name: "notifications.enabled", Kind: KindBool, Default: true, Description: "Desktop notifications from the TUI"},
+	{Name: "notifications.bell", Kind: KindBool, Default: true, Description: "Ring the bell when notifications can't be shown"},
+	{Name: "notifications.on_complete", Kind: KindBool, Default: true, Description: "Notify when a download completes"},
+	{Name: "notifications.on_error", Kind: KindBool, Default: true, Description: "Notify when a download fails"},
+	{Name: "notifications.on_retry_exhausted", Kind: KindBool, Default: true, Description: "Notify when re-downloads are exhausted"},
+	{Name: "hooks.on_start", Kind: KindStructured, Description: "Hooks run when a download starts"},
+	{Name: "hooks.on_complete", Kind: KindStructured, Description: "Hooks run when a download completes"},
+	{Name: "hooks.on_error", Kind: KindStructured, Description: "Hooks run when a download fails"},
+	{Name: "profile", Kind: KindString, Default: "", Global: true, Description: "Profile used when --profile is not given"},
+	{Name: "profiles", Kind: KindStructured, Global: true, Description: "Named profiles overriding the settings above"},
+}
+
+// EnvPrefix is the prefix of environment variables overriding settings
+const EnvPrefix = "VENAQUI"
+
+// EnvVar returns the environment variable overriding a setting, such as
+// VENAQUI_REALDEBRID_API_TOKEN for realdebrid.api_token
+func EnvVar(name string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(name, ".", "_"))
+}
+
+// LookupKey returns the setting with the given name
+func LookupKey(name string) (Key, bool) {
+	name = strings.ToLower(name)
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// Parse converts a value given on the command line to the type stored in
+// the configuration file
+func (k Key) Parse(s string) (interface{}, error) {
+	switch k.Kind {
+	case KindBool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", k.Name)
+		}
+		return b, nil
+	case KindInt:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", k.Name)
+		}
+		if err := k.checkRange(n); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case KindDuration:
+		if _, err := time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("%s must be a duration such as 30s or 5m", k.Name)
+		}
+		return s, nil
+	case KindSize:
+		if _, err := humanize.ParseBytes(s); err != nil {
+			return nil, fmt.Errorf("%s must be a size such as 500MB or 2GB", k.Name)
+		}
+		return s, nil
+	case KindChoice:
+		if err := k.checkChoice(s); err != nil {
+			return nil, err
+		}
+		return strings.ToLower(s), nil
+	case KindList:
+		items := []string{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	case KindStructured:
+		return nil, fmt.Errorf("%s can only be changed by editing the configuration file", k.Name)
+	}
+	return s, nil
+}
+
+// Check reports whether a value read from the configuration file has the
+// type of the setting. Scalars are checked the way they would be parsed on
+// the command line, since viper converts them when they are read.
+func (k Key) Check(value interface{}) error {
+	switch value.(type) {
+	case []interface{}:
+		if k.Kind == KindStructured {
+			return nil
+		}
+		if k.Kind != KindList {
+			return fmt.Errorf("%s must be a %s, got a list", k.Name, k.Kind)
+		}
+		for _, item := range value.([]interface{}) {
+			if _, ok := item.(string); !ok {
+				return fmt.Errorf("%s must be a list of strings, got %v", k.Name, item)
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		if k.Kind == KindStructured {
+			return fmt.Errorf("%s must be a list, got a map", k.Name)
+		}
+		return fmt.Errorf("%s must be a %s, got a map", k.Name, k.Kind)
+	}
+
+	switch k.Kind {
+	case KindList, KindStructured:
+		return fmt.Errorf("%s must be a list, got %v", k.Name, value)
+	case KindString:
+		return nil
+	}
+	_, err := k.Parse(fmt.Sprint(value))
+	return err
+}
+
+// checkRange reports whether n is in the allowed range
+func (k Key) checkRange(n int) error {
+	if n < k.Min || n > k.Max {
+		return fmt.Errorf("%s must be between %d and %d, got %d", k.Name, k.Min, k.Max, n)
+	}
+	return nil
+}
+
+// checkChoice reports whether s is one of the allowed values, ignoring case
+func (k Key) checkChoice(s string) error {
+	for _, choice := range k.Choices {
+		if strings.EqualFold(s, choice) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be one of %s, got %q", k.Name, strings.Join(k.Choices, ", "), s)
+}
@@ -1,10 +1,22 @@
 package config
 
 import (
+	"bufio"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 )
 
+// configFile is the configuration file given with --config, if any
+var configFile string
+
+// SetConfigFile makes Load and GetConfigFile use the configuration file at
+// path instead of looking for one
+func SetConfigFile(path string) {
+	configFile = path
+}
+
 // GetDefaultDownloadDir returns the default download directory for the current OS
 func GetDefaultDownloadDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -12,8 +24,105 @@ func GetDefaultDownloadDir() (string, error) {
 		return "", err
 	}
 
-	// Cross-platform downloads folder
+	return downloadDir(runtime.GOOS, homeDir, os.Getenv), nil
+}
+
+// downloadDir returns the download directory. Linux follows the XDG user
+// directories; elsewhere it is the Downloads folder in the home directory.
+func downloadDir(goos, homeDir string, getenv func(string) string) string {
+	if goos == "linux" {
+		dir := getenv("XDG_DOWNLOAD_DIR")
+		if dir == "" {
+			dir = readUserDir(filepath.Join(xdgConfigHome(homeDir, getenv), "user-dirs.dirs"), "XDG_DOWNLOAD_DIR")
+		}
+		dir = strings.Replace(dir, "$HOME", homeDir, 1)
+		// A user directory set to the home directory is disabled
+		if filepath.IsAbs(dir) && filepath.Clean(dir) != filepath.Clean(homeDir) {
+			return dir
+		}
+	}
+
 	// Windows: C:\Users\<user>\Downloads
 	// macOS/Linux: /Users/<user>/Downloads or /home/<user>/Downloads
-	return filepath.Join(homeDir, "Downloads"), nil
+	return filepath.Join(homeDir, "Downloads")
+}
+
+// readUserDir reads a directory such as XDG_DOWNLOAD_DIR="$HOME/Downloads"
+// from a user-dirs.dirs file
+func readUserDir(path, name string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if ok && key == name {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// xdgConfigHome returns $XDG_CONFIG_HOME, or ~/.config when it is unset
+func xdgConfigHome(homeDir string, getenv func(string) string) string {
+	if dir := getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(homeDir, ".config")
+}
+
+// GetConfigDir returns the directory holding venaqui's configuration and state files
+func GetConfigDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return configDir(runtime.GOOS, homeDir, os.Getenv), nil
+}
+
+// configDir returns the configuration directory. Linux uses
+// $XDG_CONFIG_HOME/venaqui unless only ~/.venaqui exists, which is kept
+// for configurations written by older versions; elsewhere it is ~/.venaqui.
+func configDir(goos, homeDir string, getenv func(string) string) string {
+	legacy := filepath.Join(homeDir, ".venaqui")
+	if goos != "linux" {
+		return legacy
+	}
+
+	xdg := filepath.Join(xdgConfigHome(homeDir, getenv), "venaqui")
+	if _, err := os.Stat(xdg); err != nil {
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return xdg
+}
+
+// GetConfigFile returns the path of the configuration file: the one given
+// with --config or $VENAQUI_CONFIG, or config.yaml in the configuration
+// directory
+func GetConfigFile() (string, error) {
+	if configFile != "" {
+		return configFile, nil
+	}
+	if path := os.Getenv("VENAQUI_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	configPath, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configPath, "config.yaml"), nil
+}
+
+// explicitConfigFile reports whether the configuration file was chosen by
+// the user, in which case it must exist
+func explicitConfigFile() bool {
+	return configFile != "" || os.Getenv("VENAQUI_CONFIG") != ""
 }
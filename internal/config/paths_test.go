@@ -20,21 +20,93 @@ func TestGetDefaultDownloadDir(t *testing.T) {
 	if !filepath.IsAbs(dir) {
 		t.Errorf("GetDefaultDownloadDir() = %v, expected absolute path", dir)
 	}
+}
 
-	// Should end with Downloads
-	base := filepath.Base(dir)
-	if base != "Downloads" {
-		t.Errorf("GetDefaultDownloadDir() = %v, expected path ending with Downloads", dir)
+func TestDownloadDir(t *testing.T) {
+	home := t.TempDir()
+	configHome := filepath.Join(home, "xdg")
+	if err := os.MkdirAll(configHome, 0755); err != nil {
+		t.Fatal(err)
+	}
+	userDirs := "# Written by xdg-user-dirs-update\nXDG_DESKTOP_DIR=\"$HOME/Desktop\"\nXDG_DOWNLOAD_DIR=\"$HOME/Téléchargements\"\n"
+	if err := os.WriteFile(filepath.Join(configHome, "user-dirs.dirs"), []byte(userDirs), 0644); err != nil {
+		t.Fatal(err)
 	}
 
-	// Should be in user's home directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		t.Fatalf("Failed to get home directory: %v", err)
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want string
+	}{
+		{name: "user-dirs.dirs", goos: "linux", env: map[string]string{"XDG_CONFIG_HOME": configHome}, want: filepath.Join(home, "Téléchargements")},
+		{name: "environment", goos: "linux", env: map[string]string{"XDG_CONFIG_HOME": configHome, "XDG_DOWNLOAD_DIR": "/data/downloads"}, want: "/data/downloads"},
+		{name: "disabled", goos: "linux", env: map[string]string{"XDG_DOWNLOAD_DIR": "$HOME"}, want: filepath.Join(home, "Downloads")},
+		{name: "no user dirs", goos: "linux", want: filepath.Join(home, "Downloads")},
+		{name: "macOS ignores XDG", goos: "darwin", env: map[string]string{"XDG_DOWNLOAD_DIR": "/data/downloads"}, want: filepath.Join(home, "Downloads")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := downloadDir(tt.goos, home, getenv); got != tt.want {
+				t.Errorf("downloadDir() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	home := t.TempDir()
+	noEnv := func(string) string { return "" }
+	xdg := filepath.Join(home, ".config", "venaqui")
+	legacy := filepath.Join(home, ".venaqui")
+
+	if got := configDir("linux", home, noEnv); got != xdg {
+		t.Errorf("configDir() on a fresh system = %v, want %v", got, xdg)
+	}
+	if got := configDir("darwin", home, noEnv); got != legacy {
+		t.Errorf("configDir() on macOS = %v, want %v", got, legacy)
+	}
+
+	// Configurations written by older versions stay where they are
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := configDir("linux", home, noEnv); got != legacy {
+		t.Errorf("configDir() with ~/.venaqui = %v, want %v", got, legacy)
+	}
+	if err := os.MkdirAll(xdg, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := configDir("linux", home, noEnv); got != xdg {
+		t.Errorf("configDir() with both = %v, want %v", got, xdg)
+	}
+
+	if err := os.RemoveAll(legacy); err != nil {
+		t.Fatal(err)
+	}
+	custom := filepath.Join(home, "custom")
+	getenv := func(key string) string {
+		if key == "XDG_CONFIG_HOME" {
+			return custom
+		}
+		return ""
+	}
+	if got, want := configDir("linux", home, getenv), filepath.Join(custom, "venaqui"); got != want {
+		t.Errorf("configDir() with XDG_CONFIG_HOME = %v, want %v", got, want)
+	}
+}
+
+func TestGetConfigFile(t *testing.T) {
+	t.Setenv("VENAQUI_CONFIG", "/etc/venaqui.yaml")
+	if got, _ := GetConfigFile(); got != "/etc/venaqui.yaml" {
+		t.Errorf("GetConfigFile() with VENAQUI_CONFIG = %v, want /etc/venaqui.yaml", got)
 	}
 
-	expectedDir := filepath.Join(homeDir, "Downloads")
-	if dir != expectedDir {
-		t.Errorf("GetDefaultDownloadDir() = %v, want %v", dir, expectedDir)
+	SetConfigFile("/srv/venaqui.yaml")
+	defer SetConfigFile("")
+	if got, _ := GetConfigFile(); got != "/srv/venaqui.yaml" {
+		t.Errorf("GetConfigFile() with --config = %v, want /srv/venaqui.yaml", got)
 	}
 }
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mhrsntrk/venaqui/internal/secret"
+	"github.com/spf13/viper"
+)
+
+// profile is the profile given with --profile, if any
+var profile string
+
+// SetProfile makes Load and Inspect apply the named profile on top of the
+// settings outside profiles
+func SetProfile(name string) {
+	profile = name
+}
+
+// ProfileKey returns the key of a setting within a profile, such as
+// profiles.work.aria2.rpc_url, or the setting itself when profile is empty
+func ProfileKey(profile, name string) string {
+	if profile == "" {
+		return name
+	}
+	return "profiles." + strings.ToLower(profile) + "." + name
+}
+
+// selectedProfile returns the profile given with --profile, VENAQUI_PROFILE
+// or the profile setting of the file, in that order. Empty means none.
+func selectedProfile(file *viper.Viper) string {
+	if profile != "" {
+		return profile
+	}
+	if name, ok := os.LookupEnv(EnvVar("profile")); ok {
+		return name
+	}
+	return file.GetString("profile")
+}
+
+// ActiveProfile returns the profile Load applies with the configuration
+// file at path, or an empty string for none
+func ActiveProfile(path string) (string, error) {
+	file, err := readFile(path)
+	if err != nil {
+		return "", err
+	}
+	return selectedProfile(file), nil
+}
+
+// Profiles returns the names of the profiles in the configuration file at
+// path, sorted
+func Profiles(path string) ([]string, error) {
+	file, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return profileNames(file), nil
+}
+
+// profileNames returns the sorted names of the profiles defined in v
+func profileNames(v *viper.Viper) []string {
+	var names []string
+	for name := range v.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileSettings returns the settings of a profile to apply on top of the
+// others. Global settings are left out, as are the secrets and their
+// commands, which are resolved separately so that a profile's token never
+// falls back to a command outside it.
+func profileSettings(v *viper.Viper, name string) (map[string]interface{}, error) {
+	key := "profiles." + strings.ToLower(name)
+	if !v.IsSet(key) {
+		return nil, unknownProfile(v, name)
+	}
+
+	settings := copySettings(v.GetStringMap(key))
+	for secret, command := range secretCommands {
+		deleteKey(settings, strings.Split(secret, "."))
+		deleteKey(settings, strings.Split(command, "."))
+	}
+	for _, key := range Keys {
+		if key.Global {
+			deleteKey(settings, strings.Split(key.Name, "."))
+		}
+	}
+	return settings, nil
+}
+
+// unknownProfile reports a profile that isn't in the configuration file
+func unknownProfile(v *viper.Viper, name string) error {
+	names := profileNames(v)
+	if len(names) == 0 {
+		return fmt.Errorf("profile %q is not defined: the configuration file has no profiles", name)
+	}
+	return fmt.Errorf("profile %q is not defined, choose one of %s", name, strings.Join(names, ", "))
+}
+
+// failoverAccounts resolves the accounts of the profiles in
+// realdebrid.failover, skipping the account in use and repeated accounts.
+// A profile without a provider setting uses the selected provider.
+func failoverAccounts(v *viper.Viper, selected, apiToken string, store secret.Store, backend string) ([]Account, []string, error) {
+	seen := map[string]bool{selected + "/" + apiToken: true}
+	var accounts []Account
+	var warnings []string
+	for _, name := range v.GetStringSlice("realdebrid.failover") {
+		if !v.IsSet("profiles." + strings.ToLower(name)) {
+			return nil, nil, fmt.Errorf("invalid realdebrid.failover: %w", unknownProfile(v, name))
+		}
+		provider := strings.ToLower(v.GetString(ProfileKey(name, "provider")))
+		if provider == "" {
+			provider = selected
+		}
+		if err := providerKey.checkChoice(provider); err != nil {
+			return nil, nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		token, warning, err := lookupSecret(v, provider+".api_token", name, store, backend)
+		if err != nil {
+			return nil, nil, err
+		}
+		if token == "" {
+			return nil, nil, fmt.Errorf("profile %s in realdebrid.failover has no %s.api_token", name, provider)
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		if seen[provider+"/"+token] {
+			continue
+		}
+		seen[provider+"/"+token] = true
+		accounts = append(accounts, Account{Profile: name, Provider: provider, APIToken: token})
+	}
+	return accounts, warnings, nil
+}
+
+// copySettings deep copies nested settings so they can be changed
+func copySettings(settings map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		if section, ok := value.(map[string]interface{}); ok {
+			value = copySettings(section)
+		}
+		copied[key] = value
+	}
+	return copied
+}
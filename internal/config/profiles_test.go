@@ -0,0 +1,153 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const profilesConfig = `realdebrid:
+  api_token: home-token
+  failover: [backup, work]
+secrets:
+  backend: plaintext
+download:
+  default_dir: /data/downloads
+  connections: 8
+profiles:
+  work:
+    realdebrid:
+      api_token: work-token
+    aria2:
+      rpc_url: http://nas:6800/jsonrpc
+    download:
+      default_dir: /mnt/nas
+  backup:
+    realdebrid:
+      api_token: backup-token
+`
+
+// loadProfile loads the configuration file at path with a profile applied
+func loadProfile(t *testing.T, path, name string) (*Config, error) {
+	t.Helper()
+	viper.Reset()
+	SetConfigFile(path)
+	SetProfile(name)
+	t.Cleanup(func() {
+		viper.Reset()
+		SetConfigFile("")
+		SetProfile("")
+	})
+	return Load()
+}
+
+func TestLoad_Profile(t *testing.T) {
+	path := writeConfig(t, profilesConfig)
+
+	cfg, err := loadProfile(t, path, "work")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Profile != "work" || cfg.APIToken != "work-token" {
+		t.Errorf("Load() profile = %q, token = %q, want work, work-token", cfg.Profile, cfg.APIToken)
+	}
+	if cfg.Aria2RPCUrl != "http://nas:6800/jsonrpc" || cfg.DefaultDownloadDir != "/mnt/nas" {
+		t.Errorf("Load() aria2 = %q, dir = %q, want the profile's", cfg.Aria2RPCUrl, cfg.DefaultDownloadDir)
+	}
+	if cfg.Connections != 8 {
+		t.Errorf("Load() connections = %d, want 8 from outside the profile", cfg.Connections)
+	}
+	// The account in use is not its own failover
+	if len(cfg.Failover) != 1 || cfg.Failover[0] != (Account{Profile: "backup", Provider: "realdebrid", APIToken: "backup-token"}) {
+		t.Errorf("Load() failover = %+v, want the backup account only", cfg.Failover)
+	}
+
+	// Environment variables take precedence over profiles
+	t.Setenv("VENAQUI_DOWNLOAD_DEFAULT_DIR", "/tmp/env")
+	if cfg, err := loadProfile(t, path, "work"); err != nil || cfg.DefaultDownloadDir != "/tmp/env" {
+		t.Errorf("Load() with env = %v, %v, want /tmp/env", cfg, err)
+	}
+}
+
+func TestLoad_ProfileFallback(t *testing.T) {
+	path := writeConfig(t, profilesConfig)
+
+	// A profile without a token uses the one outside profiles
+	cfg, err := loadProfile(t, path, "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Profile != "" || cfg.APIToken != "home-token" || cfg.DefaultDownloadDir != "/data/downloads" {
+		t.Errorf("Load() = %+v, want the settings outside profiles", cfg)
+	}
+	if len(cfg.Failover) != 2 {
+		t.Errorf("Load() failover = %+v, want backup and work", cfg.Failover)
+	}
+
+	if _, err := loadProfile(t, path, "holiday"); err == nil || !strings.Contains(err.Error(), "backup, work") {
+		t.Errorf("Load() of an unknown profile error = %v, want the defined profiles", err)
+	}
+
+	t.Setenv("VENAQUI_PROFILE", "backup")
+	if cfg, err := loadProfile(t, path, ""); err != nil || cfg.Profile != "backup" {
+		t.Errorf("Load() with VENAQUI_PROFILE = %v, %v, want the backup profile", cfg, err)
+	}
+}
+
+func TestValidate_Profiles(t *testing.T) {
+	path := writeConfig(t, `realdebrid:
+  api_token: abc
+  failover: [spare]
+profile: work
+profiles:
+  work:
+    download:
+      connections: 32
+    aria2:
+      rpcurl: http://nas:6800/jsonrpc
+    secrets:
+      backend: keyring
+`)
+
+	var got []string
+	for _, err := range Validate(path) {
+		got = append(got, err.Error())
+	}
+	for _, want := range []string{
+		"profile work: download.connections must be between 1 and 16",
+		"unknown key profiles.work.aria2.rpcurl",
+		"secrets.backend can't be set in profile work",
+		`profile "spare" is not defined`,
+	} {
+		found := false
+		for _, problem := range got {
+			found = found || strings.Contains(problem, want)
+		}
+		if !found {
+			t.Errorf("Validate() = %q, want a problem containing %q", got, want)
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("Validate() returned %d problems, want 4: %q", len(got), got)
+	}
+}
+
+func TestInspect_Profile(t *testing.T) {
+	path := writeConfig(t, profilesConfig+"profile: work\n")
+
+	settings, err := Inspect(path)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	want := map[string]Source{
+		"aria2.rpc_url":        SourceProfile,
+		"download.connections": SourceFile,
+		"secrets.backend":      SourceFile,
+	}
+	for _, setting := range settings {
+		if source, ok := want[setting.Key.Name]; ok && setting.Source != source {
+			t.Errorf("Inspect() source of %s = %v, want %v", setting.Key.Name, setting.Source, source)
+		}
+	}
+}
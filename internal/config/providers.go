@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mhrsntrk/venaqui/internal/secret"
+	"github.com/spf13/viper"
+)
+
+// provider is the provider given with --provider, if any
+var provider string
+
+// SetProvider makes Load send links to the named provider instead of the
+// one in the configuration
+func SetProvider(name string) {
+	provider = name
+}
+
+// tokenOptional is set when links are downloaded without a provider
+var tokenOptional bool
+
+// SetTokenOptional makes Load succeed without an API token, for downloads
+// that don't go through a debrid provider
+func SetTokenOptional(optional bool) {
+	tokenOptional = optional
+}
+
+// providerKey is the setting choosing the provider
+var providerKey, _ = LookupKey("provider")
+
+// selectedProvider returns the provider given with --provider or, when
+// none was given, the provider setting of profile, then outside profiles.
+// Environment variables and defaults apply when v has them.
+func selectedProvider(v *viper.Viper, profile string) (string, error) {
+	name := provider
+	if name == "" {
+		name, _ = os.LookupEnv(EnvVar("provider"))
+	}
+	if name == "" && profile != "" {
+		name = v.GetString(ProfileKey(profile, "provider"))
+	}
+	if name == "" {
+		name = v.GetString("provider")
+	}
+	if name == "" {
+		name = providerKey.Default.(string)
+	}
+	if err := providerKey.checkChoice(name); err != nil {
+		return "", err
+	}
+	return strings.ToLower(name), nil
+}
+
+// DefaultProvider returns the provider Load uses without a configuration
+// file: the one given with --provider or VENAQUI_PROVIDER, or the default
+func DefaultProvider() (string, error) {
+	return selectedProvider(viper.New(), "")
+}
+
+// TokenInEnv reports whether the environment gives the API token of a
+// provider, or a command printing it, so no configuration file is needed
+func TokenInEnv(provider string) bool {
+	for _, key := range []string{provider + ".api_token", provider + ".token_command"} {
+		if os.Getenv(EnvVar(key)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// providerRoutes resolves the providers other than the one in use whose
+// hosts setting lists hosts, for the profile in use
+func providerRoutes(v *viper.Viper, selected, profile string, store secret.Store, backend string) ([]Route, []string, error) {
+	var routes []Route
+	var warnings []string
+	for _, name := range providerKey.Choices {
+		hosts := v.GetStringSlice(name + ".hosts")
+		if name == selected || len(hosts) == 0 {
+			continue
+		}
+		token, warning, err := resolveSecret(v, name+".api_token", profile, store, backend)
+		if err != nil {
+			return nil, nil, err
+		}
+		if token == "" {
+			return nil, nil, fmt.Errorf("%s.hosts is set but %s.api_token is not", name, name)
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		routes = append(routes, Route{Provider: name, APIToken: token, Hosts: hosts})
+	}
+	return routes, warnings, nil
+}
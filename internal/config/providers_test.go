@@ -0,0 +1,97 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const providersConfig = `realdebrid:
+  api_token: rd-token
+  failover: [seedbox]
+alldebrid:
+  api_token: ad-token
+  hosts: [rapidgator.net]
+secrets:
+  backend: plaintext
+profiles:
+  seedbox:
+    provider: premiumize
+    premiumize:
+      api_token: pm-token
+`
+
+func TestLoad_Provider(t *testing.T) {
+	path := writeConfig(t, providersConfig)
+
+	cfg, err := loadProfile(t, path, "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Provider != "realdebrid" || cfg.APIToken != "rd-token" {
+		t.Errorf("Load() provider = %q, token = %q, want realdebrid, rd-token", cfg.Provider, cfg.APIToken)
+	}
+	if len(cfg.Failover) != 1 || cfg.Failover[0] != (Account{Profile: "seedbox", Provider: "premiumize", APIToken: "pm-token"}) {
+		t.Errorf("Load() failover = %+v, want the seedbox Premiumize account", cfg.Failover)
+	}
+	if len(cfg.Routes) != 1 || cfg.Routes[0].Provider != "alldebrid" || cfg.Routes[0].Hosts[0] != "rapidgator.net" {
+		t.Errorf("Load() routes = %+v, want rapidgator.net to AllDebrid", cfg.Routes)
+	}
+
+	// A profile chooses its own provider
+	cfg, err = loadProfile(t, path, "seedbox")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Provider != "premiumize" || cfg.APIToken != "pm-token" {
+		t.Errorf("Load() provider = %q, token = %q, want premiumize, pm-token", cfg.Provider, cfg.APIToken)
+	}
+}
+
+func TestLoad_ProviderFlag(t *testing.T) {
+	path := writeConfig(t, providersConfig)
+	t.Cleanup(func() { SetProvider("") })
+
+	SetProvider("alldebrid")
+	cfg, err := loadProfile(t, path, "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// The provider in use doesn't route to itself
+	if cfg.Provider != "alldebrid" || cfg.APIToken != "ad-token" || len(cfg.Routes) != 0 {
+		t.Errorf("Load() = %q, %q, routes %+v, want alldebrid, ad-token and no routes", cfg.Provider, cfg.APIToken, cfg.Routes)
+	}
+
+	SetProvider("torbox")
+	if _, err := loadProfile(t, path, ""); err == nil || !strings.Contains(err.Error(), "provider must be one of") {
+		t.Errorf("Load() with an unknown provider error = %v, want provider must be one of", err)
+	}
+}
+
+func TestValidate_ProviderToken(t *testing.T) {
+	path := writeConfig(t, "provider: alldebrid\nrealdebrid:\n  api_token: rd-token\nsecrets:\n  backend: plaintext\n")
+
+	problems := Validate(path)
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "alldebrid.api_token is required") {
+		t.Errorf("Validate() = %v, want alldebrid.api_token is required", problems)
+	}
+}
+
+func TestTokenInEnv(t *testing.T) {
+	t.Setenv("VENAQUI_PROVIDER", "alldebrid")
+	name, err := DefaultProvider()
+	if err != nil || name != "alldebrid" {
+		t.Fatalf("DefaultProvider() = %q, %v, want alldebrid", name, err)
+	}
+	if TokenInEnv(name) {
+		t.Error("TokenInEnv() = true without a token in the environment")
+	}
+
+	t.Setenv("VENAQUI_REALDEBRID_API_TOKEN", "abc")
+	if TokenInEnv(name) {
+		t.Error("TokenInEnv() = true for the token of another provider")
+	}
+	t.Setenv("VENAQUI_ALLDEBRID_TOKEN_COMMAND", "pass show alldebrid")
+	if !TokenInEnv(name) {
+		t.Error("TokenInEnv() = false with a token command in the environment")
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// Save writes settings, keyed like "aria2.rpc_url", to the configuration
+// file at path. Settings already in the file are kept. The file may hold
+// the API token, so it is only readable by the user.
+func Save(path string, settings map[string]interface{}) error {
+	v, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+	return write(v, path)
+}
+
+// write writes the settings of v to the configuration file at path
+func write(v *viper.Viper, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	v.SetConfigPermissions(0600)
+	if err := v.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	// Tighten permissions of a file written by hand or by older versions
+	return os.Chmod(path, 0600)
+}
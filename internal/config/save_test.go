@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "venaqui", "config.yaml")
+
+	existing := "duplicates:\n  action: skip\nrealdebrid:\n  api_token: old\n"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Save(path, map[string]interface{}{
+		"realdebrid.api_token": "new",
+		"aria2.rpc_url":        "http://nas:6800/jsonrpc",
+	})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Save() permissions = %o, want 600", perm)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{"api_token: new", "rpc_url: http://nas:6800/jsonrpc", "action: skip"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Save() wrote %q, want it to contain %q", content, want)
+		}
+	}
+}
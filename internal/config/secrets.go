@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mhrsntrk/venaqui/internal/secret"
+	"github.com/spf13/viper"
+)
+
+// Secret backends chosen with secrets.backend
+const (
+	BackendPlaintext = "plaintext"
+	BackendKeyring   = "keyring"
+	BackendFile      = "file"
+)
+
+// PassphraseEnv holds the passphrase of the encrypted secrets file
+const PassphraseEnv = "VENAQUI_SECRETS_PASSPHRASE"
+
+// secretCommands maps the settings that can be kept out of the
+// configuration file to the settings naming a command that prints them
+var secretCommands = map[string]string{
+	"realdebrid.api_token": "realdebrid.token_command",
+	"alldebrid.api_token":  "alldebrid.token_command",
+	"premiumize.api_token": "premiumize.token_command",
+	"aria2.secret":         "aria2.secret_command",
+}
+
+// IsStoredSecret reports whether a setting can be kept in a secret backend
+func IsStoredSecret(name string) bool {
+	_, ok := secretCommands[name]
+	return ok
+}
+
+// passphrasePrompt asks for the secrets passphrase, nil when nobody can
+// be asked
+var passphrasePrompt func() (string, error)
+
+// SetPassphrasePrompt sets how the passphrase of the encrypted secrets file
+// is asked for when PassphraseEnv is not set
+func SetPassphrasePrompt(prompt func() (string, error)) {
+	passphrasePrompt = prompt
+}
+
+// passphrase returns the passphrase of the encrypted secrets file
+func passphrase() (string, error) {
+	if value := os.Getenv(PassphraseEnv); value != "" {
+		return value, nil
+	}
+	if passphrasePrompt == nil {
+		return "", fmt.Errorf("the secrets file is encrypted: set %s", PassphraseEnv)
+	}
+	return passphrasePrompt()
+}
+
+// NewSecretStore returns the store of a secret backend, or nil for
+// plaintext. The encrypted file defaults to secrets.enc in the
+// configuration directory, and its passphrase is asked for with
+// passphraseFunc, or as set with SetPassphrasePrompt if it is nil.
+func NewSecretStore(backend, file string, passphraseFunc func() (string, error)) (secret.Store, error) {
+	switch backend {
+	case "", BackendPlaintext:
+		return nil, nil
+	case BackendKeyring:
+		return secret.NewKeyring(nil), nil
+	case BackendFile:
+		file, err := SecretsFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if passphraseFunc == nil {
+			passphraseFunc = passphrase
+		}
+		return secret.NewFile(file, passphraseFunc), nil
+	}
+	return nil, fmt.Errorf("invalid secrets.backend %q: must be plaintext, keyring or file", backend)
+}
+
+// SecretsFile returns the path of the encrypted secrets file given by
+// secrets.file, defaulting to secrets.enc in the configuration directory
+func SecretsFile(file string) (string, error) {
+	if file != "" {
+		return file, nil
+	}
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(dir, "secrets.enc"), nil
+}
+
+// resolveSecret returns a secret from its environment variable or, in
+// order, the command, the secret store and the configuration file of the
+// profile and then of the settings outside profiles. It returns a warning
+// when the secret was read from the file in plaintext without choosing to.
+func resolveSecret(v *viper.Viper, name, profile string, store secret.Store, backend string) (string, string, error) {
+	if value, ok := os.LookupEnv(EnvVar(name)); ok {
+		return value, "", nil
+	}
+
+	if profile != "" {
+		value, warning, err := lookupSecret(v, name, profile, store, backend)
+		if value != "" || err != nil {
+			return value, warning, err
+		}
+	}
+	return lookupSecret(v, name, "", store, backend)
+}
+
+// lookupSecret returns a secret of a profile, or outside profiles when
+// profile is empty, from its command, the secret store or the configuration
+// file. The secret is empty when none of them has it.
+func lookupSecret(v *viper.Viper, name, profile string, store secret.Store, backend string) (string, string, error) {
+	if command := v.GetString(ProfileKey(profile, secretCommands[name])); command != "" {
+		value, err := secret.RunCommand(command)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to run %s: %w", ProfileKey(profile, secretCommands[name]), err)
+		}
+		return value, "", nil
+	}
+
+	key := ProfileKey(profile, name)
+	if store != nil {
+		value, err := store.Get(key)
+		if err == nil {
+			return value, "", nil
+		}
+		if !errors.Is(err, secret.ErrNotFound) {
+			return "", "", fmt.Errorf("failed to read %s from the %s backend: %w", key, backend, err)
+		}
+	}
+
+	value := v.GetString(key)
+	if value == "" || backend == BackendPlaintext {
+		return value, "", nil
+	}
+	command := "venaqui config set-secret " + name
+	if profile != "" {
+		command = "venaqui --profile " + profile + " config set-secret " + name
+	}
+	return value, fmt.Sprintf("%s is stored in plaintext in the configuration file; run '%s' to move it to a secret backend", key, command), nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveSecret(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets.enc")
+	store, err := NewSecretStore(BackendFile, file, func() (string, error) { return "passphrase", nil })
+	if err != nil {
+		t.Fatalf("NewSecretStore() error = %v", err)
+	}
+	if err := store.Set("aria2.secret", "from-store"); err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.New()
+	v.Set("realdebrid.api_token", "from-file")
+	v.Set("aria2.secret", "plain-secret")
+
+	// The store takes precedence over the file
+	if got, warning, err := resolveSecret(v, "aria2.secret", "", store, BackendFile); err != nil || got != "from-store" || warning != "" {
+		t.Errorf("resolveSecret() = %q, %q, %v, want from-store without a warning", got, warning, err)
+	}
+
+	// A plaintext secret is used with a warning unless plaintext was chosen
+	got, warning, err := resolveSecret(v, "realdebrid.api_token", "", store, BackendFile)
+	if err != nil || got != "from-file" || !strings.Contains(warning, "set-secret realdebrid.api_token") {
+		t.Errorf("resolveSecret() = %q, %q, %v, want from-file with a warning", got, warning, err)
+	}
+	if _, warning, _ := resolveSecret(v, "realdebrid.api_token", "", nil, BackendPlaintext); warning != "" {
+		t.Errorf("resolveSecret() with the plaintext backend warned %q", warning)
+	}
+
+	if _, err := exec.LookPath("sh"); err == nil {
+		v.Set("realdebrid.token_command", "echo from-command")
+		if got, _, err := resolveSecret(v, "realdebrid.api_token", "", store, BackendFile); err != nil || got != "from-command" {
+			t.Errorf("resolveSecret() = %q, %v, want from-command", got, err)
+		}
+	}
+
+	t.Setenv("VENAQUI_REALDEBRID_API_TOKEN", "from-env")
+	if got, _, err := resolveSecret(v, "realdebrid.api_token", "", store, BackendFile); err != nil || got != "from-env" {
+		t.Errorf("resolveSecret() = %q, %v, want from-env", got, err)
+	}
+}
+
+func TestNewSecretStore(t *testing.T) {
+	if store, err := NewSecretStore("", "", nil); store != nil || err != nil {
+		t.Errorf("NewSecretStore(\"\") = %v, %v, want no store", store, err)
+	}
+	if _, err := NewSecretStore("vault", "", nil); err == nil {
+		t.Error("NewSecretStore(\"vault\") error = nil, want an error")
+	}
+}
@@ -0,0 +1,144 @@
+// Package debrid defines the interfaces venaqui uses to turn hoster links
+// and torrents into direct download links, implemented for Real-Debrid,
+// AllDebrid and Premiumize, and the errors they share.
+package debrid
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/media"
+)
+
+// Link is a direct download link for a hoster link or a torrent file
+type Link struct {
+	ID       string // The provider's ID of the download, if any
+	Filename string
+	MimeType string
+	Filesize int64
+	Host     string // Hoster main domain
+	Download string // Direct download link
+}
+
+// Torrent is a torrent the provider has downloaded to its servers
+type Torrent struct {
+	ID       string
+	Filename string
+	Hash     string // Info hash, empty if the provider doesn't tell
+	Files    []TorrentFile
+	// Links to pass to Unrestrict, in the order of Files. A provider that
+	// bundles files may return fewer links than files.
+	Links  []string
+	Direct bool // Links are already direct download links
+}
+
+// TorrentFile is a file of a torrent
+type TorrentFile struct {
+	Path string
+	Size int64
+}
+
+// Account is the account an API token belongs to
+type Account struct {
+	Username   string
+	Premium    bool
+	Expiration time.Time // When premium ends, zero if unknown
+}
+
+// Stream is a URL a player can open while the file is still downloading
+type Stream struct {
+	Format  string // Such as apple (HLS) or liveMP4; original for the file itself
+	Quality string // Such as 720p; original for the file itself
+	URL     string
+}
+
+// Media describes a video and the streams it can be played from
+type Media struct {
+	Duration      time.Duration // Zero if unknown
+	Width, Height int
+	Streams       []Stream // Best first
+}
+
+// Unrestrictor turns hoster links into direct download links
+type Unrestrictor interface {
+	// Unrestrict returns the direct download link for a hoster link or a
+	// link of a torrent
+	Unrestrict(link string) (*Link, error)
+	// GetHostRegexes returns patterns matching the links the provider
+	// supports
+	GetHostRegexes() ([]string, error)
+}
+
+// TorrentProvider downloads torrents to the provider's servers
+type TorrentProvider interface {
+	// SubmitMagnet adds a magnet link and returns the torrent's ID
+	SubmitMagnet(magnet string) (string, error)
+	// SubmitTorrent adds the contents of a torrent file and returns the
+	// torrent's ID
+	SubmitTorrent(data []byte) (string, error)
+	// WaitForTorrent selects every file of a torrent and waits until the
+	// provider has downloaded it
+	WaitForTorrent(id string, maxWait time.Duration) (*Torrent, error)
+}
+
+// Provider is a debrid service
+type Provider interface {
+	Unrestrictor
+	TorrentProvider
+	// Name returns the service's name for messages, such as Real-Debrid
+	Name() string
+	// ValidateToken checks that the API token is valid
+	ValidateToken() error
+	// GetAccount returns the account the API token belongs to
+	GetAccount() (*Account, error)
+}
+
+// Streamer transcodes unrestricted links for streaming. Providers that
+// don't are played from the direct download link.
+type Streamer interface {
+	// Streams returns the transcoded streams of an unrestricted link, by
+	// the ID of the Link
+	Streams(id string) (*Media, error)
+}
+
+// MediaDescriber describes the video files of unrestricted links. Without
+// one, downloaded videos are described with ffprobe.
+type MediaDescriber interface {
+	// MediaInfo returns the tracks of an unrestricted link, by the ID of
+	// the Link
+	MediaInfo(id string) (*media.Info, error)
+}
+
+// Providers lists the names of the supported providers, as used in the
+// configuration
+var Providers = []string{"realdebrid", "alldebrid", "premiumize"}
+
+// TokenURLs lists where the API token of each provider is found, by name
+var TokenURLs = map[string]string{
+	"realdebrid": "https://real-debrid.com/apitoken",
+	"alldebrid":  "https://alldebrid.com/apikeys",
+	"premiumize": "https://www.premiumize.me/account",
+}
+
+// DownloadTorrent downloads a torrent file from a URL so it can be passed
+// to SubmitTorrent
+func DownloadTorrent(torrentURL string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(torrentURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download torrent file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to download torrent file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read torrent file: %w", err)
+	}
+	return data, nil
+}
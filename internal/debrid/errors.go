@@ -0,0 +1,77 @@
+package debrid
+
+import "errors"
+
+// Sentinel errors shared by the providers. Errors returned by a Provider
+// can be matched with errors.Is.
+var (
+	ErrBadToken               = errors.New("bad token")
+	ErrPermissionDenied       = errors.New("permission denied")
+	ErrTwoFactorRequired      = errors.New("two-factor authentication required")
+	ErrAccountLocked          = errors.New("account locked or not activated")
+	ErrRateLimited            = errors.New("too many requests")
+	ErrHosterUnsupported      = errors.New("hoster not supported")
+	ErrHosterUnavailable      = errors.New("hoster temporarily unavailable")
+	ErrHosterLimitReached     = errors.New("hoster limit reached")
+	ErrPremiumRequired        = errors.New("hoster not available for free users")
+	ErrTooManyActiveDownloads = errors.New("too many active downloads")
+	ErrIPNotAllowed           = errors.New("IP address not allowed")
+	ErrTrafficExhausted       = errors.New("traffic exhausted")
+	ErrFileUnavailable        = errors.New("file unavailable")
+	ErrFileNotAllowed         = errors.New("file not allowed")
+	ErrServiceUnavailable     = errors.New("service unavailable")
+	ErrTorrentInvalid         = errors.New("invalid torrent")
+	ErrTorrentTooBig          = errors.New("torrent too big")
+	ErrNotFound               = errors.New("resource not found")
+)
+
+// AccountExhausted reports whether an error means the account can't serve
+// more downloads until its traffic quota resets or its premium is renewed,
+// so that another account may take over
+func AccountExhausted(err error) bool {
+	return errors.Is(err, ErrTrafficExhausted) || errors.Is(err, ErrPremiumRequired)
+}
+
+// Hint returns a remediation hint for a provider error, or an empty string
+// if the error is not a known provider error
+func Hint(err error) string {
+	switch {
+	case errors.Is(err, ErrBadToken):
+		return "Check the API token with 'venaqui config list --show-secrets'; get a new one from https://real-debrid.com/apitoken, https://alldebrid.com/apikeys or https://www.premiumize.me/account"
+	case errors.Is(err, ErrPermissionDenied):
+		return "Your account is not allowed to use this endpoint; check that your premium subscription is active"
+	case errors.Is(err, ErrTwoFactorRequired):
+		return "Complete two-factor authentication on the provider's website and try again"
+	case errors.Is(err, ErrAccountLocked):
+		return "Your account is locked or not activated; log in on the provider's website for details"
+	case errors.Is(err, ErrRateLimited):
+		return "Too many requests; wait a minute and try again"
+	case errors.Is(err, ErrHosterUnsupported):
+		return "This hoster is not supported by the provider; route the host to another provider, or use --direct for a plain URL"
+	case errors.Is(err, ErrHosterUnavailable):
+		return "The hoster is down or in maintenance on the provider's side; try again later"
+	case errors.Is(err, ErrHosterLimitReached):
+		return "You reached the daily limit for this hoster; try again tomorrow or use another mirror"
+	case errors.Is(err, ErrPremiumRequired):
+		return "This hoster requires a premium account"
+	case errors.Is(err, ErrTooManyActiveDownloads):
+		return "Too many active downloads on your account; wait for some to finish and try again"
+	case errors.Is(err, ErrIPNotAllowed):
+		return "Your IP address is not allowed (VPN or server IPs are often blocked); try another network"
+	case errors.Is(err, ErrTrafficExhausted):
+		return "Your traffic quota is exhausted; wait for it to reset, buy extra traffic or configure realdebrid.failover"
+	case errors.Is(err, ErrFileUnavailable):
+		return "The file was removed from the hoster or is temporarily unreachable; check the link in a browser"
+	case errors.Is(err, ErrFileNotAllowed):
+		return "The provider refuses to serve this file"
+	case errors.Is(err, ErrServiceUnavailable):
+		return "The provider is temporarily unavailable; try again later"
+	case errors.Is(err, ErrTorrentInvalid):
+		return "The torrent file or magnet link is invalid"
+	case errors.Is(err, ErrTorrentTooBig):
+		return "The torrent is larger than the provider allows"
+	case errors.Is(err, ErrNotFound):
+		return "The requested resource does not exist on the provider"
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+// Package direct handles plain HTTP(S) and FTP links that are downloaded
+// without going through a debrid provider
+package direct
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+// Options holds what the server of a direct link may need
+type Options struct {
+	Headers   []string // Extra HTTP headers such as "Referer: https://example.com"
+	Cookies   []string // Cookies such as "session=abc"
+	UserAgent string
+	User      string // HTTP basic or FTP authentication as user:password
+}
+
+// Validate checks the headers and credentials
+func (o Options) Validate() error {
+	for _, header := range o.Headers {
+		if name, _, ok := strings.Cut(header, ":"); !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid header %q: must be Name: value", header)
+		}
+	}
+	for _, cookie := range o.Cookies {
+		if !strings.Contains(cookie, "=") {
+			return fmt.Errorf("invalid cookie %q: must be name=value", cookie)
+		}
+	}
+	if o.User != "" && !strings.Contains(o.User, ":") {
+		return fmt.Errorf("invalid user %q: must be user:password", o.User)
+	}
+	return nil
+}
+
+// HeaderLines returns the headers to send, with the cookies in a Cookie
+// header
+func (o Options) HeaderLines() []string {
+	lines := append([]string{}, o.Headers...)
+	if len(o.Cookies) > 0 {
+		lines = append(lines, "Cookie: "+strings.Join(o.Cookies, "; "))
+	}
+	return lines
+}
+
+// Credentials splits User into the user name and password
+func (o Options) Credentials() (string, string) {
+	user, password, _ := strings.Cut(o.User, ":")
+	return user, password
+}
+
+// Supported reports whether a link can be downloaded directly by aria2
+func Supported(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ftp", "sftp":
+		return true
+	}
+	return false
+}
+
+// Probe describes a direct link. For HTTP(S) it asks the server for the
+// file name, size and type with a HEAD request; whatever the server doesn't
+// tell is taken from the URL, so Probe always returns a link.
+func Probe(link string, opts Options) *debrid.Link {
+	result := &debrid.Link{Download: link}
+	u, err := url.Parse(link)
+	if err != nil {
+		return result
+	}
+	result.Host = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if name := path.Base(u.Path); name != "." && name != "/" {
+		result.Filename = name
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return result
+	}
+	req, err := http.NewRequest("HEAD", link, nil)
+	if err != nil {
+		return result
+	}
+	for _, line := range opts.HeaderLines() {
+		name, value, _ := strings.Cut(line, ":")
+		req.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
+	if opts.User != "" {
+		req.SetBasicAuth(opts.Credentials())
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return result
+	}
+	resp.Body.Close()
+	// Servers that refuse HEAD may still serve the file
+	if resp.StatusCode >= 300 {
+		return result
+	}
+
+	// The final URL names the file after redirects
+	if name := path.Base(resp.Request.URL.Path); name != "." && name != "/" {
+		result.Filename = name
+	}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		result.Filename = path.Base(params["filename"])
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		result.MimeType = mediaType
+	}
+	if resp.ContentLength > 0 {
+		result.Filesize = resp.ContentLength
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package direct
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		if r.Method != "HEAD" || user != "alice" || password != "secret" || r.Header.Get("Cookie") != "a=1; b=2" || r.UserAgent() != "test-agent" {
+			t.Errorf("Probe() sent %s %s with user %q, cookie %q, agent %q", r.Method, r.URL, user, r.Header.Get("Cookie"), r.UserAgent())
+		}
+		switch r.URL.Path {
+		case "/files/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Header().Set("Content-Length", "4096")
+		case "/download":
+			w.Header().Set("Content-Disposition", `attachment; filename="movie.mkv"`)
+			w.Header().Set("Content-Type", "video/x-matroska; charset=binary")
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	opts := Options{Cookies: []string{"a=1", "b=2"}, UserAgent: "test-agent", User: "alice:secret"}
+	tests := []struct {
+		path string
+		want debrid.Link
+	}{
+		{path: "/files/report.pdf", want: debrid.Link{Filename: "report.pdf", MimeType: "application/pdf", Filesize: 4096}},
+		{path: "/download?id=7", want: debrid.Link{Filename: "movie.mkv", MimeType: "video/x-matroska"}},
+		// A refused HEAD leaves the name from the URL
+		{path: "/archive.zip", want: debrid.Link{Filename: "archive.zip"}},
+	}
+
+	for _, tt := range tests {
+		link := server.URL + tt.path
+		tt.want.Download = link
+		tt.want.Host = "127.0.0.1"
+		if got := Probe(link, opts); *got != tt.want {
+			t.Errorf("Probe(%s) = %+v, want %+v", tt.path, *got, tt.want)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	opts := Options{Headers: []string{"Referer: https://example.com"}, Cookies: []string{"session=abc"}, User: "bob:pa:ss"}
+	if err := opts.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	lines := opts.HeaderLines()
+	if len(lines) != 2 || lines[1] != "Cookie: session=abc" {
+		t.Errorf("HeaderLines() = %q, want the Referer and Cookie headers", lines)
+	}
+	if user, password := opts.Credentials(); user != "bob" || password != "pa:ss" {
+		t.Errorf("Credentials() = %q, %q, want bob, pa:ss", user, password)
+	}
+
+	for _, invalid := range []Options{{Headers: []string{"no colon"}}, {Cookies: []string{"novalue"}}, {User: "bob"}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate(%+v) error = nil, want an error", invalid)
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		link string
+		want bool
+	}{
+		{"https://example.com/file.zip", true},
+		{"ftp://mirror.example.com/pub/iso", true},
+		{"magnet:?xt=urn:btih:abc", false},
+	}
+	for _, tt := range tests {
+		if got := Supported(tt.link); got != tt.want {
+			t.Errorf("Supported(%q) = %v, want %v", tt.link, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package diskspace
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+)
+
+var (
+	// ErrInsufficientSpace is returned when a download does not fit on disk
+	ErrInsufficientSpace = errors.New("not enough free disk space")
+	// ErrUnsupported is returned where free space cannot be determined
+	ErrUnsupported = errors.New("free space check not supported on this platform")
+)
+
+// Check returns an error wrapping ErrInsufficientSpace if need bytes do not
+// fit in dir while keeping reserve bytes free. Platforms without free space
+// information pass the check.
+func Check(dir string, need, reserve uint64) error {
+	free, err := FreeSpace(dir)
+	if errors.Is(err, ErrUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check free space: %w", err)
+	}
+	return check(free, need, reserve)
+}
+
+// check compares free space with what a download needs
+func check(free, need, reserve uint64) error {
+	if free >= need && free-need >= reserve {
+		return nil
+	}
+	if reserve == 0 {
+		return fmt.Errorf("%w: need %s, %s free", ErrInsufficientSpace,
+			humanize.Bytes(need), humanize.Bytes(free))
+	}
+	return fmt.Errorf("%w: need %s plus %s reserve, %s free", ErrInsufficientSpace,
+		humanize.Bytes(need), humanize.Bytes(reserve), humanize.Bytes(free))
+}
+
+// Downloads is the part of the aria2 client the guard uses
+type Downloads interface {
+	GetActiveDownloads() (map[string]string, error)
+	PauseDownload(gid string) error
+	ResumeDownload(gid string) error
+}
+
+// Holder holds downloads paused for reasons of its own, such as a
+// download schedule
+type Holder interface {
+	Holds(gid string) bool
+}
+
+// Event describes downloads paused or resumed by a guard
+type Event struct {
+	Paused  []string
+	Resumed []string
+	Free    uint64
+	Reserve uint64
+}
+
+// String describes the event for the user
+func (e *Event) String() string {
+	if len(e.Paused) > 0 {
+		return fmt.Sprintf("Low disk space (%s free, %s reserve): paused %d download(s)",
+			humanize.Bytes(e.Free), humanize.Bytes(e.Reserve), len(e.Paused))
+	}
+	if len(e.Resumed) == 0 {
+		return fmt.Sprintf("Disk space recovered (%s free)", humanize.Bytes(e.Free))
+	}
+	return fmt.Sprintf("Disk space recovered (%s free): resumed %s",
+		humanize.Bytes(e.Free), strings.Join(e.Resumed, ", "))
+}
+
+// Guard pauses the downloads it tracks when free space on a filesystem
+// drops below a reserve, and resumes them once space is available again.
+// Downloads writing to other filesystems are left alone.
+type Guard struct {
+	mu        sync.Mutex
+	dir       string
+	reserve   uint64
+	downloads Downloads
+	holder    Holder // Nil if nothing else pauses the downloads
+	free      func(string) (uint64, error)
+	same      func(a, b string) bool
+	gids      []string
+	paused    []string
+}
+
+// NewGuard creates a guard for the filesystem holding dir
+func NewGuard(dir string, reserve uint64, downloads Downloads) *Guard {
+	return &Guard{
+		dir:       dir,
+		reserve:   reserve,
+		downloads: downloads,
+		free:      FreeSpace,
+		same:      SameFilesystem,
+	}
+}
+
+// Track adds a download to those the guard may pause
+func (g *Guard) Track(gid string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.gids = append(g.gids, gid)
+}
+
+// DeferTo leaves downloads that h holds paused when space recovers
+func (g *Guard) DeferTo(h Holder) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.holder = h
+}
+
+// Paused reports whether the guard currently holds downloads paused
+func (g *Guard) Paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.paused) > 0
+}
+
+// Release resumes the downloads the guard holds paused, so none stay
+// paused once venaqui stops watching the free space, and returns them
+func (g *Guard) Release() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.release()
+}
+
+// release resumes the paused downloads, except those the holder keeps paused
+func (g *Guard) release() []string {
+	var resumed []string
+	for _, gid := range g.paused {
+		if g.holder != nil && g.holder.Holds(gid) {
+			continue
+		}
+		// The download may have been removed in the meantime
+		_ = g.downloads.ResumeDownload(gid)
+		resumed = append(resumed, gid)
+	}
+	g.paused = nil
+	return resumed
+}
+
+// Poll checks free space once. It returns an event when downloads were
+// paused or resumed, and nil when nothing changed.
+func (g *Guard) Poll() (*Event, error) {
+	free, err := g.free(g.dir)
+	if errors.Is(err, ErrUnsupported) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check free space: %w", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case len(g.paused) == 0 && free < g.reserve:
+		active, err := g.downloads.GetActiveDownloads()
+		if err != nil {
+			return nil, err
+		}
+		for _, gid := range g.gids {
+			dir, ok := active[gid]
+			if !ok || !g.same(dir, g.dir) {
+				continue
+			}
+			if err := g.downloads.PauseDownload(gid); err != nil {
+				return nil, fmt.Errorf("failed to pause download %s: %w", gid, err)
+			}
+			g.paused = append(g.paused, gid)
+		}
+		if len(g.paused) == 0 {
+			return nil, nil
+		}
+		return &Event{Paused: g.paused, Free: free, Reserve: g.reserve}, nil
+
+	// Wait for some headroom so downloads don't flap around the threshold
+	case len(g.paused) > 0 && free >= g.reserve+g.reserve/10:
+		return &Event{Resumed: g.release(), Free: free, Reserve: g.reserve}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,158 @@
+package diskspace
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	const gb = 1 << 30
+
+	tests := []struct {
+		name    string
+		free    uint64
+		need    uint64
+		reserve uint64
+		wantErr bool
+	}{
+		{name: "fits", free: 10 * gb, need: 5 * gb, reserve: 1 * gb},
+		{name: "fits exactly", free: 6 * gb, need: 5 * gb, reserve: 1 * gb},
+		{name: "eats into reserve", free: 6 * gb, need: 5*gb + 1, reserve: 1 * gb, wantErr: true},
+		{name: "larger than disk", free: 4 * gb, need: 5 * gb, wantErr: true},
+		{name: "unknown size below reserve", free: gb / 2, reserve: 1 * gb, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := check(tt.free, tt.need, tt.reserve)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInsufficientSpace) {
+				t.Errorf("check() error = %v, want ErrInsufficientSpace", err)
+			}
+		})
+	}
+}
+
+func TestFreeSpace(t *testing.T) {
+	switch runtime.GOOS {
+	case "linux", "darwin", "freebsd", "windows":
+	default:
+		t.Skip("free space not supported")
+	}
+
+	free, err := FreeSpace(t.TempDir())
+	if err != nil {
+		t.Fatalf("FreeSpace() error = %v", err)
+	}
+	if free == 0 {
+		t.Error("FreeSpace() = 0, want free space on the temp filesystem")
+	}
+}
+
+// fakeDownloads records pauses and resumes
+type fakeDownloads struct {
+	active  map[string]string // Directories by GID
+	paused  map[string]bool
+	resumed []string
+}
+
+func (f *fakeDownloads) GetActiveDownloads() (map[string]string, error) { return f.active, nil }
+
+func (f *fakeDownloads) PauseDownload(gid string) error {
+	f.paused[gid] = true
+	return nil
+}
+
+func (f *fakeDownloads) ResumeDownload(gid string) error {
+	delete(f.paused, gid)
+	f.resumed = append(f.resumed, gid)
+	return nil
+}
+
+func TestGuard_Poll(t *testing.T) {
+	downloads := &fakeDownloads{
+		active: map[string]string{
+			"a":     "/downloads",
+			"b":     "/downloads/movies",
+			"other": "/downloads",      // Added by another client
+			"usb":   "/mnt/usb/movies", // On another filesystem
+		},
+		paused: map[string]bool{},
+	}
+	guard := NewGuard("/downloads", 1000, downloads)
+	for _, gid := range []string{"a", "b", "usb", "finished"} {
+		guard.Track(gid)
+	}
+
+	free := uint64(5000)
+	guard.free = func(string) (uint64, error) { return free, nil }
+	guard.same = func(a, b string) bool { return !strings.HasPrefix(a, "/mnt/usb") }
+
+	steps := []struct {
+		free        uint64
+		wantPaused  int
+		wantResumed int
+	}{
+		{free: 5000},                 // Plenty of space
+		{free: 999, wantPaused: 2},   // Below the reserve
+		{free: 500},                  // Already paused
+		{free: 1050},                 // Above the reserve but within the headroom
+		{free: 1100, wantResumed: 2}, // Recovered
+		{free: 1100},
+	}
+
+	for i, step := range steps {
+		free = step.free
+		event, err := guard.Poll()
+		if err != nil {
+			t.Fatalf("step %d: Poll() error = %v", i, err)
+		}
+
+		gotPaused, gotResumed := 0, 0
+		if event != nil {
+			gotPaused, gotResumed = len(event.Paused), len(event.Resumed)
+		}
+		if gotPaused != step.wantPaused || gotResumed != step.wantResumed {
+			t.Errorf("step %d: Poll() paused %d resumed %d, want %d and %d",
+				i, gotPaused, gotResumed, step.wantPaused, step.wantResumed)
+		}
+	}
+
+	if len(downloads.paused) != 0 || len(downloads.resumed) != 2 {
+		t.Errorf("downloads paused = %v resumed = %v, want all resumed", downloads.paused, downloads.resumed)
+	}
+}
+
+// fakeHolder holds downloads paused like a closed schedule
+type fakeHolder map[string]bool
+
+func (f fakeHolder) Holds(gid string) bool { return f[gid] }
+
+func TestGuard_Release(t *testing.T) {
+	downloads := &fakeDownloads{active: map[string]string{"a": "/downloads", "b": "/downloads"}, paused: map[string]bool{}}
+	guard := NewGuard("/downloads", 1000, downloads)
+	guard.Track("a")
+	guard.Track("b")
+	guard.free = func(string) (uint64, error) { return 500, nil }
+	guard.same = func(a, b string) bool { return true }
+	guard.DeferTo(fakeHolder{"b": true})
+
+	if _, err := guard.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if !downloads.paused["a"] || !downloads.paused["b"] {
+		t.Fatalf("downloads paused = %v, want a and b", downloads.paused)
+	}
+
+	// Quitting while space is low resumes all but what the schedule holds
+	if got := guard.Release(); len(got) != 1 || got[0] != "a" {
+		t.Errorf("Release() = %v, want [a]", got)
+	}
+	if guard.Paused() || downloads.paused["a"] || !downloads.paused["b"] {
+		t.Errorf("after Release() downloads paused = %v, want only b", downloads.paused)
+	}
+}
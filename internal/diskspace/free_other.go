@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package diskspace
+
+import "path/filepath"
+
+// SameFilesystem reports whether two paths are the same directory, as
+// filesystems can't be told apart on this platform
+func SameFilesystem(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// FreeSpace is not implemented on this platform
+func FreeSpace(path string) (uint64, error) {
+	return 0, ErrUnsupported
+}
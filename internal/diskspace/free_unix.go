@@ -0,0 +1,24 @@
+//go:build linux || darwin || freebsd
+
+package diskspace
+
+import "syscall"
+
+// SameFilesystem reports whether two paths are on the same filesystem
+func SameFilesystem(a, b string) bool {
+	var statA, statB syscall.Stat_t
+	if syscall.Stat(a, &statA) != nil || syscall.Stat(b, &statB) != nil {
+		return false
+	}
+	return statA.Dev == statB.Dev
+}
+
+// FreeSpace returns the bytes available to unprivileged users on the
+// filesystem holding path
+func FreeSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
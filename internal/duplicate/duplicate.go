@@ -0,0 +1,179 @@
+package duplicate
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/history"
+)
+
+// Action is what to do about a duplicate download
+type Action string
+
+const (
+	ActionAsk    Action = "ask"
+	ActionSkip   Action = "skip"
+	ActionResume Action = "resume"
+	ActionForce  Action = "force"
+)
+
+// ParseAction validates an action name
+func ParseAction(s string) (Action, error) {
+	switch a := Action(strings.ToLower(s)); a {
+	case ActionAsk, ActionSkip, ActionResume, ActionForce:
+		return a, nil
+	case "":
+		return ActionAsk, nil
+	}
+	return "", fmt.Errorf("invalid duplicate action %q: must be ask, skip, resume or force", s)
+}
+
+// Source is where a duplicate was found
+type Source string
+
+const (
+	SourceAria2   Source = "aria2"
+	SourceDisk    Source = "disk"
+	SourceHistory Source = "history"
+)
+
+// Candidate describes a download about to start. Empty fields are not compared.
+type Candidate struct {
+	Link        string // Original link given by the user
+	DownloadURL string // Unrestricted link passed to aria2
+	RDID        string
+	TorrentHash string
+	Path        string // Target path
+	Size        int64  // Expected size, 0 if unknown
+}
+
+// Match is an earlier or running copy of a download
+type Match struct {
+	Source Source
+	Reason string // What matched, e.g. "same link"
+	Path   string
+	GID    string // aria2 GID for queued downloads
+	// Partial is set when the download can be resumed: it is queued in
+	// aria2 or a partial file with an aria2 control file is on disk
+	Partial bool
+}
+
+// String describes the match for the user
+func (m Match) String() string {
+	switch {
+	case m.Source == SourceAria2:
+		return fmt.Sprintf("already queued in aria2 (GID %s, %s)", m.GID, m.Reason)
+	case m.Source == SourceDisk && m.Partial:
+		return fmt.Sprintf("partially downloaded at %s", m.Path)
+	case m.Source == SourceDisk:
+		return fmt.Sprintf("already on disk at %s (%s)", m.Path, m.Reason)
+	default:
+		return fmt.Sprintf("downloaded before to %s (%s)", m.Path, m.Reason)
+	}
+}
+
+// Find returns every duplicate of c in aria2's queue, on disk and in the
+// history, in that order. The first match is the most relevant one.
+func Find(c Candidate, entries []history.Entry, queue []*aria2.DownloadStatus) []Match {
+	var matches []Match
+
+	for _, status := range queue {
+		if reason := matchQueued(c, status); reason != "" {
+			matches = append(matches, Match{
+				Source:  SourceAria2,
+				Reason:  reason,
+				Path:    status.GetFilePath(),
+				GID:     status.GID,
+				Partial: true,
+			})
+		}
+	}
+
+	if c.Path != "" {
+		if info, err := os.Stat(c.Path); err == nil && !info.IsDir() {
+			if _, err := os.Stat(c.Path + ".aria2"); err == nil {
+				matches = append(matches, Match{Source: SourceDisk, Reason: "partial download", Path: c.Path, Partial: true})
+			} else if c.Size > 0 && info.Size() == c.Size {
+				matches = append(matches, Match{Source: SourceDisk, Reason: "same name and size", Path: c.Path})
+			}
+		}
+	}
+
+	// Latest entries first; failed, aborted and corrupted downloads are not duplicates
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Status != history.StatusComplete || (entry.Checksum != nil && !entry.Checksum.OK) {
+			continue
+		}
+		if reason := matchEntry(c, entry); reason != "" {
+			matches = append(matches, Match{Source: SourceHistory, Reason: reason, Path: entry.Path})
+		}
+	}
+
+	return matches
+}
+
+// matchQueued compares a candidate with a download in aria2's queue
+func matchQueued(c Candidate, status *aria2.DownloadStatus) string {
+	if c.Path != "" && samePath(status.GetFilePath(), c.Path) {
+		return "same path"
+	}
+	if c.DownloadURL != "" {
+		for _, file := range status.Files {
+			for _, uri := range file.URIs {
+				if uri.URI == c.DownloadURL {
+					return "same link"
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// matchEntry compares a candidate with a history entry
+func matchEntry(c Candidate, entry history.Entry) string {
+	switch {
+	case c.TorrentHash != "" && strings.EqualFold(entry.TorrentHash, c.TorrentHash):
+		return "same torrent"
+	case c.RDID != "" && entry.RDID == c.RDID:
+		return "same Real-Debrid file"
+	case c.Link != "" && entry.Link == c.Link:
+		return "same link"
+	case c.Path != "" && samePath(entry.Path, c.Path):
+		return "same path"
+	}
+	return ""
+}
+
+// samePath compares two paths after cleaning them
+func samePath(a, b string) bool {
+	return a != "" && filepath.Clean(a) == filepath.Clean(b)
+}
+
+// MagnetHash returns the info hash of a magnet link, or an empty string
+func MagnetHash(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "magnet" {
+		return ""
+	}
+	for _, xt := range u.Query()["xt"] {
+		hash, ok := strings.CutPrefix(xt, "urn:btih:")
+		if !ok {
+			continue
+		}
+		// Real-Debrid reports hex hashes; magnets may use base32
+		if len(hash) == 32 {
+			if raw, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash)); err == nil {
+				return hex.EncodeToString(raw)
+			}
+		}
+		return strings.ToLower(hash)
+	}
+	return ""
+}
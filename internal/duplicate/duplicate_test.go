@@ -0,0 +1,107 @@
+package duplicate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/history"
+	"github.com/siku2/arigo"
+)
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	complete := filepath.Join(dir, "complete.mkv")
+	partial := filepath.Join(dir, "partial.mkv")
+	for _, path := range []string{complete, partial, partial + ".aria2"} {
+		if err := os.WriteFile(path, []byte("12345"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := []history.Entry{
+		{Link: "https://host/a", RDID: "RD1", Path: "/old/a.mkv", Status: "complete"},
+		{Link: "https://host/failed", Status: "error"},
+		{Link: "https://host/aborted", Status: "incomplete"},
+		{Link: "https://host/corrupted", Status: "complete", Checksum: &history.Checksum{Algo: "sha256", OK: false}},
+		{TorrentHash: "ABCDEF", Path: "/old/t.mkv", Status: "complete"},
+	}
+	queue := []*aria2.DownloadStatus{
+		{GID: "g1", Files: []arigo.File{{Path: filepath.Join(dir, "queued.mkv"), URIs: []arigo.URI{{URI: "https://rd/queued"}}}}},
+	}
+
+	tests := []struct {
+		name        string
+		candidate   Candidate
+		wantSources []Source
+		wantPartial bool
+	}{
+		{name: "no duplicate", candidate: Candidate{Link: "https://host/new", Path: filepath.Join(dir, "new.mkv")}},
+		{name: "failed downloads are ignored", candidate: Candidate{Link: "https://host/failed"}},
+		{name: "aborted downloads are ignored", candidate: Candidate{Link: "https://host/aborted"}},
+		{name: "corrupted downloads are ignored", candidate: Candidate{Link: "https://host/corrupted"}},
+		{name: "same link", candidate: Candidate{Link: "https://host/a"}, wantSources: []Source{SourceHistory}},
+		{name: "same RD ID", candidate: Candidate{RDID: "RD1"}, wantSources: []Source{SourceHistory}},
+		{name: "same torrent hash", candidate: Candidate{TorrentHash: "abcdef"}, wantSources: []Source{SourceHistory}},
+		{name: "same size on disk", candidate: Candidate{Path: complete, Size: 5}, wantSources: []Source{SourceDisk}},
+		{name: "different size on disk", candidate: Candidate{Path: complete, Size: 6}},
+		{name: "partial on disk", candidate: Candidate{Path: partial}, wantSources: []Source{SourceDisk}, wantPartial: true},
+		{name: "queued by URL", candidate: Candidate{DownloadURL: "https://rd/queued"}, wantSources: []Source{SourceAria2}, wantPartial: true},
+		{
+			name:        "queued by path and in history",
+			candidate:   Candidate{Link: "https://host/a", Path: filepath.Join(dir, "queued.mkv")},
+			wantSources: []Source{SourceAria2, SourceHistory},
+			wantPartial: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := Find(tt.candidate, entries, queue)
+			if len(matches) != len(tt.wantSources) {
+				t.Fatalf("Find() = %v, want sources %v", matches, tt.wantSources)
+			}
+			for i, m := range matches {
+				if m.Source != tt.wantSources[i] {
+					t.Errorf("Find()[%d].Source = %v, want %v", i, m.Source, tt.wantSources[i])
+				}
+			}
+			if len(matches) > 0 && matches[0].Partial != tt.wantPartial {
+				t.Errorf("Find()[0].Partial = %v, want %v", matches[0].Partial, tt.wantPartial)
+			}
+		})
+	}
+}
+
+func TestMagnetHash(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want string
+	}{
+		{name: "hex", link: "magnet:?xt=urn:btih:C12FE1C06BBA254A9DC9F519B335AA7C1367A88A&dn=x", want: "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"},
+		{name: "base32", link: "magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK", want: "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"},
+		{name: "not a magnet", link: "https://example.com/file", want: ""},
+		{name: "no btih", link: "magnet:?dn=x", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MagnetHash(tt.link); got != tt.want {
+				t.Errorf("MagnetHash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAction(t *testing.T) {
+	for _, s := range []string{"", "ask", "Skip", "resume", "force"} {
+		if _, err := ParseAction(s); err != nil {
+			t.Errorf("ParseAction(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseAction("merge"); err == nil {
+		t.Error("ParseAction(merge) expected error")
+	}
+}
@@ -0,0 +1,216 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the archive format of a set
+type Kind string
+
+const (
+	KindRAR Kind = "rar"
+	Kind7z  Kind = "7z"
+	KindZIP Kind = "zip"
+)
+
+// Set is an archive, possibly split into several parts
+type Set struct {
+	Kind  Kind
+	Dir   string
+	Name  string   // Base name shared by all parts
+	First string   // Path of the part extraction starts from
+	Parts []string // Paths of all parts found on disk, in order
+}
+
+var (
+	// movie.part01.rar, movie.part1.rar
+	partRARPattern = regexp.MustCompile(`(?i)^(.+)\.part(\d+)\.rar$`)
+	// movie.rar, movie.r00, movie.r01
+	oldRARPattern = regexp.MustCompile(`(?i)^(.+)\.(rar|r\d{2,3})$`)
+	// movie.7z.001, movie.zip.001, movie.001
+	numberedPattern = regexp.MustCompile(`(?i)^(.+?)(\.(7z|zip|rar))?\.(\d{3})$`)
+	// movie.7z, movie.zip
+	singlePattern = regexp.MustCompile(`(?i)^(.+)\.(7z|zip)$`)
+)
+
+// Detect returns the archive set a file belongs to, or nil if the file
+// is not an archive. Parts are collected from the file's directory.
+func Detect(path string) (*Set, error) {
+	dir := filepath.Dir(path)
+	name := filepath.Base(path)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, entry.Name())
+		}
+	}
+
+	var set *Set
+	switch {
+	case partRARPattern.MatchString(name):
+		base := partRARPattern.FindStringSubmatch(name)[1]
+		set = &Set{Kind: KindRAR, Dir: dir, Name: base}
+		set.Parts = matchParts(files, func(f string) (int, bool) {
+			m := partRARPattern.FindStringSubmatch(f)
+			if m == nil || m[1] != base {
+				return 0, false
+			}
+			n, _ := strconv.Atoi(m[2])
+			return n, true
+		})
+	case numberedPattern.MatchString(name):
+		m := numberedPattern.FindStringSubmatch(name)
+		base, ext := m[1], strings.ToLower(m[3])
+		kind := Kind7z
+		if ext == "zip" {
+			kind = KindZIP
+		} else if ext == "rar" {
+			kind = KindRAR
+		}
+		set = &Set{Kind: kind, Dir: dir, Name: base}
+		set.Parts = matchParts(files, func(f string) (int, bool) {
+			m := numberedPattern.FindStringSubmatch(f)
+			if m == nil || m[1] != base || strings.ToLower(m[3]) != ext {
+				return 0, false
+			}
+			n, _ := strconv.Atoi(m[4])
+			return n, true
+		})
+	case oldRARPattern.MatchString(name):
+		base := oldRARPattern.FindStringSubmatch(name)[1]
+		set = &Set{Kind: KindRAR, Dir: dir, Name: base}
+		set.Parts = matchParts(files, func(f string) (int, bool) {
+			m := oldRARPattern.FindStringSubmatch(f)
+			if m == nil || m[1] != base {
+				return 0, false
+			}
+			// The .rar volume comes first, then .r00, .r01, ...
+			if strings.EqualFold(m[2], "rar") {
+				return -1, true
+			}
+			n, _ := strconv.Atoi(m[2][1:])
+			return n, true
+		})
+	case singlePattern.MatchString(name):
+		m := singlePattern.FindStringSubmatch(name)
+		kind := Kind7z
+		if strings.EqualFold(m[2], "zip") {
+			kind = KindZIP
+		}
+		set = &Set{Kind: kind, Dir: dir, Name: m[1], Parts: []string{name}}
+	default:
+		return nil, nil
+	}
+
+	for i, part := range set.Parts {
+		set.Parts[i] = filepath.Join(dir, part)
+	}
+	if len(set.Parts) > 0 {
+		set.First = set.Parts[0]
+	}
+	return set, nil
+}
+
+// Contains reports whether path looks like a part of this set, even if
+// it is not on disk yet
+func (s *Set) Contains(path string) bool {
+	if filepath.Dir(path) != s.Dir {
+		return false
+	}
+	name := filepath.Base(path)
+	for _, pattern := range []*regexp.Regexp{partRARPattern, numberedPattern, oldRARPattern, singlePattern} {
+		if m := pattern.FindStringSubmatch(name); m != nil && m[1] == s.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns a description of parts that are known to be missing or
+// still being written. pending holds paths aria2 has not finished yet.
+func (s *Set) Missing(pending []string) []string {
+	var missing []string
+
+	for _, p := range pending {
+		if s.Contains(p) {
+			missing = append(missing, filepath.Base(p))
+		}
+	}
+
+	for _, part := range s.Parts {
+		// aria2 keeps a control file next to unfinished downloads
+		if _, err := os.Stat(part + ".aria2"); err == nil {
+			missing = append(missing, filepath.Base(part))
+		}
+	}
+
+	// Numbered parts must be contiguous
+	if s.Kind == KindRAR && partRARPattern.MatchString(filepath.Base(s.First)) {
+		missing = append(missing, gaps(s.Parts, partRARPattern, 2, 1)...)
+	} else if numberedPattern.MatchString(filepath.Base(s.First)) {
+		missing = append(missing, gaps(s.Parts, numberedPattern, 4, 1)...)
+	}
+
+	return missing
+}
+
+// Complete reports whether every part of the set has finished downloading
+func (s *Set) Complete(pending []string) bool {
+	return len(s.Parts) > 0 && len(s.Missing(pending)) == 0
+}
+
+// matchParts returns the files accepted by index, sorted by part number
+func matchParts(files []string, index func(string) (int, bool)) []string {
+	type part struct {
+		name string
+		n    int
+	}
+	var parts []part
+	for _, f := range files {
+		if n, ok := index(f); ok {
+			parts = append(parts, part{f, n})
+		}
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].n < parts[j].n })
+
+	names := make([]string, len(parts))
+	for i, p := range parts {
+		names[i] = p.name
+	}
+	return names
+}
+
+// gaps returns placeholders for part numbers missing between first and the highest part
+func gaps(parts []string, pattern *regexp.Regexp, group, first int) []string {
+	seen := make(map[int]bool)
+	highest := 0
+	for _, part := range parts {
+		m := pattern.FindStringSubmatch(filepath.Base(part))
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[group])
+		seen[n] = true
+		if n > highest {
+			highest = n
+		}
+	}
+
+	var missing []string
+	for n := first; n <= highest; n++ {
+		if !seen[n] {
+			missing = append(missing, "part "+strconv.Itoa(n))
+		}
+	}
+	return missing
+}
@@ -0,0 +1,167 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func touch(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		path      string
+		wantNil   bool
+		wantKind  Kind
+		wantParts []string
+	}{
+		{
+			name:    "not an archive",
+			files:   []string{"movie.mkv"},
+			path:    "movie.mkv",
+			wantNil: true,
+		},
+		{
+			name:      "part numbered rar",
+			files:     []string{"movie.part02.rar", "movie.part01.rar", "movie.part10.rar", "other.part01.rar"},
+			path:      "movie.part02.rar",
+			wantKind:  KindRAR,
+			wantParts: []string{"movie.part01.rar", "movie.part02.rar", "movie.part10.rar"},
+		},
+		{
+			name:      "old style rar volumes",
+			files:     []string{"movie.r01", "movie.rar", "movie.r00"},
+			path:      "movie.r00",
+			wantKind:  KindRAR,
+			wantParts: []string{"movie.rar", "movie.r00", "movie.r01"},
+		},
+		{
+			name:      "numbered 7z",
+			files:     []string{"backup.7z.002", "backup.7z.001"},
+			path:      "backup.7z.002",
+			wantKind:  Kind7z,
+			wantParts: []string{"backup.7z.001", "backup.7z.002"},
+		},
+		{
+			name:      "numbered zip",
+			files:     []string{"backup.zip.001"},
+			path:      "backup.zip.001",
+			wantKind:  KindZIP,
+			wantParts: []string{"backup.zip.001"},
+		},
+		{
+			name:      "single zip",
+			files:     []string{"photos.zip"},
+			path:      "photos.zip",
+			wantKind:  KindZIP,
+			wantParts: []string{"photos.zip"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			touch(t, dir, tt.files...)
+
+			set, err := Detect(filepath.Join(dir, tt.path))
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			if tt.wantNil {
+				if set != nil {
+					t.Errorf("Detect() = %+v, want nil", set)
+				}
+				return
+			}
+			if set == nil {
+				t.Fatal("Detect() = nil, want set")
+			}
+			if set.Kind != tt.wantKind {
+				t.Errorf("Detect().Kind = %v, want %v", set.Kind, tt.wantKind)
+			}
+
+			var parts []string
+			for _, p := range set.Parts {
+				parts = append(parts, filepath.Base(p))
+			}
+			if !reflect.DeepEqual(parts, tt.wantParts) {
+				t.Errorf("Detect().Parts = %v, want %v", parts, tt.wantParts)
+			}
+			if filepath.Base(set.First) != tt.wantParts[0] {
+				t.Errorf("Detect().First = %v, want %v", filepath.Base(set.First), tt.wantParts[0])
+			}
+		})
+	}
+}
+
+func TestSet_Complete(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		pending []string
+		want    bool
+	}{
+		{
+			name:  "all parts present",
+			files: []string{"movie.part1.rar", "movie.part2.rar"},
+			want:  true,
+		},
+		{
+			name:  "gap in numbering",
+			files: []string{"movie.part1.rar", "movie.part3.rar"},
+			want:  false,
+		},
+		{
+			name:  "first part missing",
+			files: []string{"movie.part2.rar"},
+			want:  false,
+		},
+		{
+			name:  "part still downloading",
+			files: []string{"movie.part1.rar", "movie.part2.rar", "movie.part2.rar.aria2"},
+			want:  false,
+		},
+		{
+			name:    "part pending in aria2",
+			files:   []string{"movie.part1.rar", "movie.part2.rar"},
+			pending: []string{"movie.part3.rar"},
+			want:    false,
+		},
+		{
+			name:    "unrelated download pending",
+			files:   []string{"movie.part1.rar"},
+			pending: []string{"other.part2.rar"},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			touch(t, dir, tt.files...)
+
+			set, err := Detect(filepath.Join(dir, tt.files[0]))
+			if err != nil || set == nil {
+				t.Fatalf("Detect() = %v, %v", set, err)
+			}
+
+			var pending []string
+			for _, p := range tt.pending {
+				pending = append(pending, filepath.Join(dir, p))
+			}
+			if got := set.Complete(pending); got != tt.want {
+				t.Errorf("Complete() = %v, want %v (missing %v)", got, tt.want, set.Missing(pending))
+			}
+		})
+	}
+}
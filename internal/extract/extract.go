@@ -0,0 +1,200 @@
+package extract
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoTool is returned when no extraction tool for an archive is installed
+var ErrNoTool = errors.New("no extraction tool found")
+
+// ErrWrongPassword is returned when none of the configured passwords worked
+var ErrWrongPassword = errors.New("archive is encrypted and no password matched")
+
+// Options configures extraction
+type Options struct {
+	Dest           string   // Destination directory; relative paths are resolved against the archive directory
+	Passwords      []string // Passwords to try after trying without one
+	DeleteArchives bool     // Remove all parts after a successful extraction
+}
+
+// Progress reports extraction progress
+type Progress struct {
+	Percent  int    // 0-100, or -1 if the tool does not report percentages
+	Password int    // Index of the password being tried, -1 for none
+	Line     string // Last line of tool output
+}
+
+// Extractor extracts archive sets using installed command-line tools
+type Extractor struct {
+	opts     Options
+	lookPath func(string) (string, error)
+}
+
+// NewExtractor creates a new extractor
+func NewExtractor(opts Options) *Extractor {
+	return &Extractor{
+		opts:     opts,
+		lookPath: exec.LookPath,
+	}
+}
+
+// Destination returns the directory a set is extracted into
+func (e *Extractor) Destination(set *Set) string {
+	dest := e.opts.Dest
+	if dest == "" {
+		return set.Dir
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(set.Dir, dest)
+	}
+	return dest
+}
+
+// Extract extracts a complete set, trying each password in turn, and
+// optionally deletes the archives afterwards. progress may be nil.
+func (e *Extractor) Extract(ctx context.Context, set *Set, progress func(Progress)) (string, error) {
+	if set.First == "" {
+		return "", fmt.Errorf("archive set %s has no parts", set.Name)
+	}
+
+	dest := e.Destination(set)
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return "", fmt.Errorf("failed to create extraction directory: %w", err)
+	}
+
+	passwords := append([]string{""}, e.opts.Passwords...)
+	var lastErr error
+	for i, password := range passwords {
+		args, tool, err := e.command(set, dest, password)
+		if err != nil {
+			return "", err
+		}
+
+		lastErr = run(ctx, tool, args, func(p Progress) {
+			if progress != nil {
+				p.Password = i - 1
+				progress(p)
+			}
+		})
+		if lastErr == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+	}
+	if lastErr != nil {
+		if len(passwords) > 1 {
+			return "", fmt.Errorf("%w: %v", ErrWrongPassword, lastErr)
+		}
+		return "", lastErr
+	}
+
+	if e.opts.DeleteArchives {
+		for _, part := range set.Parts {
+			if err := os.Remove(part); err != nil && !os.IsNotExist(err) {
+				return dest, fmt.Errorf("extracted, but failed to delete %s: %w", filepath.Base(part), err)
+			}
+		}
+	}
+
+	return dest, nil
+}
+
+// command picks a tool for the set and builds its arguments.
+// 7z handles every format, so it is preferred when installed.
+func (e *Extractor) command(set *Set, dest, password string) ([]string, string, error) {
+	// Always pass a password flag so tools never prompt on stdin
+	pw := password
+	if pw == "" {
+		pw = "-"
+	}
+
+	for _, name := range []string{"7z", "7zz", "7za"} {
+		if path, err := e.lookPath(name); err == nil {
+			return []string{"x", "-y", "-bsp1", "-p" + pw, "-o" + dest, set.First}, path, nil
+		}
+	}
+
+	switch set.Kind {
+	case KindRAR:
+		if path, err := e.lookPath("unrar"); err == nil {
+			return []string{"x", "-y", "-o+", "-p" + pw, set.First, dest + string(filepath.Separator)}, path, nil
+		}
+	case KindZIP:
+		if len(set.Parts) == 1 {
+			if path, err := e.lookPath("unzip"); err == nil {
+				return []string{"-o", "-P", password, set.First, "-d", dest}, path, nil
+			}
+		}
+	}
+
+	return nil, "", fmt.Errorf("%w for %s archives (install 7z)", ErrNoTool, set.Kind)
+}
+
+// percentPattern matches progress percentages printed by 7z and unrar
+var percentPattern = regexp.MustCompile(`(\d{1,3})%`)
+
+// run executes an extraction tool and reports progress parsed from its output
+func run(ctx context.Context, tool string, args []string, progress func(Progress)) error {
+	cmd := exec.CommandContext(ctx, tool, args...)
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", filepath.Base(tool), err)
+	}
+
+	var last string
+	scanner := bufio.NewScanner(out)
+	scanner.Split(scanLinesOrCR)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		last = line
+
+		p := Progress{Percent: -1, Line: line}
+		if m := percentPattern.FindAllStringSubmatch(line, -1); m != nil {
+			p.Percent, _ = strconv.Atoi(m[len(m)-1][1])
+		}
+		progress(p)
+	}
+	_, _ = io.Copy(io.Discard, out)
+
+	if err := cmd.Wait(); err != nil {
+		if last != "" {
+			return fmt.Errorf("%s failed: %w: %s", filepath.Base(tool), err, last)
+		}
+		return fmt.Errorf("%s failed: %w", filepath.Base(tool), err)
+	}
+	return nil
+}
+
+// scanLinesOrCR splits output on \n and \r, since tools redraw progress with \r
+func scanLinesOrCR(data []byte, atEOF bool) (int, []byte, error) {
+	for i, b := range data {
+		if b == '\n' || b == '\r' || b == '\b' {
+			return i + 1, data[:i], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
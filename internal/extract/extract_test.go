@@ -0,0 +1,114 @@
+package extract
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractor_Command(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		kind      Kind
+		wantTool  string
+		wantErr   error
+	}{
+		{
+			name:      "7z preferred",
+			installed: []string{"7z", "unrar"},
+			kind:      KindRAR,
+			wantTool:  "7z",
+		},
+		{
+			name:      "unrar fallback",
+			installed: []string{"unrar"},
+			kind:      KindRAR,
+			wantTool:  "unrar",
+		},
+		{
+			name:      "unzip fallback",
+			installed: []string{"unzip"},
+			kind:      KindZIP,
+			wantTool:  "unzip",
+		},
+		{
+			name:      "no tool",
+			installed: []string{"unzip"},
+			kind:      KindRAR,
+			wantErr:   ErrNoTool,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExtractor(Options{})
+			e.lookPath = func(name string) (string, error) {
+				for _, installed := range tt.installed {
+					if installed == name {
+						return name, nil
+					}
+				}
+				return "", exec.ErrNotFound
+			}
+
+			set := &Set{Kind: tt.kind, First: "/tmp/a", Parts: []string{"/tmp/a"}}
+			_, tool, err := e.command(set, "/tmp/out", "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("command() error = %v, want %v", err, tt.wantErr)
+			}
+			if tool != tt.wantTool {
+				t.Errorf("command() tool = %v, want %v", tool, tt.wantTool)
+			}
+		})
+	}
+}
+
+func TestExtractor_ExtractZip(t *testing.T) {
+	if _, err := exec.LookPath("7z"); err != nil {
+		if _, err := exec.LookPath("unzip"); err != nil {
+			t.Skip("neither 7z nor unzip is installed")
+		}
+	}
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "photos.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("failed to create zip: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("inside.txt")
+	if err != nil {
+		t.Fatalf("failed to add zip entry: %v", err)
+	}
+	w.Write([]byte("hello"))
+	zw.Close()
+	f.Close()
+
+	set, err := Detect(archive)
+	if err != nil || set == nil {
+		t.Fatalf("Detect() = %v, %v", set, err)
+	}
+
+	e := NewExtractor(Options{Dest: "extracted", DeleteArchives: true})
+	dest, err := e.Extract(context.Background(), set, nil)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if dest != filepath.Join(dir, "extracted") {
+		t.Errorf("Extract() dest = %v, want %v", dest, filepath.Join(dir, "extracted"))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dest, "inside.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("extracted file = %q, %v, want hello", data, err)
+	}
+	if _, err := os.Stat(archive); !os.IsNotExist(err) {
+		t.Errorf("archive still exists after extraction with DeleteArchives")
+	}
+}
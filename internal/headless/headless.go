@@ -0,0 +1,290 @@
+package headless
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/hooks"
+	"github.com/mhrsntrk/venaqui/internal/verify"
+)
+
+// Mode selects how progress is reported without the TUI
+type Mode int
+
+const (
+	// ModeText prints human-readable progress lines
+	ModeText Mode = iota
+	// ModeQuiet prints nothing but errors
+	ModeQuiet
+	// ModeJSON prints newline-delimited JSON events
+	ModeJSON
+)
+
+// Phases reported in events
+const (
+	PhaseResolving   = "resolving"
+	PhaseDownloading = "downloading"
+	PhaseComplete    = "complete"
+	PhaseVerified    = "verified"
+	PhaseCorrupted   = "corrupted"
+	PhaseExtracting  = "extracting"
+	PhaseExtracted   = "extracted"
+	PhaseHook        = "hook"
+	PhaseWarning     = "warning"
+	PhaseError       = "error"
+)
+
+// Event is a single progress event emitted in JSON mode
+type Event struct {
+	Time           time.Time `json:"time"`
+	Phase          string    `json:"phase"`
+	Message        string    `json:"message,omitempty"`
+	GID            string    `json:"gid,omitempty"`
+	Filename       string    `json:"filename,omitempty"`
+	CompletedBytes int64     `json:"completed_bytes"`
+	TotalBytes     int64     `json:"total_bytes"`
+	Speed          int64     `json:"speed"`
+	ETA            int64     `json:"eta"`               // Seconds left at the smoothed speed, -1 if unknown
+	ETAMin         int64     `json:"eta_min,omitempty"` // Range the seconds left may vary in
+	ETAMax         int64     `json:"eta_max,omitempty"`
+	Path           string    `json:"path,omitempty"`
+	Checksum       string    `json:"checksum,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// StatusSource returns the current status of a download
+type StatusSource interface {
+	GetStatus(gid string) (*aria2.DownloadStatus, error)
+}
+
+// Reporter writes progress in the selected mode
+type Reporter struct {
+	mode Mode
+	out  io.Writer
+	err  io.Writer
+	now  func() time.Time
+	eta  *aria2.ETAEstimator // Smooths the speed of the download being followed
+}
+
+// NewReporter creates a reporter writing progress to out and diagnostics to errOut
+func NewReporter(mode Mode, out, errOut io.Writer) *Reporter {
+	return &Reporter{
+		mode: mode,
+		out:  out,
+		err:  errOut,
+		now:  time.Now,
+		eta:  aria2.NewETAEstimator(),
+	}
+}
+
+// Status reports a step before the download starts, such as unrestricting a link
+func (r *Reporter) Status(message string) {
+	switch r.mode {
+	case ModeText:
+		fmt.Fprintln(r.out, message)
+	case ModeJSON:
+		r.emit(Event{Phase: PhaseResolving, Message: message})
+	}
+}
+
+// Error reports a fatal error as an event in JSON mode. Other modes
+// leave printing the error to the caller.
+func (r *Reporter) Error(message string, err error) {
+	if r.mode == ModeJSON {
+		r.emit(Event{Phase: PhaseError, Message: message, Error: err.Error()})
+	}
+}
+
+// Progress reports a download status update
+func (r *Reporter) Progress(filename string, status *aria2.DownloadStatus) {
+	r.eta.Update(r.now(), status)
+	switch r.mode {
+	case ModeText:
+		fmt.Fprintln(r.out, formatProgress(filename, status, r.eta.Estimate(status)))
+	case ModeJSON:
+		r.emit(newEvent(filename, status, r.eta.Estimate(status)))
+	}
+}
+
+// Verification reports the outcome of verifying a completed download
+func (r *Reporter) Verification(filename string, result *verify.Result) {
+	phase, text := PhaseVerified, "verified"
+	if !result.OK() {
+		phase, text = PhaseCorrupted, "MISMATCH"
+	}
+
+	switch r.mode {
+	case ModeText:
+		fmt.Fprintf(r.out, "Checksum %s (%s from %s): %s\n", text, result.Algo, result.Source, result.Path)
+	case ModeJSON:
+		r.emit(Event{
+			Phase:    phase,
+			Filename: filename,
+			Path:     result.Path,
+			Checksum: result.Algo + "=" + result.Actual,
+		})
+	}
+}
+
+// Extraction reports a post-processing step for an archive set
+func (r *Reporter) Extraction(phase, message, path string) {
+	switch r.mode {
+	case ModeText:
+		fmt.Fprintln(r.out, message)
+	case ModeJSON:
+		r.emit(Event{Phase: phase, Message: message, Path: path})
+	}
+}
+
+// Warning reports a problem that does not stop the download, such as
+// downloads being paused for lack of disk space
+func (r *Reporter) Warning(message string) {
+	switch r.mode {
+	case ModeJSON:
+		r.emit(Event{Phase: PhaseWarning, Message: message})
+	default:
+		fmt.Fprintln(r.err, message)
+	}
+}
+
+// Hook reports the outcome of a user-defined hook. Failed hooks are
+// printed to the error writer in text and quiet modes.
+func (r *Reporter) Hook(result hooks.Result) {
+	switch r.mode {
+	case ModeJSON:
+		event := Event{Phase: PhaseHook, Message: string(result.Event) + ": " + result.Name}
+		if result.Err != nil {
+			event.Error = result.Err.Error()
+		}
+		r.emit(event)
+	default:
+		if result.Err != nil {
+			fmt.Fprintf(r.err, "Hook %s failed: %s: %v\n", result.Event, result.Name, result.Err)
+			if result.Output != "" {
+				fmt.Fprintln(r.err, result.Output)
+			}
+		} else if r.mode == ModeText {
+			fmt.Fprintf(r.out, "Hook %s ran: %s (%s)\n", result.Event, result.Name, result.Duration.Round(time.Millisecond))
+		}
+	}
+}
+
+// Run polls aria2 until the download completes or fails, reporting
+// progress every interval, and returns the final status. If aria2
+// reports an error, it returns an error wrapping aria2.ErrDownloadFailed;
+// if the download is removed from aria2, one wrapping aria2.ErrDownloadRemoved.
+func (r *Reporter) Run(source StatusSource, gid, filename string, interval time.Duration) (*aria2.DownloadStatus, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	r.eta = aria2.NewETAEstimator()
+
+	for {
+		status, err := source.GetStatus(gid)
+		if err != nil {
+			r.Error("failed to get download status", err)
+			return nil, err
+		}
+
+		switch {
+		case status.IsComplete():
+			r.finish(filename, status)
+			return status, nil
+		case status.IsError():
+			message := status.ErrorMessage
+			if message == "" {
+				message = "aria2 reported an error"
+			}
+			err := fmt.Errorf("%w: %s", aria2.ErrDownloadFailed, message)
+			if r.mode == ModeJSON {
+				event := newEvent(filename, status, r.eta.Estimate(status))
+				event.Error = message
+				r.emit(event)
+			}
+			return status, err
+		case status.IsRemoved():
+			message := "the download was removed from aria2"
+			if r.mode == ModeJSON {
+				event := newEvent(filename, status, r.eta.Estimate(status))
+				event.Error = message
+				r.emit(event)
+			}
+			return status, fmt.Errorf("%w: %s", aria2.ErrDownloadRemoved, message)
+		default:
+			r.Progress(filename, status)
+		}
+
+		<-ticker.C
+	}
+}
+
+// finish reports a completed download
+func (r *Reporter) finish(filename string, status *aria2.DownloadStatus) {
+	switch r.mode {
+	case ModeText:
+		fmt.Fprintf(r.out, "Download complete: %s\n", status.GetFilePath())
+	case ModeJSON:
+		r.emit(newEvent(filename, status, r.eta.Estimate(status)))
+	}
+}
+
+// emit writes a single JSON event
+func (r *Reporter) emit(event Event) {
+	event.Time = r.now()
+	data, err := json.Marshal(event)
+	if err != nil {
+		fmt.Fprintf(r.err, "failed to encode event: %v\n", err)
+		return
+	}
+	fmt.Fprintln(r.out, string(data))
+}
+
+// newEvent builds an event from a download status and its estimated time left
+func newEvent(filename string, status *aria2.DownloadStatus, eta aria2.ETA) Event {
+	phase := PhaseDownloading
+	switch {
+	case status.IsComplete():
+		phase = PhaseComplete
+	case status.IsError(), status.IsRemoved():
+		phase = PhaseError
+	}
+
+	event := Event{
+		Phase:          phase,
+		GID:            status.GID,
+		Filename:       filename,
+		CompletedBytes: status.CompletedLength,
+		TotalBytes:     status.TotalLength,
+		Speed:          status.DownloadSpeed,
+		ETA:            -1,
+		Path:           status.GetFilePath(),
+	}
+	if eta.Known {
+		event.ETA = int64(eta.Expected.Seconds())
+		event.ETAMin = int64(eta.Min.Seconds())
+		event.ETAMax = int64(eta.Max.Seconds())
+	}
+	return event
+}
+
+// formatProgress renders a single human-readable progress line
+func formatProgress(filename string, status *aria2.DownloadStatus, estimate aria2.ETA) string {
+	eta := "--"
+	if estimate.Known && estimate.Expected > 0 {
+		eta = estimate.Expected.String()
+		if estimate.Max-estimate.Min >= time.Second {
+			eta += fmt.Sprintf(" (%s to %s)", estimate.Min, estimate.Max)
+		}
+	}
+	return fmt.Sprintf("%s: %5.1f%% %s / %s at %s/s, ETA %s",
+		filename,
+		status.GetProgress(),
+		humanize.Bytes(uint64(status.CompletedLength)),
+		humanize.Bytes(uint64(status.TotalLength)),
+		humanize.Bytes(uint64(status.DownloadSpeed)),
+		eta,
+	)
+}
@@ -0,0 +1,124 @@
+package headless
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+)
+
+// fakeSource replays a fixed sequence of statuses
+type fakeSource struct {
+	statuses []*aria2.DownloadStatus
+	calls    int
+}
+
+func (f *fakeSource) GetStatus(gid string) (*aria2.DownloadStatus, error) {
+	status := f.statuses[f.calls]
+	if f.calls < len(f.statuses)-1 {
+		f.calls++
+	}
+	return status, nil
+}
+
+func decodeEvents(t *testing.T, data []byte) []Event {
+	t.Helper()
+	var events []Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestRun_JSONComplete(t *testing.T) {
+	source := &fakeSource{statuses: []*aria2.DownloadStatus{
+		{GID: "abc", Status: "active", TotalLength: 1000, CompletedLength: 250, DownloadSpeed: 250},
+		{GID: "abc", Status: "active", TotalLength: 1000, CompletedLength: 500, DownloadSpeed: 250},
+		{GID: "abc", Status: "complete", TotalLength: 1000, CompletedLength: 1000},
+	}}
+
+	var out, errOut bytes.Buffer
+	reporter := NewReporter(ModeJSON, &out, &errOut)
+
+	if _, err := reporter.Run(source, "abc", "file.zip", time.Millisecond); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	events := decodeEvents(t, out.Bytes())
+	if len(events) != 3 {
+		t.Fatalf("Run() emitted %d events, want 3", len(events))
+	}
+	if events[0].Phase != PhaseDownloading || events[0].ETA != 3 {
+		t.Errorf("first event = %+v, want downloading with ETA 3", events[0])
+	}
+	last := events[len(events)-1]
+	if last.Phase != PhaseComplete || last.GID != "abc" || last.CompletedBytes != 1000 {
+		t.Errorf("last event = %+v, want complete for gid abc", last)
+	}
+}
+
+func TestRun_JSONError(t *testing.T) {
+	source := &fakeSource{statuses: []*aria2.DownloadStatus{
+		{GID: "abc", Status: "error", ErrorMessage: "resource not found"},
+	}}
+
+	var out, errOut bytes.Buffer
+	reporter := NewReporter(ModeJSON, &out, &errOut)
+
+	_, err := reporter.Run(source, "abc", "file.zip", time.Millisecond)
+	if !errors.Is(err, aria2.ErrDownloadFailed) {
+		t.Fatalf("Run() error = %v, want aria2.ErrDownloadFailed", err)
+	}
+
+	events := decodeEvents(t, out.Bytes())
+	if len(events) != 1 || events[0].Phase != PhaseError || events[0].Error != "resource not found" {
+		t.Errorf("Run() events = %+v, want a single error event", events)
+	}
+}
+
+func TestRun_Removed(t *testing.T) {
+	source := &fakeSource{statuses: []*aria2.DownloadStatus{
+		{GID: "abc", Status: "active", TotalLength: 1000, CompletedLength: 250, DownloadSpeed: 250},
+		{GID: "abc", Status: "removed", TotalLength: 1000, CompletedLength: 250},
+	}}
+
+	var out, errOut bytes.Buffer
+	reporter := NewReporter(ModeJSON, &out, &errOut)
+
+	_, err := reporter.Run(source, "abc", "file.zip", time.Millisecond)
+	if !errors.Is(err, aria2.ErrDownloadRemoved) {
+		t.Fatalf("Run() error = %v, want aria2.ErrDownloadRemoved", err)
+	}
+
+	events := decodeEvents(t, out.Bytes())
+	if len(events) != 2 || events[1].Phase != PhaseError || events[1].Error == "" {
+		t.Errorf("Run() events = %+v, want a progress and an error event", events)
+	}
+}
+
+func TestReporter_Quiet(t *testing.T) {
+	source := &fakeSource{statuses: []*aria2.DownloadStatus{
+		{GID: "abc", Status: "active", TotalLength: 1000, CompletedLength: 500},
+		{GID: "abc", Status: "complete", TotalLength: 1000, CompletedLength: 1000},
+	}}
+
+	var out, errOut bytes.Buffer
+	reporter := NewReporter(ModeQuiet, &out, &errOut)
+	reporter.Status("Unrestricting link via Real-Debrid...")
+
+	if _, err := reporter.Run(source, "abc", "file.zip", time.Millisecond); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("quiet mode wrote %q, want nothing", out.String())
+	}
+}
@@ -0,0 +1,117 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/config"
+	"github.com/mhrsntrk/venaqui/internal/media"
+)
+
+// Outcomes of downloads recorded in Entry.Status
+const (
+	StatusComplete   = "complete"
+	StatusIncomplete = "incomplete" // Quit before the download finished
+	StatusCorrupted  = "corrupted"  // Still failed verification after re-downloading
+	StatusError      = "error"
+)
+
+// Entry records a finished download
+type Entry struct {
+	Time        time.Time   `json:"time"`
+	Link        string      `json:"link"`
+	RDID        string      `json:"rd_id,omitempty"`        // Real-Debrid unrestricted link ID
+	TorrentHash string      `json:"torrent_hash,omitempty"` // Info hash of torrent and magnet links
+	Filename    string      `json:"filename"`
+	Path        string      `json:"path,omitempty"`
+	Size        int64       `json:"size"`
+	GID         string      `json:"gid"`
+	Status      string      `json:"status"` // One of the Status constants
+	Error       string      `json:"error,omitempty"`
+	Checksum    *Checksum   `json:"checksum,omitempty"`
+	Media       *media.Info `json:"media,omitempty"` // Tracks of video downloads
+}
+
+// Checksum records the outcome of verifying a download
+type Checksum struct {
+	Algo   string `json:"algo"`
+	Source string `json:"source"`
+	OK     bool   `json:"ok"`
+}
+
+// Store is an append-only history file with one JSON entry per line
+type Store struct {
+	path string
+}
+
+// NewStore creates a store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the default history file location
+func DefaultPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// Append adds an entry to the history file
+func (s *Store) Append(entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+
+	return nil
+}
+
+// Load returns all entries, oldest first. A missing file yields no entries.
+func (s *Store) Load() ([]Entry, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode history entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,57 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/media"
+)
+
+func TestStore_AppendLoad(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", "history.jsonl"))
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() on missing file error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Load() on missing file = %v, want no entries", entries)
+	}
+
+	first := Entry{
+		Time:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Link:     "https://example.com/file.zip",
+		Filename: "file.zip",
+		Path:     "/tmp/file.zip",
+		Size:     1024,
+		GID:      "abc",
+		Status:   "complete",
+		Checksum: &Checksum{Algo: "sha256", Source: "flag", OK: true},
+		Media:    &media.Info{Duration: time.Hour, Width: 1920, Height: 1080, Quality: "1080p", Audio: []media.Track{{Lang: "eng", Codec: "aac", Channels: 2}}},
+	}
+	second := Entry{Link: "https://example.com/other.zip", Status: "error", Error: "boom"}
+
+	for _, entry := range []Entry{first, second} {
+		if err := store.Append(entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	entries, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Load() returned %d entries, want 2", len(entries))
+	}
+	if !entries[0].Time.Equal(first.Time) || entries[0].Checksum == nil || !entries[0].Checksum.OK {
+		t.Errorf("Load()[0] = %+v, want %+v", entries[0], first)
+	}
+	if entries[0].Media == nil || entries[0].Media.Quality != "1080p" || len(entries[0].Media.Audio) != 1 {
+		t.Errorf("Load()[0].Media = %+v, want %+v", entries[0].Media, first.Media)
+	}
+	if entries[1].Error != "boom" || entries[1].Checksum != nil || entries[1].Media != nil {
+		t.Errorf("Load()[1] = %+v, want %+v", entries[1], second)
+	}
+}
@@ -0,0 +1,287 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/config"
+)
+
+// Event names a point in a download's life that hooks can run on
+type Event string
+
+const (
+	EventStart    Event = "on_start"
+	EventComplete Event = "on_complete"
+	EventError    Event = "on_error"
+)
+
+// DefaultTimeout applies to hooks without a timeout
+const DefaultTimeout = 30 * time.Second
+
+// maxOutput caps the captured output of a hook
+const maxOutput = 64 * 1024
+
+// Vars are the variables available to hook templates, e.g. {{.Path}}.
+// Commands also receive them as VENAQUI_* environment variables, which
+// avoids quoting problems with unusual file names.
+type Vars struct {
+	Event    Event         `json:"event"`
+	Link     string        `json:"link"`
+	Filename string        `json:"filename"`
+	Path     string        `json:"path"`
+	Dir      string        `json:"dir"`
+	Size     int64         `json:"size"`
+	Host     string        `json:"host"`
+	GID      string        `json:"gid"`
+	Duration time.Duration `json:"-"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes the variables for webhooks, with the duration in seconds
+func (v Vars) MarshalJSON() ([]byte, error) {
+	type vars Vars
+	return json.Marshal(struct {
+		vars
+		DurationSeconds int64 `json:"duration_seconds"`
+	}{vars(v), int64(v.Duration.Seconds())})
+}
+
+// WithStatus fills in the variables aria2 reports for a download
+func (v Vars) WithStatus(status *aria2.DownloadStatus) Vars {
+	if status == nil {
+		return v
+	}
+	v.GID = status.GID
+	v.Path = status.GetFilePath()
+	v.Dir = status.GetFileDirectory()
+	v.Size = status.TotalLength
+	return v
+}
+
+// Result is the outcome of running a single hook
+type Result struct {
+	Event    Event
+	Name     string // Command or URL after templating
+	Output   string
+	Duration time.Duration
+	Err      error
+}
+
+// Runner runs the hooks configured for each event
+type Runner struct {
+	hooks      map[Event][]config.HookConfig
+	httpClient *http.Client
+}
+
+// NewRunner creates a runner for the configured hooks
+func NewRunner(cfg config.HooksConfig) *Runner {
+	return &Runner{
+		hooks: map[Event][]config.HookConfig{
+			EventStart:    cfg.OnStart,
+			EventComplete: cfg.OnComplete,
+			EventError:    cfg.OnError,
+		},
+		httpClient: &http.Client{},
+	}
+}
+
+// Has reports whether any hooks are configured for an event
+func (r *Runner) Has(event Event) bool {
+	return r != nil && len(r.hooks[event]) > 0
+}
+
+// Run runs every hook for an event in order and returns their results.
+// A failing hook does not stop the following ones.
+func (r *Runner) Run(ctx context.Context, event Event, vars Vars) []Result {
+	if r == nil {
+		return nil
+	}
+
+	vars.Event = event
+	var results []Result
+	for _, hook := range r.hooks[event] {
+		results = append(results, r.runHook(ctx, hook, vars))
+	}
+	return results
+}
+
+// runHook runs a single command or webhook with its timeout
+func (r *Runner) runHook(ctx context.Context, hook config.HookConfig, vars Vars) Result {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := Result{Event: vars.Event}
+
+	switch {
+	case hook.Command != "":
+		result.Name, result.Err = render("command", hook.Command, vars)
+		if result.Err == nil {
+			result.Output, result.Err = runCommand(ctx, result.Name, vars)
+		}
+	case hook.URL != "":
+		result.Name, result.Err = render("url", hook.URL, vars)
+		if result.Err == nil {
+			result.Output, result.Err = r.runWebhook(ctx, result.Name, hook, vars)
+		}
+	default:
+		result.Err = fmt.Errorf("hook has neither command nor url")
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// runCommand runs a command through the platform shell
+func runCommand(ctx context.Context, command string, vars Vars) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), vars.environ()...)
+	// Don't wait for grandchildren holding the output pipe after a timeout
+	cmd.WaitDelay = time.Second
+
+	var out limitedBuffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	return strings.TrimSpace(out.String()), err
+}
+
+// runWebhook sends an HTTP request. Without a body template, the variables
+// are sent as JSON.
+func (r *Runner) runWebhook(ctx context.Context, url string, hook config.HookConfig, vars Vars) (string, error) {
+	method := strings.ToUpper(hook.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	var body []byte
+	if hook.Body != "" {
+		rendered, err := render("body", hook.Body, vars)
+		if err != nil {
+			return "", err
+		}
+		body = []byte(rendered)
+	} else {
+		var err error
+		if body, err = json.Marshal(vars); err != nil {
+			return "", fmt.Errorf("failed to encode variables: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "venaqui")
+	for key, value := range hook.Headers {
+		rendered, err := render("header", value, vars)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set(key, rendered)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxOutput))
+	output := strings.TrimSpace(string(respBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return output, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return output, nil
+}
+
+// templateFuncs are available in hook templates
+var templateFuncs = template.FuncMap{
+	// quote shell-quotes a value: {{quote .Path}}
+	"quote": func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	},
+	// json encodes a value as a JSON string: {"text": {{json .Filename}}}
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"humanize": func(size int64) string {
+		return humanize.Bytes(uint64(size))
+	},
+}
+
+// render expands a hook template
+func render(name, text string, vars Vars) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// environ returns the variables as VENAQUI_* environment variables
+func (v Vars) environ() []string {
+	return []string{
+		"VENAQUI_EVENT=" + string(v.Event),
+		"VENAQUI_LINK=" + v.Link,
+		"VENAQUI_FILENAME=" + v.Filename,
+		"VENAQUI_PATH=" + v.Path,
+		"VENAQUI_DIR=" + v.Dir,
+		"VENAQUI_SIZE=" + strconv.FormatInt(v.Size, 10),
+		"VENAQUI_HOST=" + v.Host,
+		"VENAQUI_GID=" + v.GID,
+		"VENAQUI_DURATION=" + strconv.FormatInt(int64(v.Duration.Seconds()), 10),
+		"VENAQUI_ERROR=" + v.Error,
+	}
+}
+
+// limitedBuffer keeps at most maxOutput bytes of output
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+// Write implements io.Writer, silently dropping output past the limit
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := maxOutput - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
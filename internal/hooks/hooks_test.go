@@ -0,0 +1,157 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/config"
+)
+
+func testVars() Vars {
+	return Vars{
+		Link:     "https://example.com/file",
+		Filename: "it's a file.zip",
+		Path:     "/downloads/it's a file.zip",
+		Dir:      "/downloads",
+		Size:     2048,
+		Host:     "example.com",
+		GID:      "abc123",
+		Duration: 90 * time.Second,
+	}
+}
+
+func TestRunner_Command(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell syntax")
+	}
+
+	tests := []struct {
+		name       string
+		command    string
+		timeout    time.Duration
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name:       "template variables",
+			command:    `echo {{.GID}} {{.Size}} {{quote .Filename}}`,
+			wantOutput: "abc123 2048 it's a file.zip",
+		},
+		{
+			name:       "environment variables",
+			command:    `echo "$VENAQUI_EVENT $VENAQUI_HOST $VENAQUI_DURATION"`,
+			wantOutput: "on_complete example.com 90",
+		},
+		{
+			name:       "failing command keeps output",
+			command:    `echo oops; exit 3`,
+			wantOutput: "oops",
+			wantErr:    true,
+		},
+		{
+			name:    "timeout",
+			command: `sleep 5`,
+			timeout: 50 * time.Millisecond,
+			wantErr: true,
+		},
+		{
+			name:    "unknown variable",
+			command: `echo {{.Nope}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(config.HooksConfig{
+				OnComplete: []config.HookConfig{{Command: tt.command, Timeout: tt.timeout}},
+			})
+
+			results := runner.Run(context.Background(), EventComplete, testVars())
+			if len(results) != 1 {
+				t.Fatalf("Run() returned %d results, want 1", len(results))
+			}
+			if (results[0].Err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", results[0].Err, tt.wantErr)
+			}
+			if tt.wantOutput != "" && results[0].Output != tt.wantOutput {
+				t.Errorf("Run() output = %q, want %q", results[0].Output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestRunner_Webhook(t *testing.T) {
+	var gotBody []byte
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeader = r.Header.Get("X-Token")
+		if strings.Contains(r.URL.Path, "fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	t.Run("default JSON body", func(t *testing.T) {
+		runner := NewRunner(config.HooksConfig{
+			OnError: []config.HookConfig{{URL: server.URL + "/{{.GID}}", Headers: map[string]string{"X-Token": "secret"}}},
+		})
+
+		vars := testVars()
+		vars.Error = "boom"
+		results := runner.Run(context.Background(), EventError, vars)
+		if results[0].Err != nil {
+			t.Fatalf("Run() error = %v", results[0].Err)
+		}
+		if results[0].Name != server.URL+"/abc123" || results[0].Output != "ok" {
+			t.Errorf("Run() = %+v, want templated URL and output ok", results[0])
+		}
+
+		var got Vars
+		if err := json.Unmarshal(gotBody, &got); err != nil {
+			t.Fatalf("webhook body is not JSON: %v", err)
+		}
+		if got.Event != EventError || got.Error != "boom" || got.Path != vars.Path {
+			t.Errorf("webhook body = %+v, want event, error and path", got)
+		}
+		if gotHeader != "secret" {
+			t.Errorf("webhook header X-Token = %q, want secret", gotHeader)
+		}
+	})
+
+	t.Run("custom body and failing status", func(t *testing.T) {
+		runner := NewRunner(config.HooksConfig{
+			OnStart: []config.HookConfig{{URL: server.URL + "/fail", Body: `{"text": {{json .Filename}}}`}},
+		})
+
+		results := runner.Run(context.Background(), EventStart, testVars())
+		if results[0].Err == nil {
+			t.Error("Run() expected error for status 500")
+		}
+		if string(gotBody) != `{"text": "it's a file.zip"}` {
+			t.Errorf("webhook body = %s, want rendered template", gotBody)
+		}
+	})
+}
+
+func TestRunner_Has(t *testing.T) {
+	var nilRunner *Runner
+	if nilRunner.Has(EventStart) {
+		t.Error("nil Runner.Has() = true, want false")
+	}
+
+	runner := NewRunner(config.HooksConfig{OnStart: []config.HookConfig{{Command: "true"}}})
+	if !runner.Has(EventStart) || runner.Has(EventComplete) {
+		t.Error("Runner.Has() does not match configured events")
+	}
+}
@@ -0,0 +1,93 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// ErrNoProbe is returned by Probe when ffprobe is not installed
+var ErrNoProbe = errors.New("ffprobe not found")
+
+// probeTimeout bounds how long ffprobe may read a file
+const probeTimeout = 30 * time.Second
+
+// Probe reads the tracks of a local file with ffprobe
+func Probe(ctx context.Context, path string) (*Info, error) {
+	ffprobe, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return nil, ErrNoProbe
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, ffprobe,
+		"-v", "quiet",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		path,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	return parseProbe(out)
+}
+
+// probeOutput is the part of ffprobe's JSON output that is used
+type probeOutput struct {
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType   string `json:"codec_type"`
+		CodecName   string `json:"codec_name"`
+		Width       int    `json:"width"`
+		Height      int    `json:"height"`
+		Channels    int    `json:"channels"`
+		Disposition struct {
+			AttachedPic int `json:"attached_pic"`
+		} `json:"disposition"`
+		Tags struct {
+			Language string `json:"language"`
+		} `json:"tags"`
+	} `json:"streams"`
+}
+
+// parseProbe turns ffprobe's JSON output into an Info
+func parseProbe(data []byte) (*Info, error) {
+	var out probeOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	info := &Info{Source: "ffprobe"}
+	if seconds, err := strconv.ParseFloat(out.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	for _, stream := range out.Streams {
+		lang := stream.Tags.Language
+		if lang == "und" {
+			lang = ""
+		}
+		switch stream.CodecType {
+		case "video":
+			// Cover art is a video stream too
+			if stream.Disposition.AttachedPic == 1 || stream.Height <= info.Height {
+				continue
+			}
+			info.Width, info.Height = stream.Width, stream.Height
+			info.VideoCodec = stream.CodecName
+		case "audio":
+			info.Audio = append(info.Audio, Track{Lang: lang, Codec: stream.CodecName, Channels: stream.Channels})
+		case "subtitle":
+			info.Subtitles = append(info.Subtitles, Track{Lang: lang, Codec: stream.CodecName})
+		}
+	}
+	info.Quality = QualityOf(info.Width, info.Height)
+	return info, nil
+}
@@ -0,0 +1,146 @@
+package media
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Info describes the tracks of a video file
+type Info struct {
+	Duration   time.Duration `json:"duration"`
+	Width      int           `json:"width,omitempty"`
+	Height     int           `json:"height,omitempty"`
+	Quality    string        `json:"quality,omitempty"` // Such as 1080p, for searching the history
+	VideoCodec string        `json:"video_codec,omitempty"`
+	Audio      []Track       `json:"audio,omitempty"`
+	Subtitles  []Track       `json:"subtitles,omitempty"`
+	Source     string        `json:"source"` // The provider's name or ffprobe
+}
+
+// Track is an audio or subtitle track
+type Track struct {
+	Lang     string `json:"lang,omitempty"`
+	Codec    string `json:"codec,omitempty"`
+	Channels int    `json:"channels,omitempty"` // Audio only
+}
+
+// videoExtensions are the video files whose MIME type may not be known
+var videoExtensions = map[string]bool{
+	".mkv": true, ".mp4": true, ".m4v": true, ".avi": true, ".mov": true,
+	".wmv": true, ".webm": true, ".ts": true, ".m2ts": true, ".flv": true,
+}
+
+// IsVideo reports whether a file is a video, by its MIME type or, when that
+// is missing or generic, its extension
+func IsVideo(mimeType, filename string) bool {
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil && strings.HasPrefix(mediaType, "video/") {
+		return true
+	}
+	return videoExtensions[strings.ToLower(filepath.Ext(filename))]
+}
+
+// QualityOf names a resolution the way releases do, such as 720p or 2160p.
+// Widescreen files are named by their width, so a 1920x800 file is 1080p.
+func QualityOf(width, height int) string {
+	for _, q := range []struct {
+		width, height int
+		name          string
+	}{
+		{3840, 2160, "2160p"},
+		{2560, 1440, "1440p"},
+		{1920, 1080, "1080p"},
+		{1280, 720, "720p"},
+		{1024, 576, "576p"},
+		{640, 480, "480p"},
+	} {
+		// Allow some cropping
+		if width >= q.width*9/10 || height >= q.height*9/10 {
+			return q.name
+		}
+	}
+	if height > 0 {
+		return fmt.Sprintf("%dp", height)
+	}
+	return ""
+}
+
+// Resolution returns the size of the video, such as 1920x1080, or an empty
+// string when it is unknown
+func (i *Info) Resolution() string {
+	if i.Width == 0 || i.Height == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", i.Width, i.Height)
+}
+
+// Summary describes the video on one line, such as
+// "1h32m, 1920x1080 (1080p), h264"
+func (i *Info) Summary() string {
+	var parts []string
+	if i.Duration > 0 {
+		parts = append(parts, formatDuration(i.Duration))
+	}
+	if resolution := i.Resolution(); resolution != "" {
+		if i.Quality != "" {
+			resolution += " (" + i.Quality + ")"
+		}
+		parts = append(parts, resolution)
+	}
+	if i.VideoCodec != "" {
+		parts = append(parts, i.VideoCodec)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// AudioSummary lists the audio tracks, such as "eng aac 5.1, jpn aac 2.0"
+func (i *Info) AudioSummary() string {
+	var tracks []string
+	for _, track := range i.Audio {
+		tracks = append(tracks, track.describe())
+	}
+	return strings.Join(tracks, ", ")
+}
+
+// SubtitleSummary lists the subtitle tracks, such as "eng subrip, fre ass"
+func (i *Info) SubtitleSummary() string {
+	var tracks []string
+	for _, track := range i.Subtitles {
+		tracks = append(tracks, track.describe())
+	}
+	return strings.Join(tracks, ", ")
+}
+
+// describe names a track by its language, codec and channel layout
+func (t Track) describe() string {
+	var parts []string
+	if t.Lang != "" {
+		parts = append(parts, t.Lang)
+	}
+	if t.Codec != "" {
+		parts = append(parts, t.Codec)
+	}
+	switch {
+	case t.Channels == 1:
+		parts = append(parts, "mono")
+	case t.Channels == 2:
+		parts = append(parts, "2.0")
+	case t.Channels > 2:
+		parts = append(parts, fmt.Sprintf("%d.1", t.Channels-1))
+	}
+	if len(parts) == 0 {
+		return "unknown"
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatDuration formats a duration as 1h32m or 45m10s
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
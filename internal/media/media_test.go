@@ -0,0 +1,83 @@
+package media
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsVideo(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		filename string
+		want     bool
+	}{
+		{"video/x-matroska", "movie.mkv", true},
+		{"video/mp4; charset=binary", "clip", true},
+		{"application/octet-stream", "Show.S01E01.mkv", true},
+		{"", "episode.MP4", true},
+		{"application/zip", "archive.zip", false},
+		{"audio/mpeg", "song.mp3", false},
+	}
+	for _, tt := range tests {
+		if got := IsVideo(tt.mimeType, tt.filename); got != tt.want {
+			t.Errorf("IsVideo(%q, %q) = %v, want %v", tt.mimeType, tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestQualityOf(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          string
+	}{
+		{3840, 2160, "2160p"},
+		{1920, 1080, "1080p"},
+		{1920, 800, "1080p"},
+		{1280, 720, "720p"},
+		{1280, 536, "720p"},
+		{720, 576, "576p"},
+		{720, 480, "480p"},
+		{640, 360, "480p"},
+		{320, 240, "240p"},
+		{0, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := QualityOf(tt.width, tt.height); got != tt.want {
+			t.Errorf("QualityOf(%d, %d) = %q, want %q", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestParseProbe(t *testing.T) {
+	output := `{
+		"streams": [
+			{"codec_type": "video", "codec_name": "hevc", "width": 1920, "height": 1080},
+			{"codec_type": "audio", "codec_name": "eac3", "channels": 6, "tags": {"language": "eng"}},
+			{"codec_type": "audio", "codec_name": "aac", "channels": 2, "tags": {"language": "und"}},
+			{"codec_type": "subtitle", "codec_name": "subrip", "tags": {"language": "fre"}},
+			{"codec_type": "video", "codec_name": "mjpeg", "width": 3000, "height": 3000, "disposition": {"attached_pic": 1}}
+		],
+		"format": {"duration": "5530.250000"}
+	}`
+
+	info, err := parseProbe([]byte(output))
+	if err != nil {
+		t.Fatalf("parseProbe() error = %v", err)
+	}
+	if got, want := info.Summary(), "1h32m, 1920x1080 (1080p), hevc"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	if info.Duration != 5530250*time.Millisecond {
+		t.Errorf("Duration = %v, want 1h32m10.25s", info.Duration)
+	}
+	if got, want := info.AudioSummary(), "eng eac3 5.1, aac 2.0"; got != want {
+		t.Errorf("AudioSummary() = %q, want %q", got, want)
+	}
+	if got, want := info.SubtitleSummary(), "fre subrip"; got != want {
+		t.Errorf("SubtitleSummary() = %q, want %q", got, want)
+	}
+
+	if _, err := parseProbe([]byte("not json")); err == nil {
+		t.Error("parseProbe() error = nil, want an error")
+	}
+}
@@ -0,0 +1,207 @@
+// Package notify shows desktop notifications for download events, falling
+// back to the terminal bell when no desktop backend is available.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/mhrsntrk/venaqui/internal/config"
+)
+
+// Event is a download event that can trigger a notification
+type Event int
+
+const (
+	EventComplete Event = iota
+	EventError
+	EventRetryExhausted
+)
+
+// notifyTimeout bounds how long a notification backend may take
+const notifyTimeout = 5 * time.Second
+
+// Notification is a message shown outside the terminal
+type Notification struct {
+	Title  string
+	Body   string
+	Urgent bool
+}
+
+// Notifier is a backend that shows desktop notifications
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// Notifications sends notifications for the events enabled in the config,
+// ringing the terminal bell when the desktop backend fails
+type Notifications struct {
+	backend Notifier
+	bell    Notifier // Nil disables the fallback
+	events  map[Event]bool
+}
+
+// New creates notifications using the platform's backend, or nil if
+// notifications are disabled
+func New(cfg config.NotificationsConfig) *Notifications {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var bell Notifier
+	if cfg.Bell {
+		bell = NewBellNotifier(os.Stderr)
+	}
+	return NewWithBackend(cfg, platformNotifier(), bell)
+}
+
+// NewWithBackend creates notifications using the given backend and bell
+// fallback, which may be nil
+func NewWithBackend(cfg config.NotificationsConfig, backend, bell Notifier) *Notifications {
+	return &Notifications{
+		backend: backend,
+		bell:    bell,
+		events: map[Event]bool{
+			EventComplete:       cfg.OnComplete,
+			EventError:          cfg.OnError,
+			EventRetryExhausted: cfg.OnRetryExhausted,
+		},
+	}
+}
+
+// Send shows a notification if the event is enabled. If the desktop backend
+// fails, the bell is rung instead and the backend's error is returned.
+func (n *Notifications) Send(event Event, notification Notification) error {
+	if n == nil || !n.events[event] {
+		return nil
+	}
+
+	err := n.backend.Notify(notification)
+	if err != nil && n.bell != nil {
+		n.bell.Notify(notification)
+	}
+	return err
+}
+
+// SendEvent shows a notification for an event if it is enabled. Every event
+// but a completed download is urgent.
+func (n *Notifications) SendEvent(event Event, title, body string) error {
+	return n.Send(event, Notification{
+		Title:  title,
+		Body:   body,
+		Urgent: event != EventComplete,
+	})
+}
+
+// platformNotifier returns the desktop notification backend for this OS
+func platformNotifier() Notifier {
+	switch runtime.GOOS {
+	case "darwin":
+		return macNotifier{}
+	case "windows":
+		return windowsNotifier{}
+	default:
+		// Linux and the BSDs implement the freedesktop specification
+		return NewDBusNotifier(nil)
+	}
+}
+
+// DBusNotifier sends freedesktop notifications over D-Bus
+type DBusNotifier struct {
+	conn *dbus.Conn
+}
+
+// NewDBusNotifier creates a notifier using conn, or the session bus if conn is nil
+func NewDBusNotifier(conn *dbus.Conn) *DBusNotifier {
+	return &DBusNotifier{conn: conn}
+}
+
+// Notify implements Notifier
+func (d *DBusNotifier) Notify(n Notification) error {
+	conn := d.conn
+	if conn == nil {
+		var err error
+		conn, err = dbus.SessionBus()
+		if err != nil {
+			return fmt.Errorf("failed to connect to session bus: %w", err)
+		}
+	}
+
+	// Urgency levels are 0 (low), 1 (normal) and 2 (critical)
+	urgency := byte(1)
+	if n.Urgent {
+		urgency = 2
+	}
+	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(urgency)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.CallWithContext(ctx, "org.freedesktop.Notifications.Notify", 0,
+		"venaqui", uint32(0), "", n.Title, n.Body, []string{}, hints, int32(-1))
+	if call.Err != nil {
+		return fmt.Errorf("failed to send notification: %w", call.Err)
+	}
+	return nil
+}
+
+// macNotifier shows notifications through AppleScript
+type macNotifier struct{}
+
+// Notify implements Notifier
+func (macNotifier) Notify(n Notification) error {
+	// Pass the text as arguments so it needs no AppleScript escaping
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		n.Title, n.Body)
+	return cmd.Run()
+}
+
+// windowsToastScript shows a toast using the WinRT API available to PowerShell
+const windowsToastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:VENAQUI_TITLE)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:VENAQUI_BODY)) > $null
+$appID = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($appID).Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+
+// windowsNotifier shows toast notifications through PowerShell
+type windowsNotifier struct{}
+
+// Notify implements Notifier
+func (windowsNotifier) Notify(n Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript)
+	// The text is passed through the environment so it needs no escaping
+	cmd.Env = append(os.Environ(), "VENAQUI_TITLE="+n.Title, "VENAQUI_BODY="+n.Body)
+	return cmd.Run()
+}
+
+// BellNotifier rings the terminal bell
+type BellNotifier struct {
+	w io.Writer
+}
+
+// NewBellNotifier creates a notifier that writes the bell character to w
+func NewBellNotifier(w io.Writer) *BellNotifier {
+	return &BellNotifier{w: w}
+}
+
+// Notify implements Notifier
+func (b *BellNotifier) Notify(Notification) error {
+	_, err := io.WriteString(b.w, "\a")
+	return err
+}
@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/mhrsntrk/venaqui/internal/config"
+)
+
+// fakeBusConfig configures a private dbus-daemon for tests
+const fakeBusConfig = `<busconfig>
+  <type>session</type>
+  <listen>unix:dir=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>`
+
+// startFakeSessionBus starts a private dbus-daemon and returns its address
+func startFakeSessionBus(t *testing.T) string {
+	t.Helper()
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(configFile, []byte(strings.Replace(fakeBusConfig, "%s", dir, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+configFile, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+// fakeNotificationServer records notifications like a desktop notification daemon
+type fakeNotificationServer struct {
+	received chan Notification
+	urgency  chan byte
+}
+
+// Notify implements org.freedesktop.Notifications.Notify
+func (s *fakeNotificationServer) Notify(appName string, replacesID uint32, icon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	s.received <- Notification{Title: summary, Body: body}
+	urgency, _ := hints["urgency"].Value().(byte)
+	s.urgency <- urgency
+	return 1, nil
+}
+
+func TestDBusNotifier(t *testing.T) {
+	address := startFakeSessionBus(t)
+
+	server, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("server failed to connect: %v", err)
+	}
+	defer server.Close()
+
+	fake := &fakeNotificationServer{received: make(chan Notification, 1), urgency: make(chan byte, 1)}
+	if err := server.Export(fake, "/org/freedesktop/Notifications", "org.freedesktop.Notifications"); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := server.RequestName("org.freedesktop.Notifications", dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("failed to own name: %v (reply %v)", err, reply)
+	}
+
+	client, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("client failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	want := Notification{Title: "Download failed", Body: "movie.mkv: aria2 error"}
+	if err := NewDBusNotifier(client).Notify(Notification{Title: want.Title, Body: want.Body, Urgent: true}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got := <-fake.received; got != want {
+		t.Errorf("received %+v, want %+v", got, want)
+	}
+	if got := <-fake.urgency; got != 2 {
+		t.Errorf("urgency = %d, want 2 (critical)", got)
+	}
+
+	// Without a notification daemon on the bus, the call fails
+	server.ReleaseName("org.freedesktop.Notifications")
+	if err := NewDBusNotifier(client).Notify(want); err == nil {
+		t.Error("Notify() expected error without a notification daemon")
+	}
+}
+
+// recordingNotifier records notifications and optionally fails
+type recordingNotifier struct {
+	sent []Notification
+	err  error
+}
+
+func (r *recordingNotifier) Notify(n Notification) error {
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+func TestNotifications_Send(t *testing.T) {
+	cfg := config.NotificationsConfig{Enabled: true, OnComplete: true, OnError: false, OnRetryExhausted: true}
+
+	tests := []struct {
+		name        string
+		event       Event
+		backendErr  error
+		wantBackend int
+		wantBell    string
+		wantErr     bool
+	}{
+		{name: "enabled event", event: EventComplete, wantBackend: 1},
+		{name: "disabled event", event: EventError},
+		{name: "bell fallback", event: EventRetryExhausted, backendErr: errors.New("no bus"), wantBackend: 1, wantBell: "\a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &recordingNotifier{err: tt.backendErr}
+			var bell bytes.Buffer
+			n := NewWithBackend(cfg, backend, NewBellNotifier(&bell))
+
+			err := n.Send(tt.event, Notification{Title: "t", Body: "b"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(backend.sent) != tt.wantBackend {
+				t.Errorf("backend received %d notifications, want %d", len(backend.sent), tt.wantBackend)
+			}
+			if bell.String() != tt.wantBell {
+				t.Errorf("bell = %q, want %q", bell.String(), tt.wantBell)
+			}
+		})
+	}
+
+	if New(config.NotificationsConfig{}) != nil {
+		t.Error("New() should return nil when disabled")
+	}
+	var disabled *Notifications
+	if err := disabled.Send(EventComplete, Notification{}); err != nil {
+		t.Errorf("nil Notifications.Send() error = %v", err)
+	}
+}
+
+func TestNotifications_SendEvent(t *testing.T) {
+	cfg := config.NotificationsConfig{Enabled: true, OnComplete: true, OnError: true, OnRetryExhausted: true}
+
+	tests := []struct {
+		event      Event
+		wantUrgent bool
+	}{
+		{event: EventComplete, wantUrgent: false},
+		{event: EventError, wantUrgent: true},
+		{event: EventRetryExhausted, wantUrgent: true},
+	}
+
+	for _, tt := range tests {
+		backend := &recordingNotifier{}
+		if err := NewWithBackend(cfg, backend, nil).SendEvent(tt.event, "t", "b"); err != nil {
+			t.Fatalf("SendEvent() error = %v", err)
+		}
+		if len(backend.sent) != 1 {
+			t.Fatalf("backend received %d notifications, want 1", len(backend.sent))
+		}
+		if got := backend.sent[0]; got.Title != "t" || got.Body != "b" || got.Urgent != tt.wantUrgent {
+			t.Errorf("SendEvent(%d) sent %+v, want urgent %v", tt.event, got, tt.wantUrgent)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package organize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mhrsntrk/venaqui/internal/config"
+)
+
+// DefaultTemplate keeps the file name chosen by the host
+const DefaultTemplate = "{filename}"
+
+// ErrExists is returned when the target file exists and the policy is skip
+var ErrExists = errors.New("file already exists")
+
+// Policy decides what happens when the target file already exists
+type Policy string
+
+const (
+	PolicySkip      Policy = "skip"
+	PolicyRename    Policy = "rename"
+	PolicyOverwrite Policy = "overwrite"
+)
+
+// Input describes a download before it starts
+type Input struct {
+	Filename string
+	Host     string
+	MimeType string
+	Size     int64 // 0 if unknown
+	Time     time.Time
+}
+
+// Placement is where a download should be written
+type Placement struct {
+	Dir       string // Absolute directory
+	Out       string // File name within Dir
+	Rule      string // Name of the matching rule, empty for the default template
+	Overwrite bool
+}
+
+// Path returns the full path of the placed file
+func (p *Placement) Path() string {
+	return filepath.Join(p.Dir, p.Out)
+}
+
+// rule is a compiled category rule
+type rule struct {
+	name     string
+	exts     []string
+	mimes    []string
+	hosts    []string
+	regex    *regexp.Regexp
+	minSize  uint64
+	maxSize  uint64
+	template string
+}
+
+// Organizer chooses a directory and file name for downloads
+type Organizer struct {
+	template string
+	policy   Policy
+	rules    []rule
+}
+
+// placeholderPattern matches {name} placeholders in templates
+var placeholderPattern = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// placeholders lists the values templates can use
+var placeholders = map[string]bool{
+	"filename": true, "name": true, "ext": true, "host": true,
+	"rule": true, "yyyy": true, "mm": true, "dd": true,
+}
+
+// New compiles the organize configuration
+func New(cfg config.OrganizeConfig) (*Organizer, error) {
+	o := &Organizer{
+		template: cfg.Template,
+		policy:   Policy(strings.ToLower(cfg.OnConflict)),
+	}
+	if o.template == "" {
+		o.template = DefaultTemplate
+	}
+	if o.policy == "" {
+		o.policy = PolicyRename
+	}
+
+	switch o.policy {
+	case PolicySkip, PolicyRename, PolicyOverwrite:
+	default:
+		return nil, fmt.Errorf("invalid on_conflict %q: must be skip, rename or overwrite", cfg.OnConflict)
+	}
+	if err := checkTemplate(o.template); err != nil {
+		return nil, err
+	}
+
+	for i, rc := range cfg.Rules {
+		r, err := compileRule(rc)
+		if err != nil {
+			name := rc.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return nil, fmt.Errorf("invalid rule %s: %w", name, err)
+		}
+		o.rules = append(o.rules, r)
+	}
+
+	return o, nil
+}
+
+// compileRule validates a rule and parses its sizes and regex
+func compileRule(rc config.RuleConfig) (rule, error) {
+	r := rule{
+		name:     rc.Name,
+		template: rc.Template,
+		hosts:    lower(rc.Host),
+		mimes:    lower(rc.Mime),
+	}
+	for _, ext := range rc.Ext {
+		r.exts = append(r.exts, strings.ToLower(strings.TrimPrefix(ext, ".")))
+	}
+
+	if r.template == "" {
+		return r, fmt.Errorf("template is required")
+	}
+	if err := checkTemplate(r.template); err != nil {
+		return r, err
+	}
+	if rc.Regex != "" {
+		re, err := regexp.Compile(rc.Regex)
+		if err != nil {
+			return r, fmt.Errorf("invalid regex: %w", err)
+		}
+		r.regex = re
+	}
+	if rc.MinSize != "" {
+		size, err := humanize.ParseBytes(rc.MinSize)
+		if err != nil {
+			return r, fmt.Errorf("invalid min_size: %w", err)
+		}
+		r.minSize = size
+	}
+	if rc.MaxSize != "" {
+		size, err := humanize.ParseBytes(rc.MaxSize)
+		if err != nil {
+			return r, fmt.Errorf("invalid max_size: %w", err)
+		}
+		r.maxSize = size
+	}
+
+	return r, nil
+}
+
+// checkTemplate rejects unknown placeholders
+func checkTemplate(tmpl string) error {
+	for _, m := range placeholderPattern.FindAllStringSubmatch(tmpl, -1) {
+		if !placeholders[m[1]] {
+			return fmt.Errorf("unknown placeholder {%s} in template %q", m[1], tmpl)
+		}
+	}
+	return nil
+}
+
+// matches reports whether every condition set on the rule holds for in
+func (r rule) matches(in Input) bool {
+	if len(r.exts) > 0 && !contains(r.exts, extension(in.Filename)) {
+		return false
+	}
+	if len(r.mimes) > 0 && !matchMime(r.mimes, strings.ToLower(in.MimeType)) {
+		return false
+	}
+	if len(r.hosts) > 0 && !matchHost(r.hosts, strings.ToLower(in.Host)) {
+		return false
+	}
+	if r.regex != nil && !r.regex.MatchString(in.Filename) {
+		return false
+	}
+	// Size rules never match downloads of unknown size
+	if r.minSize > 0 && (in.Size <= 0 || uint64(in.Size) < r.minSize) {
+		return false
+	}
+	if r.maxSize > 0 && (in.Size <= 0 || uint64(in.Size) > r.maxSize) {
+		return false
+	}
+	return true
+}
+
+// Place chooses the directory and file name for a download inside baseDir
+// and resolves conflicts with existing files
+func (o *Organizer) Place(baseDir string, in Input) (*Placement, error) {
+	p, err := o.Target(baseDir, in)
+	if err != nil {
+		return nil, err
+	}
+	return p, o.Resolve(p)
+}
+
+// Target chooses the directory and file name for a download inside baseDir,
+// ignoring existing files. The first matching rule wins; otherwise the
+// default template is used.
+func (o *Organizer) Target(baseDir string, in Input) (*Placement, error) {
+	tmpl, ruleName := o.template, ""
+	for _, r := range o.rules {
+		if r.matches(in) {
+			tmpl, ruleName = r.template, r.name
+			break
+		}
+	}
+
+	rel, err := expand(tmpl, in, ruleName)
+	if err != nil {
+		return nil, err
+	}
+
+	full := filepath.Join(baseDir, rel)
+	return &Placement{Dir: filepath.Dir(full), Out: filepath.Base(full), Rule: ruleName}, nil
+}
+
+// Resolve applies the conflict policy if the placed file exists: skip
+// returns ErrExists, rename picks a free name and overwrite sets Overwrite
+func (o *Organizer) Resolve(p *Placement) error {
+	if _, err := os.Stat(p.Path()); err != nil {
+		return nil
+	}
+
+	switch o.policy {
+	case PolicySkip:
+		return fmt.Errorf("%w: %s", ErrExists, p.Path())
+	case PolicyOverwrite:
+		p.Overwrite = true
+	case PolicyRename:
+		p.Out = freeName(p.Dir, p.Out)
+	}
+	return nil
+}
+
+// expand fills in a template and returns a clean relative path
+func expand(tmpl string, in Input, ruleName string) (string, error) {
+	t := in.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	ext := extension(in.Filename)
+	values := map[string]string{
+		"filename": in.Filename,
+		"name":     strings.TrimSuffix(in.Filename, filepath.Ext(in.Filename)),
+		"ext":      ext,
+		"host":     in.Host,
+		"rule":     ruleName,
+		"yyyy":     t.Format("2006"),
+		"mm":       t.Format("01"),
+		"dd":       t.Format("02"),
+	}
+
+	expanded := placeholderPattern.ReplaceAllStringFunc(tmpl, func(m string) string {
+		value := values[m[1:len(m)-1]]
+		if value == "" {
+			value = "unknown"
+		}
+		// Values must not introduce directories of their own
+		return sanitize(value)
+	})
+
+	rel := path.Clean(filepath.ToSlash(expanded))
+	if path.IsAbs(rel) || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("template %q must produce a path inside the download directory, got %q", tmpl, expanded)
+	}
+	return filepath.FromSlash(rel), nil
+}
+
+// freeName returns name, or "name (n).ext" with the lowest n that does not exist in dir
+func freeName(dir, name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if _, err := os.Stat(filepath.Join(dir, candidate)); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// sanitize replaces path separators and characters most file systems reject
+func sanitize(value string) string {
+	value = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, value)
+	if value == "." || value == ".." {
+		return "_"
+	}
+	return value
+}
+
+// extension returns the lower-case extension of a file name without the dot
+func extension(filename string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// matchMime matches a MIME type against patterns such as video/*
+func matchMime(patterns []string, mime string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, mime); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHost matches a host or any of its subdomains
+func matchHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func lower(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToLower(v)
+	}
+	return out
+}
@@ -0,0 +1,168 @@
+package organize
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/config"
+)
+
+func testConfig() config.OrganizeConfig {
+	return config.OrganizeConfig{
+		Template: "{filename}",
+		Rules: []config.RuleConfig{
+			{Name: "shows", Regex: `(?i)S\d{2}E\d{2}`, Template: "TV/{name}.{ext}"},
+			{Name: "video", Mime: []string{"video/*"}, MinSize: "100MB", Template: "Movies/{yyyy}-{mm}/{filename}"},
+			{Name: "1fichier", Host: []string{"1fichier.com"}, Template: "{host}/{filename}"},
+			{Name: "software", Ext: []string{".ISO", "dmg"}, MaxSize: "10GB", Template: "Software/{filename}"},
+		},
+	}
+}
+
+func TestOrganizer_Place(t *testing.T) {
+	when := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		in       Input
+		wantPath string
+		wantRule string
+	}{
+		{
+			name:     "regex",
+			in:       Input{Filename: "Show.S01E02.mkv", MimeType: "video/x-matroska", Size: 1 << 30},
+			wantPath: "TV/Show.S01E02.mkv",
+			wantRule: "shows",
+		},
+		{
+			name:     "mime and size",
+			in:       Input{Filename: "movie.mkv", MimeType: "video/x-matroska", Size: 1 << 30, Time: when},
+			wantPath: "Movies/2026-03/movie.mkv",
+			wantRule: "video",
+		},
+		{
+			name:     "small video falls through to host",
+			in:       Input{Filename: "clip.mp4", MimeType: "video/mp4", Size: 1 << 20, Host: "cdn.1fichier.com"},
+			wantPath: "cdn.1fichier.com/clip.mp4",
+			wantRule: "1fichier",
+		},
+		{
+			name:     "extension",
+			in:       Input{Filename: "distro.iso", Size: 1 << 30},
+			wantPath: "Software/distro.iso",
+			wantRule: "software",
+		},
+		{
+			name:     "unknown size never matches size rules",
+			in:       Input{Filename: "distro.iso"},
+			wantPath: "distro.iso",
+		},
+		{
+			name:     "default template",
+			in:       Input{Filename: "notes.txt"},
+			wantPath: "notes.txt",
+		},
+	}
+
+	o, err := New(testConfig())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			p, err := o.Place(base, tt.in)
+			if err != nil {
+				t.Fatalf("Place() error = %v", err)
+			}
+			if want := filepath.Join(base, filepath.FromSlash(tt.wantPath)); p.Path() != want {
+				t.Errorf("Place() path = %v, want %v", p.Path(), want)
+			}
+			if p.Rule != tt.wantRule {
+				t.Errorf("Place() rule = %q, want %q", p.Rule, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestOrganizer_PlaceSanitizes(t *testing.T) {
+	o, err := New(config.OrganizeConfig{Template: "{host}/{filename}"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	base := t.TempDir()
+	p, err := o.Place(base, Input{Filename: "../../etc/passwd"})
+	if err != nil {
+		t.Fatalf("Place() error = %v", err)
+	}
+	if want := filepath.Join(base, "unknown", ".._.._etc_passwd"); p.Path() != want {
+		t.Errorf("Place() path = %v, want %v", p.Path(), want)
+	}
+
+	o, _ = New(config.OrganizeConfig{Template: "../{filename}"})
+	if _, err := o.Place(base, Input{Filename: "a.txt"}); err == nil {
+		t.Error("Place() expected error for a template leaving the download directory")
+	}
+}
+
+func TestOrganizer_Conflicts(t *testing.T) {
+	tests := []struct {
+		policy        string
+		wantOut       string
+		wantOverwrite bool
+		wantErr       error
+	}{
+		{policy: "rename", wantOut: "file (2).txt"},
+		{policy: "overwrite", wantOut: "file.txt", wantOverwrite: true},
+		{policy: "skip", wantOut: "file.txt", wantErr: ErrExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			base := t.TempDir()
+			for _, name := range []string{"file.txt", "file (1).txt"} {
+				if err := os.WriteFile(filepath.Join(base, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			o, err := New(config.OrganizeConfig{OnConflict: tt.policy})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			p, err := o.Place(base, Input{Filename: "file.txt"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Place() error = %v, want %v", err, tt.wantErr)
+			}
+			if p.Out != tt.wantOut || p.Overwrite != tt.wantOverwrite {
+				t.Errorf("Place() = %+v, want out %q overwrite %v", p, tt.wantOut, tt.wantOverwrite)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.OrganizeConfig
+	}{
+		{name: "unknown placeholder", cfg: config.OrganizeConfig{Template: "{year}/{filename}"}},
+		{name: "unknown policy", cfg: config.OrganizeConfig{OnConflict: "merge"}},
+		{name: "bad regex", cfg: config.OrganizeConfig{Rules: []config.RuleConfig{{Regex: "(", Template: "{filename}"}}}},
+		{name: "bad size", cfg: config.OrganizeConfig{Rules: []config.RuleConfig{{MinSize: "lots", Template: "{filename}"}}}},
+		{name: "missing template", cfg: config.OrganizeConfig{Rules: []config.RuleConfig{{Ext: []string{"iso"}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("New() expected error")
+			}
+		})
+	}
+}
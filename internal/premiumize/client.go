@@ -0,0 +1,323 @@
+// Package premiumize is a client for the Premiumize API, implementing
+// debrid.Provider
+package premiumize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+const BaseURL = "https://www.premiumize.me/api"
+
+// pollInterval is how often transfers are checked
+const pollInterval = 2 * time.Second
+
+// Client handles communication with the Premiumize API
+type Client struct {
+	apiToken   string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Client is a debrid.Provider
+var _ debrid.Provider = (*Client)(nil)
+
+// NewClient creates a new Premiumize API client
+func NewClient(apiToken string) *Client {
+	return NewClientWithBaseURL(apiToken, BaseURL)
+}
+
+// NewClientWithBaseURL creates a new Premiumize API client with a custom base URL (for testing)
+func NewClientWithBaseURL(apiToken, baseURL string) *Client {
+	return &Client{
+		apiToken: apiToken,
+		baseURL:  baseURL,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// status is the part every Premiumize response has
+type status struct {
+	Status  string `json:"status"` // success or error
+	Message string `json:"message"`
+}
+
+// do sends a request and decodes the response into result
+func (c *Client) do(req *http.Request, result interface{}) error {
+	query := req.URL.Query()
+	query.Set("apikey", c.apiToken)
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var s status
+	if err := json.Unmarshal(body, &s); err != nil || s.Status != "success" {
+		return &APIError{StatusCode: resp.StatusCode, Message: s.Message}
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// get calls an endpoint with query parameters
+func (c *Client) get(endpoint string, params url.Values, result interface{}) error {
+	req, err := http.NewRequest("GET", c.baseURL+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return c.do(req, result)
+}
+
+// post calls an endpoint with form data
+func (c *Client) post(endpoint string, form url.Values, result interface{}) error {
+	req, err := http.NewRequest("POST", c.baseURL+endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req, result)
+}
+
+// Name implements debrid.Provider
+func (c *Client) Name() string {
+	return "Premiumize"
+}
+
+// ValidateToken implements debrid.Provider
+func (c *Client) ValidateToken() error {
+	_, err := c.GetAccount()
+	return err
+}
+
+// GetAccount implements debrid.Provider
+func (c *Client) GetAccount() (*debrid.Account, error) {
+	var info struct {
+		CustomerID   json.RawMessage `json:"customer_id"`
+		PremiumUntil json.RawMessage `json:"premium_until"` // Unix time, or false without premium
+	}
+	if err := c.get("/account/info", url.Values{}, &info); err != nil {
+		return nil, err
+	}
+
+	account := &debrid.Account{Username: strings.Trim(string(info.CustomerID), `"`)}
+	if until, err := strconv.ParseInt(string(info.PremiumUntil), 10, 64); err == nil && until > 0 {
+		account.Expiration = time.Unix(until, 0)
+		account.Premium = account.Expiration.After(time.Now())
+	}
+	return account, nil
+}
+
+// contentItem is a file returned by /transfer/directdl
+type contentItem struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Link string `json:"link"`
+}
+
+// Unrestrict implements debrid.Unrestrictor
+func (c *Client) Unrestrict(link string) (*debrid.Link, error) {
+	var result struct {
+		Location string        `json:"location"`
+		Filename string        `json:"filename"`
+		Filesize int64         `json:"filesize"`
+		Content  []contentItem `json:"content"`
+	}
+	if err := c.post("/transfer/directdl", url.Values{"src": {link}}, &result); err != nil {
+		return nil, err
+	}
+
+	unrestricted := &debrid.Link{
+		Filename: result.Filename,
+		Filesize: result.Filesize,
+		Download: result.Location,
+	}
+	if u, err := url.Parse(link); err == nil {
+		unrestricted.Host = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	if len(result.Content) > 0 {
+		item := result.Content[0]
+		unrestricted.Download, unrestricted.Filesize = item.Link, item.Size
+		if unrestricted.Filename == "" {
+			unrestricted.Filename = path.Base(item.Path)
+		}
+	}
+	if unrestricted.Download == "" {
+		return nil, fmt.Errorf("Premiumize returned no download link")
+	}
+	return unrestricted, nil
+}
+
+// GetHostRegexes implements debrid.Unrestrictor
+func (c *Client) GetHostRegexes() ([]string, error) {
+	var result struct {
+		RegexPatterns map[string][]string `json:"regexpatterns"`
+	}
+	if err := c.get("/services/list", url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	var regexes []string
+	for _, patterns := range result.RegexPatterns {
+		regexes = append(regexes, patterns...)
+	}
+	return regexes, nil
+}
+
+// createdTransfer is the response of /transfer/create
+type createdTransfer struct {
+	ID string `json:"id"`
+}
+
+// SubmitMagnet implements debrid.TorrentProvider
+func (c *Client) SubmitMagnet(magnet string) (string, error) {
+	var created createdTransfer
+	if err := c.post("/transfer/create", url.Values{"src": {magnet}}, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// SubmitTorrent implements debrid.TorrentProvider
+func (c *Client) SubmitTorrent(data []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "venaqui.torrent")
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req, err := http.NewRequest("POST", c.baseURL+"/transfer/create", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var created createdTransfer
+	if err := c.do(req, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// transfer is a torrent returned by /transfer/list
+type transfer struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"` // waiting, queued, running, finished, seeding, error, ...
+	Message  string `json:"message"`
+	FolderID string `json:"folder_id"`
+	FileID   string `json:"file_id"`
+}
+
+// WaitForTorrent implements debrid.TorrentProvider. Premiumize stores the
+// files of a finished torrent in the cloud, where they can be downloaded
+// directly.
+func (c *Client) WaitForTorrent(id string, maxWait time.Duration) (*debrid.Torrent, error) {
+	deadline := time.Now().Add(maxWait)
+	for {
+		var result struct {
+			Transfers []transfer `json:"transfers"`
+		}
+		if err := c.get("/transfer/list", url.Values{}, &result); err != nil {
+			return nil, err
+		}
+
+		var current *transfer
+		for i := range result.Transfers {
+			if result.Transfers[i].ID == id {
+				current = &result.Transfers[i]
+			}
+		}
+		if current == nil {
+			return nil, fmt.Errorf("transfer %s: %w", id, debrid.ErrNotFound)
+		}
+
+		switch current.Status {
+		case "finished", "seeding":
+			return c.torrentFiles(current)
+		case "error", "deleted", "banned", "timeout":
+			return nil, fmt.Errorf("torrent failed: %s %s", current.Status, current.Message)
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout waiting for torrent to be ready")
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// cloudItem is a file or folder in the Premiumize cloud
+type cloudItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"` // file or folder
+	Size int64  `json:"size"`
+	Link string `json:"link"`
+}
+
+// torrentFiles lists the files of a finished transfer
+func (c *Client) torrentFiles(t *transfer) (*debrid.Torrent, error) {
+	torrent := &debrid.Torrent{ID: t.ID, Filename: t.Name, Direct: true}
+
+	if t.FileID != "" {
+		var item cloudItem
+		if err := c.get("/item/details", url.Values{"id": {t.FileID}}, &item); err != nil {
+			return nil, err
+		}
+		torrent.Files = []debrid.TorrentFile{{Path: item.Name, Size: item.Size}}
+		torrent.Links = []string{item.Link}
+		return torrent, nil
+	}
+
+	if err := c.listFolder(t.FolderID, "", torrent); err != nil {
+		return nil, err
+	}
+	return torrent, nil
+}
+
+// listFolder adds the files in a cloud folder and its subfolders to torrent
+func (c *Client) listFolder(id, prefix string, torrent *debrid.Torrent) error {
+	var result struct {
+		Content []cloudItem `json:"content"`
+	}
+	if err := c.get("/folder/list", url.Values{"id": {id}}, &result); err != nil {
+		return err
+	}
+
+	for _, item := range result.Content {
+		if item.Type == "folder" {
+			if err := c.listFolder(item.ID, path.Join(prefix, item.Name), torrent); err != nil {
+				return err
+			}
+			continue
+		}
+		torrent.Files = append(torrent.Files, debrid.TorrentFile{Path: path.Join(prefix, item.Name), Size: item.Size})
+		torrent.Links = append(torrent.Links, item.Link)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package premiumize
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+// newFakeServer serves canned Premiumize responses by path
+func newFakeServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("apikey") != "test-key" {
+			t.Errorf("%s called with apikey %q, want test-key", r.URL.Path, r.URL.Query().Get("apikey"))
+		}
+		key := r.URL.Path
+		if id := r.URL.Query().Get("id"); id != "" {
+			key += "?id=" + id
+		}
+		body, ok := responses[key]
+		if !ok {
+			t.Errorf("unexpected request to %s", key)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestUnrestrict(t *testing.T) {
+	server := newFakeServer(t, map[string]string{
+		"/transfer/directdl": `{"status":"success","location":"","filename":"","filesize":0,"content":[{"path":"dir/file.zip","size":2048,"link":"https://cdn.energycdn.com/dl/file.zip"}]}`,
+	})
+
+	link, err := NewClientWithBaseURL("test-key", server.URL).Unrestrict("https://www.uploaded.net/file/abc")
+	if err != nil {
+		t.Fatalf("Unrestrict() error = %v", err)
+	}
+	want := debrid.Link{Filename: "file.zip", Filesize: 2048, Host: "uploaded.net", Download: "https://cdn.energycdn.com/dl/file.zip"}
+	if *link != want {
+		t.Errorf("Unrestrict() = %+v, want %+v", *link, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		body string
+		want error
+	}{
+		{body: `{"status":"error","message":"Not logged in."}`, want: debrid.ErrBadToken},
+		{body: `{"status":"error","message":"You have reached your fair use limit."}`, want: debrid.ErrTrafficExhausted},
+		{body: `{"status":"error","message":"This hoster is not supported."}`, want: debrid.ErrHosterUnsupported},
+	}
+
+	for _, tt := range tests {
+		server := newFakeServer(t, map[string]string{"/transfer/directdl": tt.body})
+		_, err := NewClientWithBaseURL("test-key", server.URL).Unrestrict("https://example.com/file")
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Unrestrict() with %s error = %v, want %v", tt.body, err, tt.want)
+		}
+	}
+}
+
+func TestTorrent(t *testing.T) {
+	server := newFakeServer(t, map[string]string{
+		"/transfer/create": `{"status":"success","id":"t1","name":"Show","type":"torrent"}`,
+		"/transfer/list":   `{"status":"success","transfers":[{"id":"t0","status":"running"},{"id":"t1","name":"Show","status":"finished","folder_id":"f1"}]}`,
+		"/folder/list?id=f1": `{"status":"success","content":[{"id":"a","name":"e01.mkv","type":"file","size":100,"link":"https://cdn/e01.mkv"},` +
+			`{"id":"f2","name":"Extras","type":"folder"}]}`,
+		"/folder/list?id=f2": `{"status":"success","content":[{"id":"b","name":"making-of.mkv","type":"file","size":50,"link":"https://cdn/making-of.mkv"}]}`,
+	})
+	client := NewClientWithBaseURL("test-key", server.URL)
+
+	id, err := client.SubmitMagnet("magnet:?xt=urn:btih:abc")
+	if err != nil || id != "t1" {
+		t.Fatalf("SubmitMagnet() = %q, %v, want t1", id, err)
+	}
+	torrent, err := client.WaitForTorrent(id, 0)
+	if err != nil {
+		t.Fatalf("WaitForTorrent() error = %v", err)
+	}
+	if !torrent.Direct || len(torrent.Links) != 2 || torrent.Files[1] != (debrid.TorrentFile{Path: "Extras/making-of.mkv", Size: 50}) {
+		t.Errorf("WaitForTorrent() = %+v, want the files of both folders as direct links", torrent)
+	}
+}
+
+func TestGetAccount(t *testing.T) {
+	server := newFakeServer(t, map[string]string{
+		"/account/info": `{"status":"success","customer_id":"1234","premium_until":false}`,
+	})
+
+	account, err := NewClientWithBaseURL("test-key", server.URL).GetAccount()
+	if err != nil {
+		t.Fatalf("GetAccount() error = %v", err)
+	}
+	if account.Username != "1234" || account.Premium {
+		t.Errorf("GetAccount() = %+v, want 1234 without premium", account)
+	}
+}
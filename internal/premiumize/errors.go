@@ -0,0 +1,59 @@
+package premiumize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+// messageErrors maps phrases of Premiumize error messages, which carry no
+// error codes, to sentinel errors
+var messageErrors = []struct {
+	phrase string
+	err    error
+}{
+	{"not logged in", debrid.ErrBadToken},
+	{"api key", debrid.ErrBadToken},
+	{"apikey", debrid.ErrBadToken},
+	{"fair use", debrid.ErrTrafficExhausted},
+	{"premium", debrid.ErrPremiumRequired},
+	{"not supported", debrid.ErrHosterUnsupported},
+	{"too many", debrid.ErrRateLimited},
+	{"does not exist", debrid.ErrNotFound},
+	{"not found", debrid.ErrNotFound},
+}
+
+// statusErrors is used when the message is not recognized
+var statusErrors = map[int]error{
+	401: debrid.ErrBadToken,
+	403: debrid.ErrPermissionDenied,
+	404: debrid.ErrNotFound,
+	429: debrid.ErrRateLimited,
+	503: debrid.ErrServiceUnavailable,
+}
+
+// APIError is an error response returned by the Premiumize API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("Premiumize API error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("Premiumize API error: %s", e.Message)
+}
+
+// Unwrap returns the sentinel error matching the response, if any
+func (e *APIError) Unwrap() error {
+	message := strings.ToLower(e.Message)
+	for _, m := range messageErrors {
+		if strings.Contains(message, m.phrase) {
+			return m.err
+		}
+	}
+	return statusErrors[e.StatusCode]
+}
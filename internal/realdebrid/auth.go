@@ -1,7 +1,9 @@
 package realdebrid
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 )
 
@@ -22,13 +24,57 @@ func (c *Client) ValidateToken() error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 401 {
-		return fmt.Errorf("invalid API token: unauthorized")
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return newAPIError(resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// User is the account an API token belongs to
+type User struct {
+	Username   string `json:"username"`
+	Type       string `json:"type"`       // premium or free
+	Premium    int64  `json:"premium"`    // Seconds of premium left
+	Expiration string `json:"expiration"` // When premium ends
+}
+
+// IsPremium reports whether the account's premium subscription is active
+func (u *User) IsPremium() bool {
+	return u.Type == "premium"
+}
+
+// GetUser returns the account the API token belongs to
+func (c *Client) GetUser() (*User, error) {
+	endpoint := fmt.Sprintf("%s/user", c.baseURL)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("token validation failed: status %d", resp.StatusCode)
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
-	return nil
+	var user User
+	if err := json.Unmarshal(body, &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &user, nil
 }
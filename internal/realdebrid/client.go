@@ -87,23 +87,7 @@ func (c *Client) UnrestrictLink(link string) (*UnrestrictedLink, error) {
 	}
 
 	if resp.StatusCode != 200 {
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			// Handle specific error cases
-			switch resp.StatusCode {
-			case 401:
-				return nil, fmt.Errorf("unauthorized: invalid API token")
-			case 403:
-				return nil, fmt.Errorf("forbidden: %s", errorResp.Error)
-			case 429:
-				return nil, fmt.Errorf("rate limited: too many requests, please wait")
-			case 503:
-				return nil, fmt.Errorf("service unavailable: Real-Debrid is temporarily unavailable")
-			default:
-				return nil, fmt.Errorf("RD API error (%d): %s", resp.StatusCode, errorResp.Error)
-			}
-		}
-		return nil, fmt.Errorf("RD API error: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	var result UnrestrictedLink
@@ -103,3 +103,23 @@ func TestUnrestrictLink_Unauthorized(t *testing.T) {
 		t.Fatal("UnrestrictLink() expected error for unauthorized, got nil")
 	}
 }
+
+func TestGetHostRegexes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/hosts/regex" {
+			t.Errorf("Expected /hosts/regex, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]string{`/https?:\/\/(www\.)?1fichier\.com\/\?[a-z0-9]+/`})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-token", server.URL)
+	regexes, err := client.GetHostRegexes()
+	if err != nil {
+		t.Fatalf("GetHostRegexes() error = %v", err)
+	}
+	if len(regexes) != 1 || regexes[0] != `/https?:\/\/(www\.)?1fichier\.com\/\?[a-z0-9]+/` {
+		t.Errorf("GetHostRegexes() = %v, want the 1fichier regex", regexes)
+	}
+}
@@ -0,0 +1,119 @@
+package realdebrid
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+// Sentinel errors for Real-Debrid's documented error codes, shared with
+// the other providers. Errors returned by Client methods can be matched
+// with errors.Is.
+var (
+	ErrBadToken               = debrid.ErrBadToken
+	ErrPermissionDenied       = debrid.ErrPermissionDenied
+	ErrTwoFactorRequired      = debrid.ErrTwoFactorRequired
+	ErrAccountLocked          = debrid.ErrAccountLocked
+	ErrRateLimited            = debrid.ErrRateLimited
+	ErrHosterUnsupported      = debrid.ErrHosterUnsupported
+	ErrHosterUnavailable      = debrid.ErrHosterUnavailable
+	ErrHosterLimitReached     = debrid.ErrHosterLimitReached
+	ErrPremiumRequired        = debrid.ErrPremiumRequired
+	ErrTooManyActiveDownloads = debrid.ErrTooManyActiveDownloads
+	ErrIPNotAllowed           = debrid.ErrIPNotAllowed
+	ErrTrafficExhausted       = debrid.ErrTrafficExhausted
+	ErrFileUnavailable        = debrid.ErrFileUnavailable
+	ErrFileNotAllowed         = debrid.ErrFileNotAllowed
+	ErrServiceUnavailable     = debrid.ErrServiceUnavailable
+	ErrTorrentInvalid         = debrid.ErrTorrentInvalid
+	ErrTorrentTooBig          = debrid.ErrTorrentTooBig
+	ErrNotFound               = debrid.ErrNotFound
+)
+
+// codeErrors maps Real-Debrid error_code values to sentinel errors
+// See https://api.real-debrid.com/#api_error_codes
+var codeErrors = map[int]error{
+	5:  ErrRateLimited,
+	7:  ErrNotFound,
+	8:  ErrBadToken,
+	9:  ErrPermissionDenied,
+	10: ErrTwoFactorRequired,
+	11: ErrTwoFactorRequired,
+	14: ErrAccountLocked,
+	15: ErrAccountLocked,
+	16: ErrHosterUnsupported,
+	17: ErrHosterUnavailable,
+	18: ErrHosterLimitReached,
+	19: ErrHosterUnavailable,
+	20: ErrPremiumRequired,
+	21: ErrTooManyActiveDownloads,
+	22: ErrIPNotAllowed,
+	23: ErrTrafficExhausted,
+	24: ErrFileUnavailable,
+	25: ErrServiceUnavailable,
+	28: ErrFileNotAllowed,
+	29: ErrTorrentTooBig,
+	30: ErrTorrentInvalid,
+	34: ErrRateLimited,
+	35: ErrFileNotAllowed,
+	36: ErrTrafficExhausted,
+}
+
+// statusErrors is used when the response carries no error_code
+var statusErrors = map[int]error{
+	401: ErrBadToken,
+	403: ErrPermissionDenied,
+	404: ErrNotFound,
+	429: ErrRateLimited,
+	503: ErrServiceUnavailable,
+}
+
+// APIError is an error response returned by the Real-Debrid API
+type APIError struct {
+	StatusCode int
+	Code       int
+	Message    string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		if sentinel := e.sentinel(); sentinel != nil {
+			return fmt.Sprintf("RD API error (%d): %v", e.StatusCode, sentinel)
+		}
+		return fmt.Sprintf("RD API error: status %d", e.StatusCode)
+	}
+	if e.Code != 0 {
+		return fmt.Sprintf("RD API error (%d): %s [code %d]", e.StatusCode, e.Message, e.Code)
+	}
+	return fmt.Sprintf("RD API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the sentinel error matching the response, if any
+func (e *APIError) Unwrap() error {
+	return e.sentinel()
+}
+
+// sentinel resolves the error code first, falling back to the HTTP status
+func (e *APIError) sentinel() error {
+	if err, ok := codeErrors[e.Code]; ok {
+		return err
+	}
+	return statusErrors[e.StatusCode]
+}
+
+// newAPIError builds an APIError from a non-successful response body
+func newAPIError(statusCode int, body []byte) error {
+	apiErr := &APIError{StatusCode: statusCode}
+
+	var errorResp ErrorResponse
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		apiErr.Code = errorResp.ErrorCode
+		apiErr.Message = errorResp.Error
+	} else if len(body) > 0 {
+		apiErr.Message = string(body)
+	}
+
+	return apiErr
+}
@@ -0,0 +1,171 @@
+package realdebrid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       error
+	}{
+		{
+			name:       "bad token code",
+			statusCode: 401,
+			body:       `{"error":"bad_token","error_code":8}`,
+			want:       ErrBadToken,
+		},
+		{
+			name:       "hoster unsupported",
+			statusCode: 503,
+			body:       `{"error":"hoster_unsupported","error_code":16}`,
+			want:       ErrHosterUnsupported,
+		},
+		{
+			name:       "hoster in maintenance",
+			statusCode: 503,
+			body:       `{"error":"hoster_in_maintenance","error_code":17}`,
+			want:       ErrHosterUnavailable,
+		},
+		{
+			name:       "traffic exhausted",
+			statusCode: 503,
+			body:       `{"error":"traffic_exhausted","error_code":23}`,
+			want:       ErrTrafficExhausted,
+		},
+		{
+			name:       "file unavailable",
+			statusCode: 503,
+			body:       `{"error":"unavailable_file","error_code":24}`,
+			want:       ErrFileUnavailable,
+		},
+		{
+			name:       "too many active downloads",
+			statusCode: 503,
+			body:       `{"error":"too_many_active_downloads","error_code":21}`,
+			want:       ErrTooManyActiveDownloads,
+		},
+		{
+			name:       "permission denied",
+			statusCode: 403,
+			body:       `{"error":"permission_denied","error_code":9}`,
+			want:       ErrPermissionDenied,
+		},
+		{
+			name:       "status fallback without body",
+			statusCode: 401,
+			body:       ``,
+			want:       ErrBadToken,
+		},
+		{
+			name:       "status fallback for rate limit",
+			statusCode: 429,
+			body:       `not json`,
+			want:       ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newAPIError(tt.statusCode, []byte(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("newAPIError() = %v, want errors.Is %v", err, tt.want)
+			}
+			if debrid.Hint(err) == "" {
+				t.Errorf("Hint(%v) returned empty string", err)
+			}
+		})
+	}
+}
+
+func TestAPIError_Unknown(t *testing.T) {
+	err := newAPIError(400, []byte(`{"error":"bad_parameter","error_code":2}`))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("newAPIError() = %T, want *APIError", err)
+	}
+	if apiErr.Code != 2 || apiErr.Message != "bad_parameter" {
+		t.Errorf("APIError = %+v, want code 2 and message bad_parameter", apiErr)
+	}
+	if debrid.Hint(err) != "" {
+		t.Errorf("Hint() = %q, want empty for unknown code", debrid.Hint(err))
+	}
+}
+
+func TestUnrestrictLink_TypedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "traffic_exhausted", ErrorCode: 23})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-token", server.URL)
+
+	_, err := client.UnrestrictLink("https://example.com/file.zip")
+	if !errors.Is(err, ErrTrafficExhausted) {
+		t.Errorf("UnrestrictLink() error = %v, want ErrTrafficExhausted", err)
+	}
+}
+
+func TestValidateToken_BadToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "bad_token", ErrorCode: 8})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("invalid-token", server.URL)
+
+	if err := client.ValidateToken(); !errors.Is(err, ErrBadToken) {
+		t.Errorf("ValidateToken() error = %v, want ErrBadToken", err)
+	}
+}
+
+func TestAccountExhausted(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{body: `{"error":"traffic_exhausted","error_code":23}`, want: true},
+		{body: `{"error":"traffic_exhausted","error_code":36}`, want: true},
+		{body: `{"error":"hoster_not_free","error_code":20}`, want: true},
+		{body: `{"error":"unavailable_file","error_code":24}`, want: false},
+		{body: `{"error":"bad_token","error_code":8}`, want: false},
+	}
+
+	for _, tt := range tests {
+		err := fmt.Errorf("resolve: %w", newAPIError(503, []byte(tt.body)))
+		if got := debrid.AccountExhausted(err); got != tt.want {
+			t.Errorf("AccountExhausted(%s) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user" || r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("request = %s with %q, want /user with the token", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"id":1,"username":"alice","type":"free","premium":0,"expiration":"2026-01-01T00:00:00.000Z"}`))
+	}))
+	defer server.Close()
+
+	user, err := NewClientWithBaseURL("test-token", server.URL).GetUser()
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if user.Username != "alice" || user.IsPremium() {
+		t.Errorf("GetUser() = %+v, want alice without premium", user)
+	}
+}
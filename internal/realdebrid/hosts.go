@@ -0,0 +1,44 @@
+package realdebrid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// GetHostRegexes returns the regular expressions matching links of the
+// hosters Real-Debrid supports. They are JavaScript literals such as
+// /https?:\/\/(www\.)?1fichier\.com\/.../ as returned by the API.
+func (c *Client) GetHostRegexes() ([]string, error) {
+	endpoint := fmt.Sprintf("%s/hosts/regex", c.baseURL)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	var regexes []string
+	if err := json.Unmarshal(body, &regexes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return regexes, nil
+}
@@ -0,0 +1,208 @@
+package realdebrid
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/media"
+)
+
+// Client is a debrid.Provider, a debrid.Streamer and a debrid.MediaDescriber
+var (
+	_ debrid.Provider       = (*Client)(nil)
+	_ debrid.Streamer       = (*Client)(nil)
+	_ debrid.MediaDescriber = (*Client)(nil)
+)
+
+// Name implements debrid.Provider
+func (c *Client) Name() string {
+	return "Real-Debrid"
+}
+
+// Unrestrict implements debrid.Unrestrictor. Torrent links on rdeb.io are
+// already direct download links.
+func (c *Client) Unrestrict(link string) (*debrid.Link, error) {
+	if strings.Contains(link, "rdeb.io") {
+		return &debrid.Link{Download: link}, nil
+	}
+
+	unrestricted, err := c.UnrestrictLink(link)
+	if err != nil {
+		return nil, err
+	}
+	// The 'download' field holds the direct link, 'link' the original one
+	download := unrestricted.Download
+	if download == "" {
+		download = unrestricted.Link
+	}
+	return &debrid.Link{
+		ID:       unrestricted.ID,
+		Filename: unrestricted.Filename,
+		MimeType: unrestricted.MimeType,
+		Filesize: unrestricted.Filesize,
+		Host:     unrestricted.Host,
+		Download: download,
+	}, nil
+}
+
+// SubmitMagnet implements debrid.TorrentProvider
+func (c *Client) SubmitMagnet(magnet string) (string, error) {
+	resp, err := c.AddMagnet(magnet)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// SubmitTorrent implements debrid.TorrentProvider
+func (c *Client) SubmitTorrent(data []byte) (string, error) {
+	resp, err := c.AddTorrentData(data)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// WaitForTorrent implements debrid.TorrentProvider. Real-Debrid returns a
+// link per selected file, unless it bundled them into an archive.
+func (c *Client) WaitForTorrent(id string, maxWait time.Duration) (*debrid.Torrent, error) {
+	info, err := c.GetTorrentInfo(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select all files if needed
+	if info.Status == "waiting_files_selection" {
+		fileIDs := []int{}
+		for _, file := range info.Files {
+			fileIDs = append(fileIDs, file.ID)
+		}
+		if len(fileIDs) > 0 {
+			if err := c.SelectFiles(id, fileIDs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	info, err = c.WaitForTorrentReady(id, maxWait)
+	if err != nil {
+		return nil, err
+	}
+
+	torrent := &debrid.Torrent{
+		ID:       info.ID,
+		Filename: info.Filename,
+		Hash:     info.Hash,
+		Links:    info.Links,
+	}
+	for _, file := range info.Files {
+		if file.Selected == 1 {
+			torrent.Files = append(torrent.Files, debrid.TorrentFile{Path: file.Path, Size: file.Bytes})
+		}
+	}
+	return torrent, nil
+}
+
+// GetAccount implements debrid.Provider
+func (c *Client) GetAccount() (*debrid.Account, error) {
+	user, err := c.GetUser()
+	if err != nil {
+		return nil, err
+	}
+	// An unparsable date leaves the expiration unknown
+	expiration, _ := time.Parse(time.RFC3339, user.Expiration)
+	return &debrid.Account{Username: user.Username, Premium: user.IsPremium(), Expiration: expiration}, nil
+}
+
+// streamFormats are the transcoded formats offered, best for players first
+var streamFormats = []string{"apple", "liveMP4", "h264WebM", "dash"}
+
+// Streams implements debrid.Streamer. Qualities above the resolution of the
+// original are left out.
+func (c *Client) Streams(id string) (*debrid.Media, error) {
+	info, err := c.GetMediaInfos(id)
+	if err != nil {
+		return nil, err
+	}
+	transcodes, err := c.GetTranscode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	media := &debrid.Media{Duration: time.Duration(info.Duration * float64(time.Second))}
+	media.Width, media.Height = info.Resolution()
+	for _, format := range streamFormats {
+		var streams []debrid.Stream
+		for quality, url := range transcodes[format] {
+			if height := qualityHeight(quality); media.Height > 0 && height > media.Height {
+				continue
+			}
+			streams = append(streams, debrid.Stream{Format: format, Quality: quality, URL: url})
+		}
+		sort.Slice(streams, func(i, j int) bool {
+			return qualityHeight(streams[i].Quality) > qualityHeight(streams[j].Quality)
+		})
+		media.Streams = append(media.Streams, streams...)
+	}
+	return media, nil
+}
+
+// qualityHeight returns the height of a quality such as 720p, 0 if it has none
+func qualityHeight(quality string) int {
+	height, _ := strconv.Atoi(strings.TrimSuffix(strings.ToLower(quality), "p"))
+	return height
+}
+
+// MediaInfo implements debrid.MediaDescriber. Tracks are ordered by name,
+// which Real-Debrid numbers in file order.
+func (c *Client) MediaInfo(id string) (*media.Info, error) {
+	info, err := c.GetMediaInfos(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &media.Info{
+		Duration: time.Duration(info.Duration * float64(time.Second)),
+		Source:   c.Name(),
+	}
+	for _, video := range info.Video {
+		if video.Height > result.Height {
+			result.Width, result.Height = video.Width, video.Height
+			result.VideoCodec = video.Codec
+		}
+	}
+	result.Quality = media.QualityOf(result.Width, result.Height)
+	for _, name := range sortedKeys(info.Audio) {
+		audio := info.Audio[name]
+		// 5.1 is six channels
+		channels := int(audio.Channels) + int(math.Round(math.Mod(audio.Channels, 1)*10))
+		result.Audio = append(result.Audio, media.Track{Lang: trackLang(audio.LangISO, audio.Lang), Codec: audio.Codec, Channels: channels})
+	}
+	for _, name := range sortedKeys(info.Subtitles) {
+		subtitle := info.Subtitles[name]
+		result.Subtitles = append(result.Subtitles, media.Track{Lang: trackLang(subtitle.LangISO, subtitle.Lang), Codec: strings.ToLower(subtitle.Type)})
+	}
+	return result, nil
+}
+
+// trackLang prefers the ISO code of a track's language, as ffprobe reports it
+func trackLang(iso, name string) string {
+	if iso != "" && iso != "und" {
+		return iso
+	}
+	return name
+}
+
+// sortedKeys returns the names of a file's tracks in order
+func sortedKeys[T any](tracks map[string]T) []string {
+	names := make([]string, 0, len(tracks))
+	for name := range tracks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
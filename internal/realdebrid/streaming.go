@@ -0,0 +1,136 @@
+package realdebrid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Transcodes maps a streaming format, such as apple (HLS), dash, liveMP4 or
+// h264WebM, to the stream URL of each quality
+type Transcodes map[string]map[string]string
+
+// MediaInfo describes the video or audio file of an unrestricted link
+type MediaInfo struct {
+	Filename string  `json:"filename"`
+	Type     string  `json:"type"`     // movie, show or audio
+	Duration float64 `json:"duration"` // Seconds
+	Bitrate  int64   `json:"bitrate"`
+	Size     int64   `json:"size"`
+	// Tracks by name and the qualities it can be transcoded to, read from
+	// details and availableQualities
+	Video     map[string]VideoStream    `json:"-"`
+	Audio     map[string]AudioStream    `json:"-"`
+	Subtitles map[string]SubtitleStream `json:"-"`
+	Qualities map[string]string         `json:"-"`
+}
+
+// VideoStream is a video track of a file
+type VideoStream struct {
+	Lang   string `json:"lang"`
+	Codec  string `json:"codec"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// AudioStream is an audio track of a file
+type AudioStream struct {
+	Lang     string  `json:"lang"`
+	LangISO  string  `json:"lang_iso"`
+	Codec    string  `json:"codec"`
+	Channels float64 `json:"channels"` // Such as 5.1
+}
+
+// SubtitleStream is a subtitle track of a file
+type SubtitleStream struct {
+	Lang    string `json:"lang"`
+	LangISO string `json:"lang_iso"`
+	Type    string `json:"type"` // Such as SRT or ASS
+}
+
+// GetTranscode returns the streams Real-Debrid transcodes an unrestricted
+// link into, by the link's ID
+func (c *Client) GetTranscode(id string) (Transcodes, error) {
+	var formats map[string]json.RawMessage
+	if err := c.getJSON("/streaming/transcode/"+id, &formats); err != nil {
+		return nil, err
+	}
+
+	// Formats without streams come as empty lists
+	transcodes := Transcodes{}
+	for format, raw := range formats {
+		var qualities map[string]string
+		if json.Unmarshal(raw, &qualities) == nil && len(qualities) > 0 {
+			transcodes[format] = qualities
+		}
+	}
+	return transcodes, nil
+}
+
+// GetMediaInfos returns the media details of an unrestricted link, by the
+// link's ID
+func (c *Client) GetMediaInfos(id string) (*MediaInfo, error) {
+	var raw struct {
+		MediaInfo
+		Details struct {
+			Video     json.RawMessage `json:"video"`
+			Audio     json.RawMessage `json:"audio"`
+			Subtitles json.RawMessage `json:"subtitles"`
+		} `json:"details"`
+		AvailableQualities json.RawMessage `json:"availableQualities"`
+	}
+	if err := c.getJSON("/streaming/mediaInfos/"+id, &raw); err != nil {
+		return nil, err
+	}
+
+	// Empty maps come as empty lists, which are left out
+	info := raw.MediaInfo
+	json.Unmarshal(raw.Details.Video, &info.Video)
+	json.Unmarshal(raw.Details.Audio, &info.Audio)
+	json.Unmarshal(raw.Details.Subtitles, &info.Subtitles)
+	json.Unmarshal(raw.AvailableQualities, &info.Qualities)
+	return &info, nil
+}
+
+// Resolution returns the size of the largest video track, 0 when there is
+// none
+func (m *MediaInfo) Resolution() (int, int) {
+	var width, height int
+	for _, video := range m.Video {
+		if video.Height > height {
+			width, height = video.Width, video.Height
+		}
+	}
+	return width, height
+}
+
+// getJSON calls an endpoint and unmarshals the response into result
+func (c *Client) getJSON(path string, result interface{}) error {
+	req, err := http.NewRequest("GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return newAPIError(resp.StatusCode, body)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package realdebrid
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+)
+
+func TestStreams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/streaming/mediaInfos/ABC":
+			io.WriteString(w, `{"filename":"movie.mkv","type":"movie","duration":5400.5,"details":{"video":{"und1":{"codec":"h264","width":1280,"height":720}},"audio":[],"subtitles":[]},"availableQualities":{"Original":"original"}}`)
+		case "/streaming/transcode/ABC":
+			io.WriteString(w, `{"apple":{"480p":"https://s/apple/480","720p":"https://s/apple/720","1080p":"https://s/apple/1080"},"dash":[],"liveMP4":{"720p":"https://s/mp4/720"},"h264WebM":[]}`)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	media, err := NewClientWithBaseURL("test-token", server.URL).Streams("ABC")
+	if err != nil {
+		t.Fatalf("Streams() error = %v", err)
+	}
+	if media.Width != 1280 || media.Height != 720 || media.Duration != 5400500*time.Millisecond {
+		t.Errorf("Streams() = %dx%d, %v, want 1280x720, 1h30m0.5s", media.Width, media.Height, media.Duration)
+	}
+
+	// 1080p is above the original's resolution
+	want := []debrid.Stream{
+		{Format: "apple", Quality: "720p", URL: "https://s/apple/720"},
+		{Format: "apple", Quality: "480p", URL: "https://s/apple/480"},
+		{Format: "liveMP4", Quality: "720p", URL: "https://s/mp4/720"},
+	}
+	if len(media.Streams) != len(want) {
+		t.Fatalf("Streams() = %+v, want %+v", media.Streams, want)
+	}
+	for i := range want {
+		if media.Streams[i] != want[i] {
+			t.Errorf("Streams()[%d] = %+v, want %+v", i, media.Streams[i], want[i])
+		}
+	}
+}
+
+func TestMediaInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"filename":"show.mkv","type":"show","duration":2700,"details":{
+			"video":{"und1":{"codec":"hevc","width":1920,"height":800}},
+			"audio":{"jpn2":{"lang":"Japanese","lang_iso":"jpn","codec":"aac","channels":2.0},"eng1":{"lang":"English","lang_iso":"eng","codec":"eac3","channels":5.1}},
+			"subtitles":{"eng1":{"lang":"English","lang_iso":"eng","type":"ASS"}}}}`)
+	}))
+	defer server.Close()
+
+	info, err := NewClientWithBaseURL("test-token", server.URL).MediaInfo("ABC")
+	if err != nil {
+		t.Fatalf("MediaInfo() error = %v", err)
+	}
+	if got, want := info.Summary(), "45m00s, 1920x800 (1080p), hevc"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	if got, want := info.AudioSummary(), "eng eac3 5.1, jpn aac 2.0"; got != want {
+		t.Errorf("AudioSummary() = %q, want %q", got, want)
+	}
+	if got, want := info.SubtitleSummary(), "eng ass"; got != want {
+		t.Errorf("SubtitleSummary() = %q, want %q", got, want)
+	}
+}
@@ -14,6 +14,7 @@ import (
 type TorrentInfo struct {
 	ID       string   `json:"id"`
 	Filename string   `json:"filename"`
+	Hash     string   `json:"hash"`
 	Status   string   `json:"status"` // waiting_files_selection, queued, downloading, downloaded, error
 	Files    []File   `json:"files"`
 	Links    []string `json:"links"` // Download links after torrent is ready
@@ -37,8 +38,6 @@ type AddTorrentResponse struct {
 
 // AddTorrent adds a torrent file URL to Real-Debrid
 func (c *Client) AddTorrent(torrentURL string) (*AddTorrentResponse, error) {
-	endpoint := fmt.Sprintf("%s/torrents/addTorrent", c.baseURL)
-
 	// For torrent URLs, we need to download the torrent file first
 	// Real-Debrid API expects the torrent file content, not the URL
 	resp, err := http.Get(torrentURL)
@@ -56,6 +55,13 @@ func (c *Client) AddTorrent(torrentURL string) (*AddTorrentResponse, error) {
 		return nil, fmt.Errorf("failed to read torrent file: %w", err)
 	}
 
+	return c.AddTorrentData(torrentData)
+}
+
+// AddTorrentData adds the contents of a torrent file to Real-Debrid
+func (c *Client) AddTorrentData(torrentData []byte) (*AddTorrentResponse, error) {
+	endpoint := fmt.Sprintf("%s/torrents/addTorrent", c.baseURL)
+
 	// Real-Debrid API expects the torrent file content directly in the PUT request body
 	req, err := http.NewRequest("PUT", endpoint, bytes.NewReader(torrentData))
 	if err != nil {
@@ -65,7 +71,7 @@ func (c *Client) AddTorrent(torrentURL string) (*AddTorrentResponse, error) {
 	req.Header.Set("Authorization", "Bearer "+c.apiToken)
 	req.Header.Set("Content-Type", "application/x-bittorrent")
 
-	resp, err = c.httpClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -77,11 +83,7 @@ func (c *Client) AddTorrent(torrentURL string) (*AddTorrentResponse, error) {
 	}
 
 	if resp.StatusCode != 201 {
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			return nil, fmt.Errorf("RD API error (%d): %s", resp.StatusCode, errorResp.Error)
-		}
-		return nil, fmt.Errorf("RD API error: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	var result AddTorrentResponse
@@ -125,11 +127,7 @@ func (c *Client) AddMagnet(magnetLink string) (*AddTorrentResponse, error) {
 	}
 
 	if resp.StatusCode != 201 {
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			return nil, fmt.Errorf("RD API error (%d): %s", resp.StatusCode, errorResp.Error)
-		}
-		return nil, fmt.Errorf("RD API error: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	var result AddTorrentResponse
@@ -177,11 +175,7 @@ func (c *Client) SelectFiles(torrentID string, fileIDs []int) error {
 	// According to API docs: returns 204 HTTP code, or 202 if action already done
 	if resp.StatusCode != 204 && resp.StatusCode != 202 {
 		body, _ := io.ReadAll(resp.Body)
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			return fmt.Errorf("RD API error (%d): %s", resp.StatusCode, errorResp.Error)
-		}
-		return fmt.Errorf("RD API error: %d - %s", resp.StatusCode, string(body))
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	return nil
@@ -210,11 +204,7 @@ func (c *Client) GetTorrentInfo(torrentID string) (*TorrentInfo, error) {
 	}
 
 	if resp.StatusCode != 200 {
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			return nil, fmt.Errorf("RD API error (%d): %s", resp.StatusCode, errorResp.Error)
-		}
-		return nil, fmt.Errorf("RD API error: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	var result TorrentInfo
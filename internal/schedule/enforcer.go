@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"sync"
+	"time"
+)
+
+// Downloads is the part of the aria2 client the enforcer uses
+type Downloads interface {
+	PauseDownload(gid string) error
+	ResumeDownload(gid string) error
+}
+
+// Event describes downloads held or released by an enforcer
+type Event struct {
+	Paused bool   // The schedule closed; otherwise it opened
+	Status string // Schedule status at the time of the event
+}
+
+// String describes the event for the user
+func (e *Event) String() string {
+	if e.Paused {
+		return "Outside the download window, paused. " + e.Status
+	}
+	return "Download window opened, resumed. " + e.Status
+}
+
+// Enforcer pauses the downloads it tracks while the schedule is closed and
+// resumes them once it opens
+type Enforcer struct {
+	mu        sync.Mutex
+	schedule  *Schedule
+	downloads Downloads
+	gids      []string
+	closed    bool
+	now       func() time.Time
+}
+
+// NewEnforcer creates an enforcer for a schedule
+func NewEnforcer(schedule *Schedule, downloads Downloads) *Enforcer {
+	return &Enforcer{schedule: schedule, downloads: downloads, now: time.Now}
+}
+
+// Track adds a download to those held by the schedule. Downloads tracked
+// while the schedule is closed are expected to have been added paused, and
+// are resumed when it opens.
+func (e *Enforcer) Track(gid string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.gids = append(e.gids, gid)
+	if !e.schedule.Open(e.now()) {
+		e.closed = true
+	}
+}
+
+// Holds reports whether the enforcer keeps a download paused because the
+// schedule is closed
+func (e *Enforcer) Holds(gid string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.closed {
+		return false
+	}
+	for _, g := range e.gids {
+		if g == gid {
+			return true
+		}
+	}
+	return false
+}
+
+// Open reports whether downloads may run at t
+func (e *Enforcer) Open(t time.Time) bool {
+	return e.schedule.Open(t)
+}
+
+// Status describes the schedule at t for the user
+func (e *Enforcer) Status(t time.Time) string {
+	return e.schedule.Status(t)
+}
+
+// Poll applies the schedule at t. It returns an event when the schedule
+// closed or opened since the last poll, and nil otherwise.
+func (e *Enforcer) Poll(t time.Time) *Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.schedule.Open(t) {
+		// Pause again on every poll in case something else resumed them;
+		// paused, finished and removed downloads fail and are fine as they are
+		for _, gid := range e.gids {
+			_ = e.downloads.PauseDownload(gid)
+		}
+		if e.closed {
+			return nil
+		}
+		e.closed = true
+		return &Event{Paused: true, Status: e.schedule.Status(t)}
+	}
+
+	if !e.closed {
+		return nil
+	}
+	e.closed = false
+	for _, gid := range e.gids {
+		_ = e.downloads.ResumeDownload(gid)
+	}
+	return &Event{Status: e.schedule.Status(t)}
+}
@@ -0,0 +1,209 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/config"
+)
+
+// window is a daily period downloads may run in. It ends the next day when
+// end is not after start.
+type window struct {
+	days  map[time.Weekday]bool // Days the window starts on; nil for every day
+	start int                   // Minutes after midnight
+	end   int
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseWindow parses a window from the configuration
+func parseWindow(cfg config.WindowConfig) (window, error) {
+	start, err := parseClock(cfg.Start)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseClock(cfg.End)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid end: %w", err)
+	}
+
+	w := window{start: start, end: end}
+	for _, day := range cfg.Days {
+		key := strings.ToLower(strings.TrimSpace(day))
+		if len(key) > 3 {
+			key = key[:3]
+		}
+		weekday, ok := weekdays[key]
+		if !ok {
+			return window{}, fmt.Errorf("invalid day %q", day)
+		}
+		if w.days == nil {
+			w.days = make(map[time.Weekday]bool)
+		}
+		w.days[weekday] = true
+	}
+	return w, nil
+}
+
+// parseClock parses a time of day such as 01:30 into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day like 01:30", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// startsOn reports whether the window starts on the given day
+func (w window) startsOn(day time.Weekday) bool {
+	return w.days == nil || w.days[day]
+}
+
+// contains reports whether t falls inside the window
+func (w window) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	switch {
+	case w.start == w.end:
+		return w.startsOn(t.Weekday())
+	case w.start < w.end:
+		return w.startsOn(t.Weekday()) && minute >= w.start && minute < w.end
+	default:
+		// The part after midnight belongs to the previous day's window
+		return (w.startsOn(t.Weekday()) && minute >= w.start) ||
+			(w.startsOn(t.AddDate(0, 0, -1).Weekday()) && minute < w.end)
+	}
+}
+
+// Schedule decides when downloads may run: not before a start time, and
+// only inside the download windows if any are configured
+type Schedule struct {
+	windows   []window
+	notBefore time.Time
+}
+
+// New creates a schedule from the configured download windows
+func New(cfg config.ScheduleConfig) (*Schedule, error) {
+	s := &Schedule{}
+	for i, wc := range cfg.Windows {
+		w, err := parseWindow(wc)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", i+1, err)
+		}
+		s.windows = append(s.windows, w)
+	}
+	return s, nil
+}
+
+// Until returns a copy of the schedule that stays closed before t
+func (s *Schedule) Until(t time.Time) *Schedule {
+	c := *s
+	c.notBefore = t
+	return &c
+}
+
+// Empty reports whether the schedule never holds downloads back
+func (s *Schedule) Empty() bool {
+	return len(s.windows) == 0 && s.notBefore.IsZero()
+}
+
+// Open reports whether downloads may run at t
+func (s *Schedule) Open(t time.Time) bool {
+	if t.Before(s.notBefore) {
+		return false
+	}
+	if len(s.windows) == 0 {
+		return true
+	}
+	for _, w := range s.windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextChange returns when the schedule next opens or closes after t, or
+// the zero time if it stays as it is. The schedule only changes at the start
+// time, at midnight and where a window starts or ends, so only those times
+// are checked.
+func (s *Schedule) NextChange(t time.Time) time.Time {
+	open := s.Open(t)
+	var next time.Time
+	consider := func(c time.Time) {
+		if c.After(t) && (next.IsZero() || c.Before(next)) && s.Open(c) != open {
+			next = c
+		}
+	}
+
+	consider(s.notBefore)
+	from := t
+	if s.notBefore.After(from) {
+		from = s.notBefore
+	}
+
+	// Windows repeat every week at most
+	for day := 0; day <= 8 && len(s.windows) > 0; day++ {
+		d := from.AddDate(0, 0, day)
+		consider(clock(d, 0))
+		for _, w := range s.windows {
+			consider(clock(d, w.start))
+			consider(clock(d, w.end))
+		}
+	}
+	return next
+}
+
+// clock returns the given minute after midnight on t's day
+func clock(t time.Time, minute int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), minute/60, minute%60, 0, 0, t.Location())
+}
+
+// Status describes the schedule at t for the user
+func (s *Schedule) Status(t time.Time) string {
+	next := s.NextChange(t)
+	if s.Open(t) {
+		if next.IsZero() {
+			return "Download window open"
+		}
+		return "Download window open until " + formatTime(t, next)
+	}
+	if next.IsZero() {
+		return "Waiting for a download window"
+	}
+	return "Waiting until " + formatTime(t, next)
+}
+
+// formatTime formats t, adding the day when it is not today
+func formatTime(now, t time.Time) string {
+	if t.YearDay() == now.YearDay() && t.Year() == now.Year() {
+		return t.Format("15:04")
+	}
+	if t.Sub(now) < 6*24*time.Hour {
+		return t.Format("Mon 15:04")
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// ParseAt parses the time given to --at: a time of day such as 01:00, which
+// is the next time the clock shows it, or a date and time
+func ParseAt(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time like 01:00 or 2006-01-02 01:00", s)
+}
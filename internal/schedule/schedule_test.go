@@ -0,0 +1,203 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mhrsntrk/venaqui/internal/config"
+)
+
+// at returns a time in the week starting on Sunday 2026-03-01
+func at(day time.Weekday, clock string) time.Time {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		panic(err)
+	}
+	return time.Date(2026, 3, 1+int(day), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+func TestSchedule_Open(t *testing.T) {
+	s, err := New(config.ScheduleConfig{Windows: []config.WindowConfig{
+		{Start: "01:00", End: "07:00"},
+		{Days: []string{"Saturday", "sun"}, Start: "22:00", End: "02:00"},
+	}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{name: "inside nightly window", t: at(time.Tuesday, "03:00"), want: true},
+		{name: "window start", t: at(time.Tuesday, "01:00"), want: true},
+		{name: "window end", t: at(time.Tuesday, "07:00")},
+		{name: "daytime", t: at(time.Tuesday, "14:00")},
+		{name: "weekend evening", t: at(time.Saturday, "23:00"), want: true},
+		{name: "past midnight after saturday", t: at(time.Sunday, "00:30"), want: true},
+		{name: "weekday evening", t: at(time.Friday, "23:00")},
+		{name: "past midnight after sunday", t: time.Date(2026, 3, 9, 0, 30, 0, 0, time.UTC), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Open(tt.t); got != tt.want {
+				t.Errorf("Open(%s) = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, w := range []config.WindowConfig{
+		{Start: "1am", End: "07:00"},
+		{Start: "01:00", End: "25:00"},
+		{Days: []string{"someday"}, Start: "01:00", End: "07:00"},
+	} {
+		if _, err := New(config.ScheduleConfig{Windows: []config.WindowConfig{w}}); err == nil {
+			t.Errorf("New(%+v) error = nil, want an error", w)
+		}
+	}
+}
+
+func TestSchedule_NextChange(t *testing.T) {
+	s, _ := New(config.ScheduleConfig{Windows: []config.WindowConfig{{Start: "01:00", End: "07:00"}}})
+	now := at(time.Tuesday, "14:20")
+
+	if got, want := s.NextChange(now), at(time.Wednesday, "01:00"); !got.Equal(want) {
+		t.Errorf("NextChange() = %v, want %v", got, want)
+	}
+	if got, want := s.NextChange(at(time.Wednesday, "02:00")), at(time.Wednesday, "07:00"); !got.Equal(want) {
+		t.Errorf("NextChange() = %v, want %v", got, want)
+	}
+
+	// A start time inside the window opens it right away
+	delayed := s.Until(at(time.Wednesday, "03:30"))
+	if got, want := delayed.NextChange(now), at(time.Wednesday, "03:30"); !got.Equal(want) {
+		t.Errorf("NextChange() with start time = %v, want %v", got, want)
+	}
+
+	// Without windows the schedule never closes again
+	empty, _ := New(config.ScheduleConfig{})
+	if !empty.Empty() || !empty.NextChange(now).IsZero() {
+		t.Error("empty schedule should be empty and never change")
+	}
+	if got := empty.Until(now.Add(time.Hour)).Status(now); got != "Waiting until 15:20" {
+		t.Errorf("Status() = %q, want Waiting until 15:20", got)
+	}
+}
+
+func TestSchedule_NextChange_Windows(t *testing.T) {
+	s, _ := New(config.ScheduleConfig{Windows: []config.WindowConfig{
+		{Days: []string{"sat", "sun"}, Start: "22:00", End: "02:00"},
+		{Days: []string{"mon"}, Start: "00:00", End: "00:00"},
+	}})
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{name: "weekday to weekend window", t: at(time.Tuesday, "14:20"), want: at(time.Saturday, "22:00")},
+		{name: "overnight window", t: at(time.Saturday, "23:00"), want: time.Date(2026, 3, 8, 2, 0, 0, 0, time.UTC)},
+		{name: "window into all-day window", t: at(time.Sunday, "23:00"), want: at(time.Tuesday, "00:00")},
+		{name: "all-day window opens at midnight", t: at(time.Sunday, "12:00"), want: at(time.Sunday, "22:00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.NextChange(tt.t); !got.Equal(tt.want) {
+				t.Errorf("NextChange(%s) = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+			}
+		})
+	}
+
+	// A start time that has passed no longer changes anything
+	if got := s.Until(at(time.Sunday, "00:00")).NextChange(at(time.Tuesday, "14:20")); !got.Equal(at(time.Saturday, "22:00")) {
+		t.Errorf("NextChange() after start time = %v, want Sat 22:00", got)
+	}
+	open, _ := New(config.ScheduleConfig{})
+	if got := open.Until(at(time.Sunday, "00:00")).NextChange(at(time.Tuesday, "14:20")); !got.IsZero() {
+		t.Errorf("NextChange() without windows after start time = %v, want zero", got)
+	}
+}
+
+func TestParseAt(t *testing.T) {
+	now := at(time.Tuesday, "14:20")
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "16:00", want: at(time.Tuesday, "16:00")},
+		{in: "01:00", want: at(time.Wednesday, "01:00")},
+		{in: "14:20", want: at(time.Wednesday, "14:20")},
+		{in: "2026-03-10 08:15", want: time.Date(2026, 3, 10, 8, 15, 0, 0, time.UTC)},
+		{in: "tonight", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAt(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAt(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("ParseAt(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// fakeDownloads records pauses and resumes
+type fakeDownloads struct {
+	paused, resumed int
+}
+
+func (f *fakeDownloads) PauseDownload(string) error {
+	f.paused++
+	return nil
+}
+
+func (f *fakeDownloads) ResumeDownload(string) error {
+	f.resumed++
+	return nil
+}
+
+func TestEnforcer_Poll(t *testing.T) {
+	s, _ := New(config.ScheduleConfig{Windows: []config.WindowConfig{{Start: "01:00", End: "07:00"}}})
+	downloads := &fakeDownloads{}
+	e := NewEnforcer(s, downloads)
+
+	// Added paused at night and tracked before the window opens
+	e.now = func() time.Time { return at(time.Tuesday, "23:00") }
+	e.Track("a")
+
+	if event := e.Poll(at(time.Wednesday, "01:30")); event == nil || event.Paused {
+		t.Fatalf("Poll() when the window opens = %v, want a resume event", event)
+	}
+	if downloads.resumed != 1 {
+		t.Errorf("resumed %d downloads, want 1", downloads.resumed)
+	}
+	if event := e.Poll(at(time.Wednesday, "02:00")); event != nil {
+		t.Errorf("Poll() inside the window = %v, want nil", event)
+	}
+
+	event := e.Poll(at(time.Wednesday, "07:00"))
+	if event == nil || !event.Paused {
+		t.Fatalf("Poll() when the window closes = %v, want a pause event", event)
+	}
+	if event.Status != "Waiting until Thu 01:00" {
+		t.Errorf("event Status = %q, want Waiting until Thu 01:00", event.Status)
+	}
+	if e.Poll(at(time.Wednesday, "08:00")) != nil || downloads.paused != 2 {
+		t.Errorf("Poll() while closed should pause again without an event, paused %d", downloads.paused)
+	}
+	if !e.Holds("a") || e.Holds("b") {
+		t.Errorf("Holds() while closed = %v for a and %v for b, want only a", e.Holds("a"), e.Holds("b"))
+	}
+	e.Poll(at(time.Thursday, "01:00"))
+	if e.Holds("a") {
+		t.Error("Holds() once the window opens = true, want false")
+	}
+}
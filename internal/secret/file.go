@@ -0,0 +1,205 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// iterations is the PBKDF2 work factor for deriving the file key
+const iterations = 600000
+
+// fileFormat is the on-disk form of an encrypted secrets file
+type fileFormat struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"` // AES-256-GCM sealed JSON object of secrets
+}
+
+// File keeps secrets in a file encrypted with a key derived from a
+// passphrase
+type File struct {
+	path       string
+	passphrase func() (string, error)
+
+	mu      sync.Mutex
+	secrets map[string]string // Decrypted contents, nil until read
+	key     []byte
+	salt    []byte
+}
+
+// NewFile creates a store backed by the encrypted file at path. The
+// passphrase is asked for once, when the file is first read or written.
+func NewFile(path string, passphrase func() (string, error)) *File {
+	return &File{path: path, passphrase: passphrase}
+}
+
+// Get implements Store
+func (f *File) Get(name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Don't ask for a passphrase to create a file only to read from it
+	if _, err := os.Stat(f.path); f.secrets == nil && errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err := f.load(); err != nil {
+		return "", err
+	}
+	value, ok := f.secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Set implements Store
+func (f *File) Set(name, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return err
+	}
+	f.secrets[name] = value
+	return f.save()
+}
+
+// load decrypts the file, or starts an empty one if it doesn't exist
+func (f *File) load() error {
+	if f.secrets != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		f.salt = make([]byte, 16)
+		if _, err := rand.Read(f.salt); err != nil {
+			return err
+		}
+		if f.key, err = f.deriveKey(f.salt, iterations); err != nil {
+			return err
+		}
+		f.secrets = map[string]string{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	var file fileFormat
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != 1 {
+		return fmt.Errorf("%s is not a venaqui secrets file", f.path)
+	}
+	key, err := f.deriveKey(file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return errors.New("failed to decrypt secrets file: wrong passphrase?")
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return fmt.Errorf("failed to decode secrets file: %w", err)
+	}
+	f.secrets, f.key, f.salt = secrets, key, file.Salt
+	return nil
+}
+
+// save encrypts the secrets with a fresh nonce and writes them
+func (f *File) save() error {
+	plain, err := json.Marshal(f.secrets)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(f.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fileFormat{
+		Version:    1,
+		Iterations: iterations,
+		Salt:       f.salt,
+		Nonce:      nonce,
+		Data:       gcm.Seal(nil, nonce, plain, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+	// Write next to the file and rename so a failed write keeps the old one
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	return os.Rename(tmp, f.path)
+}
+
+// deriveKey asks for the passphrase and derives the file key from it
+func (f *File) deriveKey(salt []byte, iter int) ([]byte, error) {
+	passphrase, err := f.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, errors.New("the secrets passphrase is empty")
+	}
+	return pbkdf2SHA256([]byte(passphrase), salt, iter, 32), nil
+}
+
+// newGCM returns AES-GCM for a 32 byte key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key as specified in RFC 8018
+func pbkdf2SHA256(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
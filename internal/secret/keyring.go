@@ -0,0 +1,200 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Secret Service names, see https://specifications.freedesktop.org/secret-service/
+const (
+	serviceName      = "org.freedesktop.secrets"
+	servicePath      = dbus.ObjectPath("/org/freedesktop/secrets")
+	serviceInterface = "org.freedesktop.Secret.Service"
+	itemInterface    = "org.freedesktop.Secret.Item"
+	promptInterface  = "org.freedesktop.Secret.Prompt"
+)
+
+// promptTimeout bounds how long the user may take to unlock the keyring
+const promptTimeout = 2 * time.Minute
+
+// keyringSecret is the Secret Service's secret struct
+type keyringSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// Keyring keeps secrets in the desktop keyring through the freedesktop
+// Secret Service API, implemented by GNOME Keyring and KWallet
+type Keyring struct {
+	conn *dbus.Conn
+}
+
+// NewKeyring creates a store using conn, or the session bus if conn is nil
+func NewKeyring(conn *dbus.Conn) *Keyring {
+	return &Keyring{conn: conn}
+}
+
+// attributes identify venaqui's secret with the given name
+func attributes(name string) map[string]string {
+	return map[string]string{"application": "venaqui", "name": name}
+}
+
+// connect returns the bus connection and the Secret Service on it
+func (k *Keyring) connect() (*dbus.Conn, dbus.BusObject, error) {
+	conn := k.conn
+	if conn == nil {
+		var err error
+		conn, err = dbus.SessionBus()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to session bus: %w", err)
+		}
+	}
+	return conn, conn.Object(serviceName, servicePath), nil
+}
+
+// openSession opens an unencrypted session; the bus is local to the user
+func openSession(service dbus.BusObject) (dbus.ObjectPath, error) {
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err := service.Call(serviceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &session)
+	if err != nil {
+		return "", fmt.Errorf("failed to open keyring session: %w", err)
+	}
+	return session, nil
+}
+
+// Get implements Store
+func (k *Keyring) Get(name string) (string, error) {
+	conn, service, err := k.connect()
+	if err != nil {
+		return "", err
+	}
+
+	var unlocked, locked []dbus.ObjectPath
+	if err := service.Call(serviceInterface+".SearchItems", 0, attributes(name)).Store(&unlocked, &locked); err != nil {
+		return "", fmt.Errorf("failed to search keyring: %w", err)
+	}
+	if len(unlocked) == 0 && len(locked) > 0 {
+		if unlocked, err = unlock(conn, service, locked); err != nil {
+			return "", err
+		}
+	}
+	if len(unlocked) == 0 {
+		return "", ErrNotFound
+	}
+
+	session, err := openSession(service)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Object(serviceName, session).Call("org.freedesktop.Secret.Session.Close", 0)
+
+	var secret keyringSecret
+	if err := conn.Object(serviceName, unlocked[0]).Call(itemInterface+".GetSecret", 0, session).Store(&secret); err != nil {
+		return "", fmt.Errorf("failed to read secret from keyring: %w", err)
+	}
+	return string(secret.Value), nil
+}
+
+// Set implements Store
+func (k *Keyring) Set(name, value string) error {
+	conn, service, err := k.connect()
+	if err != nil {
+		return err
+	}
+
+	var collection dbus.ObjectPath
+	if err := service.Call(serviceInterface+".ReadAlias", 0, "default").Store(&collection); err != nil {
+		return fmt.Errorf("failed to find the default keyring: %w", err)
+	}
+	if collection == "/" {
+		return errors.New("there is no default keyring")
+	}
+	if _, err := unlock(conn, service, []dbus.ObjectPath{collection}); err != nil {
+		return err
+	}
+
+	session, err := openSession(service)
+	if err != nil {
+		return err
+	}
+	defer conn.Object(serviceName, session).Call("org.freedesktop.Secret.Session.Close", 0)
+
+	properties := map[string]dbus.Variant{
+		itemInterface + ".Label":      dbus.MakeVariant("venaqui " + name),
+		itemInterface + ".Attributes": dbus.MakeVariant(attributes(name)),
+	}
+	secret := keyringSecret{Session: session, Parameters: []byte{}, Value: []byte(value), ContentType: "text/plain"}
+
+	var item, prompt dbus.ObjectPath
+	call := conn.Object(serviceName, collection).Call("org.freedesktop.Secret.Collection.CreateItem", 0, properties, secret, true)
+	if err := call.Store(&item, &prompt); err != nil {
+		return fmt.Errorf("failed to store secret in keyring: %w", err)
+	}
+	_, err = runPrompt(conn, prompt)
+	return err
+}
+
+// unlock unlocks objects, prompting the user if the keyring asks for it
+func unlock(conn *dbus.Conn, service dbus.BusObject, objects []dbus.ObjectPath) ([]dbus.ObjectPath, error) {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	if err := service.Call(serviceInterface+".Unlock", 0, objects).Store(&unlocked, &prompt); err != nil {
+		return nil, fmt.Errorf("failed to unlock keyring: %w", err)
+	}
+	result, err := runPrompt(conn, prompt)
+	if err != nil {
+		return nil, err
+	}
+	if paths, ok := result.Value().([]dbus.ObjectPath); ok {
+		unlocked = append(unlocked, paths...)
+	}
+	return unlocked, nil
+}
+
+// runPrompt shows a keyring prompt and waits for the user to answer it.
+// The path "/" means no prompt is needed.
+func runPrompt(conn *dbus.Conn, prompt dbus.ObjectPath) (dbus.Variant, error) {
+	if prompt == "/" || prompt == "" {
+		return dbus.Variant{}, nil
+	}
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(promptInterface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := conn.AddMatchSignal(match...); err != nil {
+		return dbus.Variant{}, fmt.Errorf("failed to watch keyring prompt: %w", err)
+	}
+	defer conn.RemoveMatchSignal(match...)
+	signals := make(chan *dbus.Signal, 1)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	if err := conn.Object(serviceName, prompt).Call(promptInterface+".Prompt", 0, "").Err; err != nil {
+		return dbus.Variant{}, fmt.Errorf("failed to show keyring prompt: %w", err)
+	}
+
+	timeout := time.After(promptTimeout)
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path != prompt || len(signal.Body) != 2 {
+				continue
+			}
+			if dismissed, _ := signal.Body[0].(bool); dismissed {
+				return dbus.Variant{}, errors.New("the keyring prompt was dismissed")
+			}
+			result, _ := signal.Body[1].(dbus.Variant)
+			return result, nil
+		case <-timeout:
+			return dbus.Variant{}, errors.New("timed out waiting for the keyring to be unlocked")
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Package secret stores the API token and other secrets outside the
+// configuration file, in the OS keyring or a passphrase-encrypted file, and
+// reads them from external commands such as password managers.
+package secret
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a store has no secret with the given name
+var ErrNotFound = errors.New("secret not found")
+
+// Store keeps named secrets
+type Store interface {
+	Get(name string) (string, error)
+	Set(name, value string) error
+}
+
+// commandTimeout bounds how long a secret command may take, leaving time
+// to unlock a password manager
+const commandTimeout = time.Minute
+
+// RunCommand runs a shell command such as "pass show rd" and returns the
+// first line it prints
+func RunCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%q failed: %w: %s", command, err, msg)
+		}
+		return "", fmt.Errorf("%q failed: %w", command, err)
+	}
+
+	// Password managers print the secret on the first line
+	line, _, _ := strings.Cut(string(out), "\n")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", fmt.Errorf("%q printed nothing", command)
+	}
+	return line, nil
+}
@@ -0,0 +1,284 @@
+package secret
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	asked := 0
+	passphrase := func() (string, error) {
+		asked++
+		return "correct horse", nil
+	}
+
+	store := NewFile(path, passphrase)
+	if _, err := store.Get("realdebrid.api_token"); !errors.Is(err, ErrNotFound) || asked != 0 {
+		t.Errorf("Get() on a missing file error = %v, want ErrNotFound without asking for the passphrase", err)
+	}
+	if err := store.Set("realdebrid.api_token", "ABC123"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if asked != 1 {
+		t.Errorf("passphrase asked %d times, want once", asked)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "ABC123") {
+		t.Error("secrets file contains the secret in plaintext")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("secrets file permissions = %o, want 600", info.Mode().Perm())
+	}
+
+	if got, err := NewFile(path, passphrase).Get("realdebrid.api_token"); err != nil || got != "ABC123" {
+		t.Errorf("Get() = %q, %v, want ABC123", got, err)
+	}
+
+	wrong := NewFile(path, func() (string, error) { return "battery staple", nil })
+	if _, err := wrong.Get("realdebrid.api_token"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() with the wrong passphrase error = %v, want a decryption error", err)
+	}
+}
+
+func TestPBKDF2(t *testing.T) {
+	// RFC 7914 section 11 test vectors
+	got := fmt.Sprintf("%x", pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64))
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got != want {
+		t.Errorf("pbkdf2SHA256() = %s, want %s", got, want)
+	}
+
+	got = fmt.Sprintf("%x", pbkdf2SHA256([]byte("Password"), []byte("NaCl"), 80000, 64))
+	want = "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"
+	if got != want {
+		t.Errorf("pbkdf2SHA256() with 80000 iterations = %s, want %s", got, want)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	if got, err := RunCommand("printf 'hunter2\\nurl: example.com\\n'"); err != nil || got != "hunter2" {
+		t.Errorf("RunCommand() = %q, %v, want hunter2", got, err)
+	}
+	if _, err := RunCommand("echo locked >&2; exit 1"); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("RunCommand() error = %v, want the command's stderr", err)
+	}
+	if _, err := RunCommand("true"); err == nil {
+		t.Error("RunCommand() of a command printing nothing should fail")
+	}
+}
+
+// fakeBusConfig configures a private dbus-daemon for tests
+const fakeBusConfig = `<busconfig>
+  <type>session</type>
+  <listen>unix:dir=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>`
+
+// startFakeSessionBus starts a private dbus-daemon and returns its address
+func startFakeSessionBus(t *testing.T) string {
+	t.Helper()
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(configFile, []byte(strings.Replace(fakeBusConfig, "%s", dir, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+configFile, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+// fakeSecretService keeps secrets in memory like GNOME Keyring. While
+// locked, unlocking goes through a prompt.
+type fakeSecretService struct {
+	conn    *dbus.Conn
+	locked  bool
+	prompts int
+	items   map[dbus.ObjectPath]*fakeItem
+}
+
+// fakeItem is a stored secret
+type fakeItem struct {
+	attributes map[string]string
+	value      []byte
+}
+
+// GetSecret implements org.freedesktop.Secret.Item.GetSecret
+func (i *fakeItem) GetSecret(session dbus.ObjectPath) (keyringSecret, *dbus.Error) {
+	return keyringSecret{Session: session, Parameters: []byte{}, Value: i.value, ContentType: "text/plain"}, nil
+}
+
+const fakeCollection = dbus.ObjectPath("/org/freedesktop/secrets/collection/login")
+
+// OpenSession implements org.freedesktop.Secret.Service.OpenSession
+func (s *fakeSecretService) OpenSession(algorithm string, input dbus.Variant) (dbus.Variant, dbus.ObjectPath, *dbus.Error) {
+	if algorithm != "plain" {
+		return dbus.Variant{}, "", dbus.MakeFailedError(errors.New("unsupported algorithm"))
+	}
+	return dbus.MakeVariant(""), "/org/freedesktop/secrets/session/1", nil
+}
+
+// SearchItems implements org.freedesktop.Secret.Service.SearchItems
+func (s *fakeSecretService) SearchItems(attributes map[string]string) ([]dbus.ObjectPath, []dbus.ObjectPath, *dbus.Error) {
+	var found []dbus.ObjectPath
+	for path, item := range s.items {
+		if fmt.Sprint(item.attributes) == fmt.Sprint(attributes) {
+			found = append(found, path)
+		}
+	}
+	if s.locked {
+		return []dbus.ObjectPath{}, found, nil
+	}
+	return found, []dbus.ObjectPath{}, nil
+}
+
+// Unlock implements org.freedesktop.Secret.Service.Unlock
+func (s *fakeSecretService) Unlock(objects []dbus.ObjectPath) ([]dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	if !s.locked {
+		return objects, "/", nil
+	}
+	return []dbus.ObjectPath{}, "/org/freedesktop/secrets/prompt/1", nil
+}
+
+// ReadAlias implements org.freedesktop.Secret.Service.ReadAlias
+func (s *fakeSecretService) ReadAlias(name string) (dbus.ObjectPath, *dbus.Error) {
+	return fakeCollection, nil
+}
+
+// fakePrompt unlocks the fake service when shown
+type fakePrompt struct {
+	service *fakeSecretService
+}
+
+// Prompt implements org.freedesktop.Secret.Prompt.Prompt
+func (p *fakePrompt) Prompt(windowID string) *dbus.Error {
+	p.service.locked = false
+	p.service.prompts++
+	var unlocked []dbus.ObjectPath
+	for path := range p.service.items {
+		unlocked = append(unlocked, path)
+	}
+	p.service.conn.Emit("/org/freedesktop/secrets/prompt/1", promptInterface+".Completed", false, dbus.MakeVariant(unlocked))
+	return nil
+}
+
+// fakeCollectionObject creates items in the fake service
+type fakeCollectionObject struct {
+	service *fakeSecretService
+}
+
+// CreateItem implements org.freedesktop.Secret.Collection.CreateItem
+func (c *fakeCollectionObject) CreateItem(properties map[string]dbus.Variant, secret keyringSecret, replace bool) (dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	attributes, _ := properties[itemInterface+".Attributes"].Value().(map[string]string)
+	s := c.service
+	for path, item := range s.items {
+		if replace && fmt.Sprint(item.attributes) == fmt.Sprint(attributes) {
+			item.value = secret.Value
+			return path, "/", nil
+		}
+	}
+
+	path := dbus.ObjectPath(fmt.Sprintf("%s/%d", fakeCollection, len(s.items)+1))
+	item := &fakeItem{attributes: attributes, value: secret.Value}
+	s.items[path] = item
+	if err := s.conn.Export(item, path, itemInterface); err != nil {
+		return "", "", dbus.MakeFailedError(err)
+	}
+	return path, "/", nil
+}
+
+func TestKeyring(t *testing.T) {
+	address := startFakeSessionBus(t)
+
+	server, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("server failed to connect: %v", err)
+	}
+	defer server.Close()
+
+	fake := &fakeSecretService{conn: server, items: map[dbus.ObjectPath]*fakeItem{}}
+	server.Export(fake, servicePath, serviceInterface)
+	server.Export(&fakeCollectionObject{service: fake}, fakeCollection, "org.freedesktop.Secret.Collection")
+	server.Export(&fakePrompt{service: fake}, "/org/freedesktop/secrets/prompt/1", promptInterface)
+	if reply, err := server.RequestName(serviceName, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("failed to own name: %v (reply %v)", err, reply)
+	}
+
+	client, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("client failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	keyring := NewKeyring(client)
+	if _, err := keyring.Get("realdebrid.api_token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a missing secret error = %v, want ErrNotFound", err)
+	}
+	if err := keyring.Set("realdebrid.api_token", "ABC123"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := keyring.Set("realdebrid.api_token", "DEF456"); err != nil {
+		t.Fatalf("Set() replacing error = %v", err)
+	}
+	if len(fake.items) != 1 {
+		t.Errorf("keyring holds %d items, want the secret replaced", len(fake.items))
+	}
+
+	// A locked keyring is unlocked through a prompt
+	fake.locked = true
+	if got, err := keyring.Get("realdebrid.api_token"); err != nil || got != "DEF456" {
+		t.Errorf("Get() = %q, %v, want DEF456", got, err)
+	}
+	if fake.prompts != 1 {
+		t.Errorf("prompted %d times, want once", fake.prompts)
+	}
+
+	// Without a Secret Service on the bus, the keyring is unavailable
+	server.ReleaseName(serviceName)
+	if _, err := keyring.Get("realdebrid.api_token"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() without a Secret Service error = %v, want a bus error", err)
+	}
+}
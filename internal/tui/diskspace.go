@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mhrsntrk/venaqui/internal/diskspace"
+)
+
+// diskCheckInterval is how often free space is checked during a download
+const diskCheckInterval = 5 * time.Second
+
+// diskSpaceMsg carries the result of a free space check
+type diskSpaceMsg struct {
+	event *diskspace.Event
+	err   error
+}
+
+// checkDiskSpace schedules the next free space check
+func (m Model) checkDiskSpace() tea.Cmd {
+	if m.diskGuard == nil {
+		return nil
+	}
+
+	guard := m.diskGuard
+	return tea.Tick(diskCheckInterval, func(time.Time) tea.Msg {
+		event, err := guard.Poll()
+		return diskSpaceMsg{event: event, err: err}
+	})
+}
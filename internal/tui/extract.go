@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mhrsntrk/venaqui/internal/extract"
+)
+
+// extractState tracks post-download archive extraction
+type extractState struct {
+	set      *extract.Set
+	waiting  []string // Parts that are missing or still downloading
+	skipped  bool     // Parts are missing that aria2 is not downloading
+	running  bool
+	progress extract.Progress
+	dest     string
+	err      error
+	done     bool
+	events   chan tea.Msg
+	cancel   context.CancelFunc
+}
+
+// extractCheckMsg carries the archive set of a completed download and its missing parts
+type extractCheckMsg struct {
+	set         *extract.Set
+	missing     []string
+	downloading bool // aria2 is still downloading parts of the set
+	err         error
+}
+
+// extractRecheckMsg asks to check an archive set for missing parts again
+type extractRecheckMsg struct{}
+
+// extractProgressMsg carries extraction progress
+type extractProgressMsg extract.Progress
+
+// extractDoneMsg is sent when extraction finishes
+type extractDoneMsg struct {
+	dest string
+	err  error
+}
+
+// checkArchive detects the archive set of the completed download and
+// reports which parts are still missing and whether aria2 is downloading any
+func (m Model) checkArchive() tea.Msg {
+	set, err := extract.Detect(m.status.GetFilePath())
+	if err != nil || set == nil {
+		return extractCheckMsg{err: err}
+	}
+
+	pending, err := m.aria2Client.GetPendingFiles()
+	if err != nil {
+		return extractCheckMsg{err: err}
+	}
+	msg := extractCheckMsg{set: set, missing: set.Missing(pending)}
+	for _, p := range pending {
+		if set.Contains(p) {
+			msg.downloading = true
+		}
+	}
+	return msg
+}
+
+// recheckArchive schedules another check while parts are still downloading
+func recheckArchive() tea.Cmd {
+	return tea.Tick(2*time.Second, func(time.Time) tea.Msg {
+		return extractRecheckMsg{}
+	})
+}
+
+// startExtraction runs the extractor in the background, streaming progress to events
+func startExtraction(extractor *extract.Extractor, set *extract.Set) (chan tea.Msg, context.CancelFunc) {
+	events := make(chan tea.Msg, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		dest, err := extractor.Extract(ctx, set, func(p extract.Progress) {
+			select {
+			case events <- extractProgressMsg(p):
+			default:
+				// Drop progress updates the UI has not caught up with
+			}
+		})
+		events <- extractDoneMsg{dest: dest, err: err}
+	}()
+
+	return events, cancel
+}
+
+// waitForExtraction waits for the next extraction event
+func waitForExtraction(events chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
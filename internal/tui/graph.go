@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	// yAxisWidth is the room left of the plot for the speed labels and the axis
+	yAxisWidth = 10
+
+	// graphChromeRows are the rows of the graph around the plot: the title,
+	// the X axis, its labels and the legend
+	graphChromeRows = 4
+)
+
+// blocks are the partly filled cells of a bar, in eighths
+var blocks = []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// brailleRows are the bits of the braille dots in a cell's four rows, top
+// first, with both columns set
+var brailleRows = []rune{0x01 | 0x08, 0x02 | 0x10, 0x04 | 0x20, 0x40 | 0x80}
+
+// graphColumns averages the samples between start and end into n columns.
+// Columns without samples repeat the one before, and are -1 before the
+// first sample.
+func graphColumns(samples []speedSample, start, end time.Time, n int) []int64 {
+	columns := make([]int64, n)
+	span := end.Sub(start)
+	if span <= 0 {
+		span = time.Second
+	}
+
+	sums := make([]int64, n)
+	counts := make([]int, n)
+	for _, s := range samples {
+		if s.at.Before(start) {
+			continue
+		}
+		i := int(int64(s.at.Sub(start)) * int64(n) / int64(span))
+		if i >= n {
+			i = n - 1
+		}
+		sums[i] += s.speed
+		counts[i]++
+	}
+
+	last := int64(-1)
+	for i := range columns {
+		if counts[i] > 0 {
+			last = sums[i] / int64(counts[i])
+		}
+		columns[i] = last
+	}
+	return columns
+}
+
+// movingAverage returns the trailing mean of each column over the given
+// number of columns, skipping columns without data
+func movingAverage(columns []int64, over int) []int64 {
+	averages := make([]int64, len(columns))
+	for i := range columns {
+		var sum, n int64
+		for j := max(0, i-over+1); j <= i; j++ {
+			if columns[j] >= 0 {
+				sum += columns[j]
+				n++
+			}
+		}
+		averages[i] = -1
+		if n > 0 {
+			averages[i] = sum / n
+		}
+	}
+	return averages
+}
+
+// renderSpeedGraph renders the speed of the selected window as bars with a
+// labelled speed axis and a time axis, the moving average in braille and
+// the peak marked. width includes the speed labels; height is the number
+// of rows of bars.
+func (m Model) renderSpeedGraph(width, height int) string {
+	window := graphWindows[m.graphWindow]
+	now := m.lastUpdate
+	start := now.Add(-window.duration)
+	if window.duration == 0 {
+		start = m.startTime
+	}
+	samples := m.speed.window(now, window.duration)
+
+	plotWidth := max(width-yAxisWidth, 1)
+	columns := graphColumns(samples, start, now, plotWidth)
+	averages := movingAverage(columns, max(3, plotWidth/8))
+
+	// Scale to the fastest column of the window
+	var maxSpeed int64
+	peakColumn := -1
+	for i, speed := range columns {
+		if speed > maxSpeed {
+			maxSpeed, peakColumn = speed, i
+		}
+	}
+	maxSpeed = max(maxSpeed, 1)
+
+	var s strings.Builder
+	s.WriteString(m.renderGraphTitle())
+	s.WriteString("\n")
+
+	for row := 0; row < height; row++ {
+		level := height - 1 - row // Rows from the bottom
+		s.WriteString(graphAxisStyle.Render(yAxisLabel(row, height, maxSpeed)))
+		for i, speed := range columns {
+			s.WriteString(graphCell(speed, averages[i], maxSpeed, level, height, i == peakColumn))
+		}
+		s.WriteString("\n")
+	}
+
+	// Time axis, with the peak marked under its column
+	axis := []rune(strings.Repeat("─", plotWidth))
+	if peakColumn >= 0 {
+		axis[peakColumn] = '▲'
+	}
+	s.WriteString(graphAxisStyle.Render(strings.Repeat(" ", yAxisWidth-1) + "└" + string(axis)))
+	s.WriteString("\n")
+	s.WriteString(graphAxisStyle.Render(strings.Repeat(" ", yAxisWidth) + timeAxisLabels(now.Sub(start), plotWidth)))
+	s.WriteString("\n")
+	s.WriteString(renderGraphLegend(samples, maxSpeed, peakColumn >= 0))
+
+	return s.String()
+}
+
+// graphCell renders one cell of a column: a full or partial block of the
+// bar, or a braille dot of the moving average above it
+func graphCell(speed, average, maxSpeed int64, level, height int, peak bool) string {
+	style := graphStyle
+	if peak {
+		style = graphPeakStyle
+	}
+
+	fill := -1
+	if speed >= 0 {
+		fill = int(speed*int64(height*8)/maxSpeed) - level*8
+	}
+	averageRow := -1 // Dot row of the average in this cell, top first
+	if average >= 0 {
+		dots := min(int(average*int64(height*4)/maxSpeed), height*4-1)
+		if dots/4 == level {
+			averageRow = 3 - dots%4
+		}
+	}
+
+	switch {
+	case fill >= 8:
+		if averageRow >= 0 {
+			return graphAverageStyle.Render(blocks[8])
+		}
+		return style.Render(blocks[8])
+	case fill > 0:
+		if averageRow >= 0 {
+			return graphAverageStyle.Render(blocks[fill])
+		}
+		return style.Render(blocks[fill])
+	case averageRow >= 0:
+		return graphAverageStyle.Render(string(0x2800 + brailleRows[averageRow]))
+	}
+	return " "
+}
+
+// yAxisLabel returns the speed label and axis of a row: the top, middle
+// and bottom rows are labelled with the speed at their top
+func yAxisLabel(row, height int, maxSpeed int64) string {
+	var label string
+	switch {
+	case row == height-1 && row > 0:
+		label = "0 B/s"
+	case row == 0 || (height >= 5 && row == height/2):
+		label = humanize.Bytes(uint64(maxSpeed*int64(height-row)/int64(height))) + "/s"
+	default:
+		return strings.Repeat(" ", yAxisWidth-1) + "│"
+	}
+	return fmt.Sprintf("%*s ┤", yAxisWidth-2, label)
+}
+
+// timeAxisLabels labels the start, middle and end of the time axis, in
+// seconds before now
+func timeAxisLabels(span time.Duration, width int) string {
+	left := "-" + axisDuration(span)
+	middle := "-" + axisDuration(span/2)
+	right := "now"
+
+	line := []rune(strings.Repeat(" ", width))
+	place := func(at int, label string) {
+		for i, r := range label {
+			if at+i >= 0 && at+i < len(line) {
+				line[at+i] = r
+			}
+		}
+	}
+	place(0, left)
+	if width >= 3*(len(left)+2) {
+		place(width/2-len(middle)/2, middle)
+	}
+	place(width-len(right), right)
+	return string(line)
+}
+
+// axisDuration formats a time axis label: seconds up to five minutes,
+// then minutes and hours
+func axisDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d <= 5*time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// renderGraphTitle renders the graph's label and the windows it can show
+func (m Model) renderGraphTitle() string {
+	names := make([]string, len(graphWindows))
+	for i, window := range graphWindows {
+		if i == m.graphWindow {
+			names[i] = statValueStyle.Render("[" + window.name + "]")
+		} else {
+			names[i] = helpStyle.Render(window.name)
+		}
+	}
+	return statLabelStyle.Copy().UnsetWidth().Render("Speed History") + "  " +
+		strings.Join(names, " ") + helpStyle.Render("  'w' to change")
+}
+
+// renderGraphLegend renders the average, peak and stalled time of the samples
+func renderGraphLegend(samples []speedSample, peak int64, hasPeak bool) string {
+	var sum int64
+	var stalled time.Duration
+	for i, s := range samples {
+		sum += s.speed
+		if s.speed == 0 && i > 0 {
+			stalled += s.at.Sub(samples[i-1].at)
+		}
+	}
+
+	parts := []string{}
+	if len(samples) > 0 {
+		parts = append(parts, graphAverageStyle.Render("⠒")+" avg "+statValueStyle.Render(humanize.Bytes(uint64(sum/int64(len(samples))))+"/s"))
+	}
+	if hasPeak {
+		parts = append(parts, graphPeakStyle.Render("▲")+" peak "+statValueStyle.Render(humanize.Bytes(uint64(peak))+"/s"))
+	}
+	if stalled > 0 {
+		parts = append(parts, statusWarningStyle.Render("stalled "+formatDuration(stalled)))
+	}
+	return strings.Join(parts, "   ")
+}
@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mhrsntrk/venaqui/internal/hooks"
+)
+
+// hookState tracks the on_complete hooks
+type hookState struct {
+	started bool
+	results []hooks.Result
+	done    bool
+}
+
+// hooksDoneMsg carries the results of the on_complete hooks
+type hooksDoneMsg []hooks.Result
+
+// runCompleteHooks starts the on_complete hooks in the background once the
+// download, verification and any extraction have finished
+func (m Model) runCompleteHooks() (Model, tea.Cmd) {
+	if !m.hooks.Has(hooks.EventComplete) || m.hook.started {
+		return m, nil
+	}
+	m.hook.started = true
+
+	runner := m.hooks
+	vars := m.hookVars.WithStatus(m.status)
+	vars.Filename = m.filename
+	vars.Duration = m.completionTime.Sub(m.startTime)
+	return m, func() tea.Msg {
+		return hooksDoneMsg(runner.Run(context.Background(), hooks.EventComplete, vars))
+	}
+}
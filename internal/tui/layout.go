@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	// Below this size the TUI shrinks to a single status line
+	compactWidth  = 50
+	compactHeight = 12
+
+	// Terminals shorter than this get boxes without vertical padding
+	roomyHeight = 45
+
+	// Sizes used until the terminal reports its size
+	defaultBarWidth    = 60
+	defaultGraphHeight = 8
+
+	minGraphHeight = 3
+	maxGraphHeight = 12
+
+	columnGap = 4
+)
+
+// compact reports whether the terminal is too small for the boxes
+func (m Model) compact() bool {
+	return m.width > 0 && (m.width < compactWidth || m.height < compactHeight)
+}
+
+// box returns the box style fitted to the terminal's width, and without
+// vertical padding on short terminals
+func (m Model) box() lipgloss.Style {
+	style := boxStyle.Copy()
+	if m.width == 0 {
+		return style
+	}
+	if m.height < roomyHeight {
+		style = style.Padding(0, 2)
+	}
+	return style.Width(m.width - style.GetHorizontalMargins() - style.GetHorizontalBorderSize())
+}
+
+// contentWidth returns the width available inside a box
+func (m Model) contentWidth() int {
+	if m.width == 0 {
+		return defaultBarWidth
+	}
+	style := m.box()
+	return style.GetWidth() - style.GetHorizontalPadding()
+}
+
+// barWidth returns the width of the progress bar
+func (m Model) barWidth() int {
+	return max(m.contentWidth(), 10)
+}
+
+// graphWidth returns the width of the speed graph, its axis included
+func (m Model) graphWidth() int {
+	if m.width == 0 {
+		return defaultBarWidth + yAxisWidth
+	}
+	return max(m.contentWidth(), yAxisWidth+10)
+}
+
+// graphHeight returns how many rows the speed graph can have below the
+// rest of the view, whose height is used, or 0 when it doesn't fit
+func (m Model) graphHeight(used int) int {
+	if m.height == 0 {
+		return defaultGraphHeight
+	}
+	// The graph's box, title, axis and legend and the blank line after it
+	chrome := m.box().GetVerticalFrameSize() + graphChromeRows + 1
+	rows := m.height - used - chrome
+	if rows < minGraphHeight {
+		return 0
+	}
+	return min(rows, maxGraphHeight)
+}
+
+// columns places two columns of statistics side by side, or one above the
+// other when they don't fit the box
+func (m Model) columns(left, right string) string {
+	left, right = strings.TrimSuffix(left, "\n"), strings.TrimSuffix(right, "\n")
+	if lipgloss.Width(left)+columnGap+lipgloss.Width(right) > m.contentWidth() {
+		return lipgloss.JoinVertical(lipgloss.Left, left, right)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Left, left, strings.Repeat(" ", columnGap), right)
+}
+
+// renderCompact renders the download on a single line, cut to the
+// terminal's width
+func (m Model) renderCompact() string {
+	var line string
+	switch {
+	case m.status == nil:
+		line = "Initializing download..."
+	case m.status.IsComplete():
+		line = successStyle.Copy().UnsetPadding().Render("✓ Complete") + " " + filenameStyle.Render(m.filename) + " " + helpStyle.Render("(q to quit)")
+	default:
+		parts := []string{
+			m.getStatusStyle().Render(m.getStatusText()),
+			statValueStyle.Render(fmt.Sprintf("%.1f%%", m.status.GetProgress())),
+			statValueHighlightStyle.Render(humanize.Bytes(uint64(m.status.DownloadSpeed)) + "/s"),
+		}
+		if eta := m.eta.Estimate(m.status); eta.Known && eta.Expected > 0 {
+			parts = append(parts, "ETA "+formatDuration(eta.Expected))
+		}
+		if m.scheduleNotice != "" {
+			parts = append(parts, statusWarningStyle.Render(m.scheduleNotice))
+		}
+		if m.diskNotice != "" {
+			parts = append(parts, statusErrorStyle.Render(m.diskNotice))
+		}
+		line = strings.Join(append(parts, filenameStyle.Render(m.filename)), " ")
+	}
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(line) + "\n"
+}
+
+// renderGraphBox renders the speed graph in a box with the given number of rows
+func (m Model) renderGraphBox(height int) string {
+	return m.box().Render(m.renderSpeedGraph(m.graphWidth(), height))
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+)
+
+// sizedModel returns a model of an active download in a terminal of the given size
+func sizedModel(width, height int) Model {
+	m := InitialModel(nil, "gid", "A.Rather.Long.Release.Name.2160p.WEB-DL.DDP5.1.Atmos.mkv")
+	m.status = &aria2.DownloadStatus{Status: "active", TotalLength: 4 << 30, CompletedLength: 1 << 30, DownloadSpeed: 12 << 20, Connections: 16}
+	for i, speed := range []int64{4 << 20, 0, 8 << 20, 12 << 20} {
+		m.speed.add(m.startTime.Add(time.Duration(i+1)*time.Second), speed)
+	}
+	m.lastUpdate = m.startTime.Add(5 * time.Second)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return updated.(Model)
+}
+
+func TestView_FitsTerminal(t *testing.T) {
+	tests := []struct {
+		width, height int
+		compact       bool
+	}{
+		{160, 60, false},
+		{80, 40, false},
+		{60, 24, false},
+		{40, 30, true},
+		{100, 10, true},
+	}
+
+	for _, tt := range tests {
+		m := sizedModel(tt.width, tt.height)
+		if got := m.compact(); got != tt.compact {
+			t.Errorf("compact() at %dx%d = %v, want %v", tt.width, tt.height, got, tt.compact)
+		}
+
+		view := m.View()
+		lines := strings.Split(strings.TrimSuffix(view, "\n"), "\n")
+		if len(lines) > tt.height {
+			t.Errorf("View() at %dx%d has %d lines", tt.width, tt.height, len(lines))
+		}
+		for _, line := range lines {
+			if w := lipgloss.Width(line); w > tt.width {
+				t.Errorf("View() at %dx%d has a line of width %d: %q", tt.width, tt.height, w, line)
+				break
+			}
+		}
+		if tt.compact && len(lines) != 1 {
+			t.Errorf("View() at %dx%d = %d lines, want a single line", tt.width, tt.height, len(lines))
+		}
+	}
+}
+
+func TestGraphHeight(t *testing.T) {
+	m := sizedModel(100, 60)
+	if got := m.graphHeight(20); got != maxGraphHeight {
+		t.Errorf("graphHeight(20) at 100x60 = %d, want %d", got, maxGraphHeight)
+	}
+	if got := m.graphHeight(55); got != 0 {
+		t.Errorf("graphHeight(55) at 100x60 = %d, want 0", got)
+	}
+	// Before the terminal reports its size
+	if got := InitialModel(nil, "gid", "file").graphHeight(100); got != defaultGraphHeight {
+		t.Errorf("graphHeight() without a size = %d, want %d", got, defaultGraphHeight)
+	}
+}
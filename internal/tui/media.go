@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mhrsntrk/venaqui/internal/media"
+)
+
+// mediaState tracks the description of a video download
+type mediaState struct {
+	info    *media.Info // From the provider, or ffprobe after completion
+	probe   bool        // Describe the file with ffprobe if info is missing
+	probing bool
+}
+
+// mediaMsg carries the result of describing the downloaded file
+type mediaMsg struct {
+	info *media.Info
+	err  error
+}
+
+// probeMedia describes the completed video with ffprobe when the provider
+// did not describe it
+func (m Model) probeMedia() (Model, tea.Cmd) {
+	if m.media.info != nil || !m.media.probe || m.media.probing {
+		return m, nil
+	}
+	m.media.probing = true
+
+	path := m.status.GetFilePath()
+	return m, func() tea.Msg {
+		info, err := media.Probe(context.Background(), path)
+		return mediaMsg{info: info, err: err}
+	}
+}
+
+// renderMedia renders the video's tracks, one line each, or an empty string
+// if the download is not a described video
+func (m Model) renderMedia() string {
+	info := m.media.info
+	if info == nil {
+		if m.media.probing {
+			return statLabelStyle.Render("Video:") + " " + helpStyle.Render("Reading tracks...")
+		}
+		return ""
+	}
+
+	lines := fmt.Sprintf("%s %s", statLabelStyle.Render("Video:"), statValueStyle.Render(info.Summary()))
+	if audio := info.AudioSummary(); audio != "" {
+		lines += fmt.Sprintf("\n%s %s", statLabelStyle.Render("Audio:"), statValueStyle.Render(audio))
+	}
+	if subtitles := info.SubtitleSummary(); subtitles != "" {
+		lines += fmt.Sprintf("\n%s %s", statLabelStyle.Render("Subtitles:"), statValueStyle.Render(subtitles))
+	}
+	return lines
+}
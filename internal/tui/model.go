@@ -5,21 +5,75 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/diskspace"
+	"github.com/mhrsntrk/venaqui/internal/extract"
+	"github.com/mhrsntrk/venaqui/internal/hooks"
+	"github.com/mhrsntrk/venaqui/internal/media"
+	"github.com/mhrsntrk/venaqui/internal/notify"
+	"github.com/mhrsntrk/venaqui/internal/schedule"
+	"github.com/mhrsntrk/venaqui/internal/verify"
 )
 
 // Model represents the TUI application state
 type Model struct {
-	aria2Client  *aria2.Client
-	gid          string
-	status       *aria2.DownloadStatus
-	filename     string
-	err          error
-	quitting     bool
-	startTime    time.Time
+	aria2Client    *aria2.Client
+	gid            string
+	status         *aria2.DownloadStatus
+	filename       string
+	err            error
+	quitting       bool
+	startTime      time.Time
 	completionTime time.Time // Time when download completed
-	lastUpdate   time.Time
-	speedHistory []int64 // Speed history for graph (last 50 samples)
-	maxHistory   int
+	lastUpdate     time.Time
+	speed          *speedHistory // Speed history for graph
+	eta            *aria2.ETAEstimator
+	graphWindow    int // Index in graphWindows of the span the graph shows
+
+	width, height int // Terminal size, 0 until the terminal reports it
+
+	themes []Theme // Themes cycled through with 't'
+	theme  int     // Index of the theme in use
+
+	checksum      *verify.Checksum      // Expected checksum from --checksum, if any
+	downloadOpts  aria2.DownloadOptions // Options used when re-downloading a corrupted file
+	verifying     bool
+	verification  *verify.Result
+	verifyErr     error
+	redownloads   int  // Number of re-downloads after a checksum mismatch
+	redownloading bool // Set while a re-download is being added to aria2
+
+	extractor *extract.Extractor // Nil when extraction is disabled
+	extract   extractState
+
+	hooks    *hooks.Runner // Nil when no hooks are configured
+	hookVars hooks.Vars    // Variables known before the download started
+	hook     hookState
+
+	notifications *notify.Notifications // Nil when notifications are disabled
+
+	diskGuard  *diskspace.Guard // Nil when free space is not watched
+	diskNotice string           // Last message from the disk space guard
+
+	schedule       *schedule.Enforcer // Nil when downloads are not scheduled
+	scheduleNotice string             // Set while the schedule holds the download
+
+	media mediaState
+}
+
+// Options configures optional TUI behaviour
+type Options struct {
+	Checksum        *verify.Checksum
+	DownloadOptions aria2.DownloadOptions
+	Extractor       *extract.Extractor // Extract archive sets after completion, if set
+	Hooks           *hooks.Runner      // Run on_complete hooks after completion, if set
+	HookVars        hooks.Vars
+	Notifications   *notify.Notifications // Notify on completion and failure, if set
+	DiskGuard       *diskspace.Guard      // Pause downloads when free space runs low, if set
+	Schedule        *schedule.Enforcer    // Hold the download outside its schedule, if set
+	Media           *media.Info           // The video's tracks as described by the provider, if known
+	ProbeMedia      bool                  // Describe the video with ffprobe after completion if Media is nil
+	Themes          []Theme               // Themes to cycle through, see LoadThemes
+	Theme           int                   // Index of the theme to start with
 }
 
 // tickMsg is sent periodically to update the UI
@@ -31,25 +85,80 @@ type statusMsg *aria2.DownloadStatus
 // errMsg wraps an error
 type errMsg error
 
+// verifyMsg carries the result of verifying a completed download
+type verifyMsg struct {
+	result *verify.Result
+	err    error
+}
+
+// redownloadMsg carries the GID of a re-added download
+type redownloadMsg string
+
 // InitialModel creates a new model with initial state
 func InitialModel(aria2Client *aria2.Client, gid, filename string) Model {
+	return InitialModelWithOptions(aria2Client, gid, filename, Options{})
+}
+
+// InitialModelWithOptions creates a new model with initial state and options
+func InitialModelWithOptions(aria2Client *aria2.Client, gid, filename string, opts Options) Model {
 	now := time.Now()
+	if opts.Theme >= 0 && opts.Theme < len(opts.Themes) {
+		applyTheme(opts.Themes[opts.Theme])
+	}
+	scheduleNotice := ""
+	if opts.Schedule != nil && !opts.Schedule.Open(now) {
+		scheduleNotice = opts.Schedule.Status(now)
+	}
 	return Model{
-		aria2Client:  aria2Client,
-		gid:          gid,
-		filename:     filename,
-		startTime:    now,
-		lastUpdate:   now,
-		speedHistory: make([]int64, 0),
-		maxHistory:   50,
+		aria2Client:    aria2Client,
+		gid:            gid,
+		filename:       filename,
+		startTime:      now,
+		lastUpdate:     now,
+		speed:          newSpeedHistory(),
+		eta:            aria2.NewETAEstimator(),
+		checksum:       opts.Checksum,
+		downloadOpts:   opts.DownloadOptions,
+		extractor:      opts.Extractor,
+		hooks:          opts.Hooks,
+		hookVars:       opts.HookVars,
+		notifications:  opts.Notifications,
+		diskGuard:      opts.DiskGuard,
+		schedule:       opts.Schedule,
+		scheduleNotice: scheduleNotice,
+		media:          mediaState{info: opts.Media, probe: opts.ProbeMedia},
+		themes:         opts.Themes,
+		theme:          opts.Theme,
 	}
 }
 
+// Err returns the error that ended the session, if any
+func (m Model) Err() error {
+	return m.err
+}
+
+// Status returns the last known download status
+func (m Model) Status() *aria2.DownloadStatus {
+	return m.status
+}
+
+// Verification returns the checksum verification result, if the file was verified
+func (m Model) Verification() *verify.Result {
+	return m.verification
+}
+
+// Media returns the video's tracks, if the download is a described video
+func (m Model) Media() *media.Info {
+	return m.media.info
+}
+
 // Init initializes the model and returns initial commands
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		tickCmd(),
 		m.fetchStatus,
+		m.checkDiskSpace(),
+		m.checkSchedule(),
 	)
 }
 
@@ -68,3 +177,18 @@ func (m Model) fetchStatus() tea.Msg {
 	}
 	return statusMsg(status)
 }
+
+// verifyFile verifies the completed download against the expected checksum or a sidecar file
+func (m Model) verifyFile() tea.Msg {
+	result, err := verify.Check(m.status.GetFilePath(), m.checksum)
+	return verifyMsg{result: result, err: err}
+}
+
+// redownload adds the current download again, overwriting the corrupted file
+func (m Model) redownload() tea.Msg {
+	gid, err := m.aria2Client.Redownload(m.status, m.downloadOpts)
+	if err != nil {
+		return errMsg(err)
+	}
+	return redownloadMsg(gid)
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mhrsntrk/venaqui/internal/notify"
+)
+
+// notify sends a notification in the background
+func (m Model) notify(event notify.Event, title, body string) tea.Cmd {
+	if m.notifications == nil {
+		return nil
+	}
+
+	notifications := m.notifications
+	return func() tea.Msg {
+		// Failures already fell back to the bell; nothing else to do
+		notifications.SendEvent(event, title, body)
+		return nil
+	}
+}
+
+// notifyError sends the notification for a download that failed
+func (m Model) notifyError(err error) tea.Cmd {
+	event := notify.EventError
+	if m.status != nil && m.status.IsChecksumError() {
+		event = notify.EventRetryExhausted
+	}
+	return m.notify(event, "Download failed", fmt.Sprintf("%s: %s", m.filename, strings.TrimSpace(err.Error())))
+}
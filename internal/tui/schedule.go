@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mhrsntrk/venaqui/internal/schedule"
+)
+
+// scheduleCheckInterval is how often the download schedule is applied
+const scheduleCheckInterval = 10 * time.Second
+
+// scheduleMsg carries the result of applying the download schedule
+type scheduleMsg struct {
+	event *schedule.Event
+}
+
+// checkSchedule schedules the next check of the download schedule
+func (m Model) checkSchedule() tea.Cmd {
+	if m.schedule == nil {
+		return nil
+	}
+
+	enforcer := m.schedule
+	return tea.Tick(scheduleCheckInterval, func(t time.Time) tea.Msg {
+		return scheduleMsg{event: enforcer.Poll(t)}
+	})
+}
@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"time"
+)
+
+// speedSample is a download speed at a point in time
+type speedSample struct {
+	at    time.Time
+	speed int64 // Bytes per second
+}
+
+// speedRing keeps the latest samples, overwriting the oldest once full
+type speedRing struct {
+	samples []speedSample
+	next    int // Index the next sample is written to
+	full    bool
+}
+
+// newSpeedRing creates a ring holding up to size samples
+func newSpeedRing(size int) *speedRing {
+	return &speedRing{samples: make([]speedSample, size)}
+}
+
+// add records a sample
+func (r *speedRing) add(s speedSample) {
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// since returns the samples taken after t, oldest first
+func (r *speedRing) since(t time.Time) []speedSample {
+	ordered := r.samples[:r.next]
+	if r.full {
+		ordered = append(append([]speedSample{}, r.samples[r.next:]...), r.samples[:r.next]...)
+	}
+	for i, s := range ordered {
+		if s.at.After(t) {
+			return ordered[i:]
+		}
+	}
+	return nil
+}
+
+const (
+	// recentSamples holds the samples shown in the 1m and 5m windows, at
+	// one sample a second with room to spare
+	recentSamples = 360
+
+	// maxOverviewSamples is how many averaged samples cover the whole
+	// download; adjacent ones are merged once there are more
+	maxOverviewSamples = 240
+)
+
+// speedHistory records the download speed, stalls included. Recent samples
+// are kept as they are; the whole download is kept as averages over a
+// span that doubles whenever they run out of room.
+type speedHistory struct {
+	recent *speedRing
+
+	overview []speedSample // Averages of per samples each, oldest first
+	per      int
+	pending  speedSample // Running sum of the samples not averaged yet
+	pendingN int
+
+	peak speedSample
+}
+
+// newSpeedHistory creates an empty history
+func newSpeedHistory() *speedHistory {
+	return &speedHistory{recent: newSpeedRing(recentSamples), per: 1}
+}
+
+// add records the speed at a point in time
+func (h *speedHistory) add(at time.Time, speed int64) {
+	s := speedSample{at: at, speed: speed}
+	h.recent.add(s)
+	if speed > h.peak.speed {
+		h.peak = s
+	}
+
+	h.pending.at = at
+	h.pending.speed += speed
+	h.pendingN++
+	if h.pendingN < h.per {
+		return
+	}
+	h.overview = append(h.overview, speedSample{at: at, speed: h.pending.speed / int64(h.pendingN)})
+	h.pending, h.pendingN = speedSample{}, 0
+
+	// Halve the resolution once full
+	if len(h.overview) >= maxOverviewSamples {
+		merged := h.overview[:0]
+		for i := 0; i+1 < len(h.overview); i += 2 {
+			merged = append(merged, speedSample{at: h.overview[i+1].at, speed: (h.overview[i].speed + h.overview[i+1].speed) / 2})
+		}
+		h.overview = merged
+		h.per *= 2
+	}
+}
+
+// empty reports whether nothing was recorded yet
+func (h *speedHistory) empty() bool {
+	return h == nil || (len(h.overview) == 0 && h.pendingN == 0)
+}
+
+// window returns the samples of the last d, or of the whole download when
+// d is 0, oldest first
+func (h *speedHistory) window(now time.Time, d time.Duration) []speedSample {
+	if d > 0 {
+		return h.recent.since(now.Add(-d))
+	}
+	samples := append([]speedSample{}, h.overview...)
+	if h.pendingN > 0 {
+		samples = append(samples, speedSample{at: h.pending.at, speed: h.pending.speed / int64(h.pendingN)})
+	}
+	return samples
+}
+
+// graphWindow is a span of time the speed graph can show
+type graphWindow struct {
+	name     string
+	duration time.Duration // 0 for the whole download
+}
+
+// graphWindows are cycled through with 'w'
+var graphWindows = []graphWindow{
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"all", 0},
+}
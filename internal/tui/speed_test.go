@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpeedRing(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	ring := newSpeedRing(4)
+	for i := 1; i <= 6; i++ {
+		ring.add(speedSample{at: start.Add(time.Duration(i) * time.Second), speed: int64(i)})
+	}
+
+	// The two oldest samples were overwritten
+	got := ring.since(start)
+	if len(got) != 4 || got[0].speed != 3 || got[3].speed != 6 {
+		t.Errorf("since(start) = %+v, want samples 3 to 6", got)
+	}
+	if got := ring.since(start.Add(5 * time.Second)); len(got) != 1 || got[0].speed != 6 {
+		t.Errorf("since(5s) = %+v, want sample 6", got)
+	}
+	if got := ring.since(start.Add(time.Minute)); len(got) != 0 {
+		t.Errorf("since(1m) = %+v, want none", got)
+	}
+}
+
+func TestSpeedHistory(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	h := newSpeedHistory()
+	if !h.empty() {
+		t.Error("empty() = false for a new history")
+	}
+
+	// Long enough to be downsampled twice
+	n := 3*maxOverviewSamples + 1
+	for i := 0; i < n; i++ {
+		speed := int64(100)
+		if i%2 == 1 {
+			speed = 0 // Stalls are kept
+		}
+		h.add(start.Add(time.Duration(i)*time.Second), speed)
+	}
+	h.add(start.Add(time.Duration(n)*time.Second), 500)
+	now := start.Add(time.Duration(n) * time.Second)
+
+	all := h.window(now, 0)
+	if len(all) >= maxOverviewSamples || h.per != 4 {
+		t.Errorf("window(all) has %d samples of %d each, want fewer than %d of 4", len(all), h.per, maxOverviewSamples)
+	}
+	if all[0].speed != 50 {
+		t.Errorf("window(all)[0].speed = %d, want the average 50", all[0].speed)
+	}
+	if recent := h.window(now, time.Minute); len(recent) != 60 || recent[len(recent)-1].speed != 500 {
+		t.Errorf("window(1m) = %d samples, want the last 60", len(recent))
+	}
+	if h.peak.speed != 500 {
+		t.Errorf("peak = %d, want 500", h.peak.speed)
+	}
+}
+
+func TestGraphColumns(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	samples := []speedSample{
+		{at: start.Add(2 * time.Second), speed: 10},
+		{at: start.Add(3 * time.Second), speed: 30},
+		{at: start.Add(6 * time.Second), speed: 0},
+	}
+
+	// Two seconds a column: nothing yet, the average, a repeat, the stall
+	got := graphColumns(samples, start, start.Add(8*time.Second), 4)
+	want := []int64{-1, 20, 20, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("graphColumns() = %v, want %v", got, want)
+			break
+		}
+	}
+
+	averages := movingAverage(want, 2)
+	if averages[0] != -1 || averages[1] != 20 || averages[3] != 10 {
+		t.Errorf("movingAverage() = %v, want [-1 20 20 10]", averages)
+	}
+}
+
+func TestAxisDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{time.Minute, "60s"},
+		{5 * time.Minute, "300s"},
+		{12 * time.Minute, "12m"},
+		{90 * time.Minute, "1h30m"},
+	}
+	for _, tt := range tests {
+		if got := axisDuration(tt.d); got != tt.want {
+			t.Errorf("axisDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -4,100 +4,159 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Styles are built from the theme in use by applyTheme
 var (
-	// Color palette - harmonized around primary red (#EF4444)
-	primaryColor    = lipgloss.Color("#EF4444") // Red primary
-	secondaryColor  = lipgloss.Color("#F87171") // Lighter red/pink accent
-	successColor   = lipgloss.Color("#10B981") // Emerald green (complements red)
-	warningColor   = lipgloss.Color("#F59E0B") // Amber (works with red)
-	errorColor     = lipgloss.Color("#DC2626") // Darker red for errors
-	textColor      = lipgloss.Color("#F3F4F6") // Light gray text
-	dimTextColor   = lipgloss.Color("#9CA3AF") // Medium gray for dim text
-	borderColor    = lipgloss.Color("#4B5563") // Darker gray for borders
-	backgroundColor = lipgloss.Color("#1F2937") // Dark background
-
 	// Title style
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(primaryColor).
-			Background(backgroundColor).
-			Padding(0, 1).
-			MarginBottom(1)
+	titleStyle lipgloss.Style
 
 	// Box/border styles
+	boxStyle     lipgloss.Style
+	sectionStyle lipgloss.Style
+
+	// Text styles
+	filenameStyle       lipgloss.Style
+	statusStyle         lipgloss.Style
+	statusActiveStyle   lipgloss.Style
+	statusCompleteStyle lipgloss.Style
+	statusErrorStyle    lipgloss.Style
+	statusWarningStyle  lipgloss.Style
+
+	// Progress bar styles
+	progressBarStyle   lipgloss.Style
+	progressBarBgStyle lipgloss.Style
+
+	// Stat styles
+	statLabelStyle          lipgloss.Style
+	statValueStyle          lipgloss.Style
+	statValueHighlightStyle lipgloss.Style
+
+	// Success/Error styles
+	successStyle lipgloss.Style
+	errorStyle   lipgloss.Style
+
+	// Help text style
+	helpStyle lipgloss.Style
+
+	// Graph styles
+	graphStyle        lipgloss.Style
+	graphAverageStyle lipgloss.Style
+	graphPeakStyle    lipgloss.Style
+	graphAxisStyle    lipgloss.Style
+)
+
+func init() {
+	applyTheme(BuiltinThemes[0])
+}
+
+// applyTheme rebuilds the styles from a theme's palette
+func applyTheme(t Theme) {
+	var (
+		primaryColor    = themeColor(t.Primary)
+		successColor    = themeColor(t.Success)
+		warningColor    = themeColor(t.Warning)
+		errorColor      = themeColor(t.Error)
+		textColor       = themeColor(t.Text)
+		dimTextColor    = themeColor(t.DimText)
+		borderColor     = themeColor(t.Border)
+		backgroundColor = themeColor(t.Background)
+	)
+
+	titleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(primaryColor).
+		Background(backgroundColor).
+		Padding(0, 1).
+		MarginBottom(1)
+
 	boxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(borderColor).
-			Padding(1, 2).
-			Margin(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Margin(0, 1)
 
 	sectionStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder(), false, false, false, true).
-			BorderForeground(borderColor).
-			PaddingLeft(1).
-			MarginLeft(1)
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(borderColor).
+		PaddingLeft(1).
+		MarginLeft(1)
 
-	// Text styles
 	filenameStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(textColor).
-			Background(backgroundColor)
+		Bold(true).
+		Foreground(textColor).
+		Background(backgroundColor)
 
 	statusStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(successColor)
+		Bold(true).
+		Foreground(successColor)
 
 	statusActiveStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(primaryColor)
+		Bold(true).
+		Foreground(primaryColor)
 
 	statusCompleteStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(successColor)
+		Bold(true).
+		Foreground(successColor)
 
 	statusErrorStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(errorColor)
+		Bold(true).
+		Foreground(errorColor)
+
+	statusWarningStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(warningColor)
 
-	// Progress bar styles
 	progressBarStyle = lipgloss.NewStyle().
-				Foreground(primaryColor)
+		Foreground(primaryColor)
 
 	progressBarBgStyle = lipgloss.NewStyle().
-				Foreground(dimTextColor)
+		Foreground(dimTextColor)
 
-	// Stat styles
 	statLabelStyle = lipgloss.NewStyle().
-			Foreground(dimTextColor).
-			Width(12)
+		Foreground(dimTextColor).
+		Width(12)
 
 	statValueStyle = lipgloss.NewStyle().
-			Foreground(textColor).
-			Bold(true)
+		Foreground(textColor).
+		Bold(true)
 
 	statValueHighlightStyle = lipgloss.NewStyle().
-				Foreground(primaryColor).
-				Bold(true)
+		Foreground(primaryColor).
+		Bold(true)
 
-	// Success/Error styles
 	successStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(successColor).
-			Background(backgroundColor).
-			Padding(1, 2)
+		Bold(true).
+		Foreground(successColor).
+		Background(backgroundColor).
+		Padding(1, 2)
 
 	errorStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(errorColor).
-			Background(backgroundColor).
-			Padding(1, 2)
+		Bold(true).
+		Foreground(errorColor).
+		Background(backgroundColor).
+		Padding(1, 2)
 
-	// Help text style
 	helpStyle = lipgloss.NewStyle().
-			Foreground(dimTextColor).
-			Italic(true)
+		Foreground(dimTextColor).
+		Italic(true)
 
-	// Graph style
 	graphStyle = lipgloss.NewStyle().
-			Foreground(primaryColor)
-)
+		Foreground(primaryColor)
+
+	graphAverageStyle = lipgloss.NewStyle().
+		Foreground(textColor)
+
+	graphPeakStyle = lipgloss.NewStyle().
+		Foreground(warningColor)
+
+	graphAxisStyle = lipgloss.NewStyle().
+		Foreground(dimTextColor)
+}
+
+// themeColor turns a theme colour into a lipgloss colour; an empty colour
+// leaves the terminal's own
+func themeColor(c string) lipgloss.TerminalColor {
+	if c == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(c)
+}
@@ -0,0 +1,166 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/viper"
+)
+
+// Theme is the palette the TUI is drawn with. Colours are hex codes such as
+// #EF4444 or ANSI numbers; empty colours leave the terminal's own.
+type Theme struct {
+	Name       string `mapstructure:"name"`
+	Primary    string `mapstructure:"primary"` // Title, progress bar, graph and highlights
+	Success    string `mapstructure:"success"`
+	Warning    string `mapstructure:"warning"`
+	Error      string `mapstructure:"error"`
+	Text       string `mapstructure:"text"`
+	DimText    string `mapstructure:"dim_text"` // Labels and help
+	Border     string `mapstructure:"border"`
+	Background string `mapstructure:"background"` // Behind the title and file name
+}
+
+// ThemeAuto picks the dark or light theme from the terminal's background
+const ThemeAuto = "auto"
+
+// BuiltinThemes are the themes venaqui ships, the default first
+var BuiltinThemes = []Theme{
+	{
+		Name:       "dark",
+		Primary:    "#EF4444",
+		Success:    "#10B981",
+		Warning:    "#F59E0B",
+		Error:      "#DC2626",
+		Text:       "#F3F4F6",
+		DimText:    "#9CA3AF",
+		Border:     "#4B5563",
+		Background: "#1F2937",
+	},
+	{
+		Name:       "light",
+		Primary:    "#DC2626",
+		Success:    "#047857",
+		Warning:    "#B45309",
+		Error:      "#B91C1C",
+		Text:       "#111827",
+		DimText:    "#4B5563",
+		Border:     "#9CA3AF",
+		Background: "#F3F4F6",
+	},
+	{
+		Name:       "high-contrast",
+		Primary:    "#FFFF00",
+		Success:    "#00FF00",
+		Warning:    "#FFAF00",
+		Error:      "#FF5555",
+		Text:       "#FFFFFF",
+		DimText:    "#FFFFFF",
+		Border:     "#FFFFFF",
+		Background: "#000000",
+	},
+	{Name: "no-color"},
+}
+
+// LoadThemes returns the built-in themes followed by the user themes in
+// dir, one YAML file each, named after the file unless the file has a
+// name. A user theme replaces the built-in one of the same name, and
+// colours it leaves out are the dark theme's. A missing dir has no user
+// themes; files that can't be read are skipped with a warning.
+func LoadThemes(dir string) ([]Theme, []string) {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	more, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+	paths = append(paths, more...)
+	sort.Strings(paths)
+
+	themes := append([]Theme{}, BuiltinThemes...)
+	var warnings []string
+	for _, path := range paths {
+		v := viper.New()
+		v.SetConfigFile(path)
+		theme := BuiltinThemes[0]
+		theme.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if err := v.ReadInConfig(); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Skipping theme %s: %v", path, err))
+			continue
+		}
+		if err := v.Unmarshal(&theme); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Skipping theme %s: %v", path, err))
+			continue
+		}
+		themes = addTheme(themes, theme)
+	}
+	return themes, warnings
+}
+
+// addTheme adds a theme, replacing the one of the same name
+func addTheme(themes []Theme, theme Theme) []Theme {
+	for i := range themes {
+		if strings.EqualFold(themes[i].Name, theme.Name) {
+			themes[i] = theme
+			return themes
+		}
+	}
+	return append(themes, theme)
+}
+
+// SelectTheme returns the index of the named theme. auto picks dark or
+// light from the terminal's background, and NO_COLOR picks no-color
+// whatever the name.
+func SelectTheme(themes []Theme, name string) (int, error) {
+	switch {
+	case os.Getenv("NO_COLOR") != "":
+		name = "no-color"
+	case name == "" || strings.EqualFold(name, ThemeAuto):
+		name = "dark"
+		if !lipgloss.HasDarkBackground() {
+			name = "light"
+		}
+	}
+
+	for i := range themes {
+		if strings.EqualFold(themes[i].Name, name) {
+			return i, nil
+		}
+	}
+	names := make([]string, len(themes))
+	for i, theme := range themes {
+		names[i] = theme.Name
+	}
+	return 0, fmt.Errorf("unknown theme %q, choose one of %s", name, strings.Join(names, ", "))
+}
+
+// cycleTheme switches to the next theme and returns the model using it
+func (m Model) cycleTheme() Model {
+	if len(m.themes) < 2 {
+		return m
+	}
+	m.theme = (m.theme + 1) % len(m.themes)
+	applyTheme(m.themes[m.theme])
+	return m
+}
+
+// renderHelp renders a help line, followed by the theme hotkey when
+// themes can be switched
+func (m Model) renderHelp(text string) string {
+	if name := m.themeName(); name != "" {
+		text += fmt.Sprintf(" | 't' for the next theme (%s)", name)
+	}
+	if m.width > 0 {
+		return helpStyle.Copy().Width(m.width).Render(text)
+	}
+	return helpStyle.Render(text)
+}
+
+// themeName returns the name of the theme in use, empty when themes can't
+// be switched
+func (m Model) themeName() string {
+	if len(m.themes) < 2 {
+		return ""
+	}
+	return m.themes[m.theme].Name
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadThemes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"solarized.yaml": "primary: \"#268BD2\"\nbackground: \"#002B36\"\n",
+		"mine.yml":       "name: light\ntext: \"#000000\"\n",
+		"broken.yaml":    "primary: [unterminated\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	themes, warnings := LoadThemes(dir)
+	if len(warnings) != 1 {
+		t.Errorf("LoadThemes() warnings = %q, want one for broken.yaml", warnings)
+	}
+	if len(themes) != len(BuiltinThemes)+1 {
+		t.Fatalf("LoadThemes() returned %d themes, want %d", len(themes), len(BuiltinThemes)+1)
+	}
+
+	// The named file replaces the built-in light theme
+	if light := themes[1]; light.Name != "light" || light.Text != "#000000" || light.Primary != BuiltinThemes[0].Primary {
+		t.Errorf("LoadThemes()[1] = %+v, want light with black text and the dark theme's other colours", light)
+	}
+	if solarized := themes[len(themes)-1]; solarized.Name != "solarized" || solarized.Primary != "#268BD2" || solarized.Border != BuiltinThemes[0].Border {
+		t.Errorf("LoadThemes() last = %+v, want solarized", solarized)
+	}
+
+	// A missing directory has only the built-in themes
+	if themes, warnings := LoadThemes(filepath.Join(dir, "missing")); len(themes) != len(BuiltinThemes) || len(warnings) != 0 {
+		t.Errorf("LoadThemes(missing) = %d themes, %q", len(themes), warnings)
+	}
+}
+
+func TestSelectTheme(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+	}{
+		{"dark", 0, false},
+		{"High-Contrast", 2, false},
+		{"no-color", 3, false},
+		{"neon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := SelectTheme(BuiltinThemes, tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("SelectTheme(%q) = %d, %v, want %d, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	// NO_COLOR wins over the configured theme
+	t.Setenv("NO_COLOR", "1")
+	if got, _ := SelectTheme(BuiltinThemes, "dark"); BuiltinThemes[got].Name != "no-color" {
+		t.Errorf("SelectTheme(dark) with NO_COLOR = %s, want no-color", BuiltinThemes[got].Name)
+	}
+}
+
+func TestCycleTheme(t *testing.T) {
+	defer applyTheme(BuiltinThemes[0])
+
+	m := InitialModelWithOptions(nil, "gid", "file", Options{Themes: BuiltinThemes, Theme: 2})
+	for _, want := range []string{"no-color", "dark", "light"} {
+		m = m.cycleTheme()
+		if got := m.themeName(); got != want {
+			t.Errorf("cycleTheme() = %s, want %s", got, want)
+		}
+	}
+
+	// A single theme can't be switched
+	if m := InitialModel(nil, "gid", "file").cycleTheme(); m.themeName() != "" {
+		t.Errorf("cycleTheme() without themes = %s, want none", m.themeName())
+	}
+}
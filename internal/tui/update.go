@@ -5,6 +5,10 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/extract"
+	"github.com/mhrsntrk/venaqui/internal/notify"
+	"github.com/mhrsntrk/venaqui/internal/verify"
 )
 
 // Update handles messages and updates the model
@@ -14,8 +18,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "q", "ctrl+c", "esc":
+			if m.extract.cancel != nil {
+				m.extract.cancel()
+			}
+			// Nothing resumes downloads paused for low disk space once the TUI is gone
+			if m.diskGuard != nil {
+				m.diskGuard.Release()
+			}
 			m.quitting = true
 			return m, tea.Quit
+		case "w":
+			// Show the next span of the speed history
+			m.graphWindow = (m.graphWindow + 1) % len(graphWindows)
+			return m, nil
+		case "t":
+			// The next View draws with the new palette
+			return m.cycleTheme(), nil
 		case "o":
 			// Open file directly with default application when download is complete
 			if m.status != nil && m.status.IsComplete() {
@@ -59,6 +77,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+	case tea.WindowSizeMsg:
+		// The view reflows to the new size
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
 	case tickMsg:
 		// Update last update time
 		m.lastUpdate = time.Time(msg)
@@ -71,37 +94,197 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case statusMsg:
 		m.status = msg
 
-		// Update speed history
-		if m.status != nil && m.status.DownloadSpeed > 0 {
-			m.speedHistory = append(m.speedHistory, m.status.DownloadSpeed)
-			if len(m.speedHistory) > m.maxHistory {
-				m.speedHistory = m.speedHistory[1:]
-			}
+		// Update speed history and the ETA, stalls included
+		if m.status != nil && !m.status.IsComplete() {
+			now := time.Now()
+			m.speed.add(now, m.status.DownloadSpeed)
+			m.eta.Update(now, m.status)
 		}
 
-		// Track completion time
+		// Track completion time and verify the file once
 		if m.status != nil && m.status.IsComplete() && m.completionTime.IsZero() {
 			m.completionTime = time.Now()
+			m.verifying = true
+			var probeCmd tea.Cmd
+			m, probeCmd = m.probeMedia()
+			return m, tea.Batch(m.verifyFile, probeCmd)
+		}
+
+		// aria2 already rejected the file against --checksum; try again
+		if m.status.IsChecksumError() && m.redownloads < verify.MaxRedownloads {
+			if m.redownloading {
+				return m, nil
+			}
+			m.redownloading = true
+			return m, m.redownload
 		}
 
 		// Don't auto-quit on completion - let user press 'o' or 'q'
 		// Check for errors
 		if m.status.IsError() {
-			if m.status.ErrorMessage != "" {
-				m.err = fmt.Errorf("download error: %s", m.status.ErrorMessage)
-			} else {
-				m.err = fmt.Errorf("download error")
+			switch {
+			case m.status.IsChecksumError():
+				// aria2's checksum check failed after every re-download
+				m.err = fmt.Errorf("%w: aria2 rejected %s", verify.ErrMismatch, m.filename)
+			case m.status.ErrorMessage != "":
+				m.err = fmt.Errorf("%w: %s", aria2.ErrDownloadFailed, m.status.ErrorMessage)
+			default:
+				m.err = aria2.ErrDownloadFailed
 			}
 			m.quitting = true
-			return m, tea.Quit
+			return m, tea.Sequence(m.notifyError(m.err), tea.Quit)
+		}
+		// Another client removed the download; nothing will change anymore
+		if m.status.IsRemoved() {
+			m.err = fmt.Errorf("%w: the download was removed from aria2", aria2.ErrDownloadRemoved)
+			m.quitting = true
+			return m, tea.Sequence(m.notifyError(m.err), tea.Quit)
+		}
+
+		return m, nil
+
+	case verifyMsg:
+		m.verifying = false
+		m.verification = msg.result
+		m.verifyErr = msg.err
+
+		if msg.result != nil && !msg.result.OK() {
+			if m.redownloads < verify.MaxRedownloads {
+				m.redownloading = true
+				return m, m.redownload
+			}
+			return m, m.notify(notify.EventRetryExhausted, "Download corrupted",
+				fmt.Sprintf("%s failed %s verification after %d re-downloads", m.filename, msg.result.Algo, m.redownloads))
+		}
+
+		// A file that can't be checked is an error, as in headless mode;
+		// the on_error hooks run once the TUI exits
+		if msg.err != nil {
+			m.err = fmt.Errorf("failed to verify download: %w", msg.err)
+			m.quitting = true
+			return m, tea.Sequence(m.notifyError(m.err), tea.Quit)
+		}
+
+		notifyCmd := m.notify(notify.EventComplete, "Download complete", m.filename)
+		if m.extractor != nil {
+			return m, tea.Batch(notifyCmd, m.checkArchive)
+		}
+		var hooksCmd tea.Cmd
+		m, hooksCmd = m.runCompleteHooks()
+		return m, tea.Batch(notifyCmd, hooksCmd)
+
+	case mediaMsg:
+		// Files ffprobe can't read are shown without tracks
+		m.media.probing = false
+		if msg.err == nil {
+			m.media.info = msg.info
 		}
+		return m, nil
 
+	case extractCheckMsg:
+		if msg.err != nil {
+			m.extract.err = msg.err
+			return m.runCompleteHooks()
+		}
+		if msg.set == nil {
+			return m.runCompleteHooks()
+		}
+
+		m.extract.set = msg.set
+		m.extract.waiting = msg.missing
+		if len(msg.missing) > 0 {
+			// Parts aria2 doesn't know about will never arrive
+			if !msg.downloading {
+				m.extract.skipped = true
+				return m.runCompleteHooks()
+			}
+			return m, recheckArchive()
+		}
+
+		m.extract.running = true
+		m.extract.progress = extract.Progress{Percent: -1, Password: -1}
+		m.extract.events, m.extract.cancel = startExtraction(m.extractor, msg.set)
+		return m, waitForExtraction(m.extract.events)
+
+	case extractRecheckMsg:
+		if m.status != nil && m.status.IsComplete() && !m.extract.running && !m.extract.done && !m.extract.skipped {
+			return m, m.checkArchive
+		}
 		return m, nil
 
+	case extractProgressMsg:
+		m.extract.progress = extract.Progress(msg)
+		return m, waitForExtraction(m.extract.events)
+
+	case extractDoneMsg:
+		m.extract.running = false
+		m.extract.done = true
+		m.extract.dest = msg.dest
+		m.extract.err = msg.err
+		return m.runCompleteHooks()
+
+	case diskSpaceMsg:
+		switch {
+		case msg.err != nil:
+			m.diskNotice = "Disk space check failed: " + msg.err.Error()
+		case msg.event != nil && len(msg.event.Paused) > 0:
+			m.diskNotice = msg.event.String()
+		case msg.event != nil:
+			m.diskNotice = ""
+		}
+		// Stop watching once the download is done
+		if m.status != nil && m.status.IsComplete() {
+			return m, nil
+		}
+		return m, m.checkDiskSpace()
+
+	case scheduleMsg:
+		switch {
+		case msg.event != nil && msg.event.Paused:
+			m.scheduleNotice = msg.event.Status
+		case msg.event != nil:
+			m.scheduleNotice = ""
+		case m.scheduleNotice != "":
+			// Keep the time left up to date
+			m.scheduleNotice = m.schedule.Status(time.Now())
+		}
+		if m.status != nil && m.status.IsComplete() {
+			return m, nil
+		}
+		return m, m.checkSchedule()
+
+	case hooksDoneMsg:
+		m.hook.done = true
+		m.hook.results = msg
+		return m, nil
+
+	case redownloadMsg:
+		// Start over with the new download
+		m.gid = string(msg)
+		if m.schedule != nil {
+			m.schedule.Track(m.gid)
+		}
+		if m.diskGuard != nil {
+			m.diskGuard.Track(m.gid)
+		}
+		m.redownloads++
+		m.redownloading = false
+		m.status = nil
+		m.verification = nil
+		m.verifyErr = nil
+		m.extract = extractState{}
+		m.hook = hookState{}
+		// The failed attempt's speed says nothing about the new one
+		m.speed = newSpeedHistory()
+		m.eta = aria2.NewETAEstimator()
+		m.startTime = time.Now()
+		m.completionTime = time.Time{}
+		return m, m.fetchStatus
+
 	case errMsg:
 		m.err = msg
 		m.quitting = true
-		return m, tea.Quit
+		return m, tea.Sequence(m.notifyError(m.err), tea.Quit)
 	}
 
 	return m, nil
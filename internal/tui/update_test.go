@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mhrsntrk/venaqui/internal/aria2"
+	"github.com/mhrsntrk/venaqui/internal/extract"
+	"github.com/mhrsntrk/venaqui/internal/verify"
+)
+
+func TestUpdate_ChecksumErrorRedownloadsOnce(t *testing.T) {
+	m := InitialModel(nil, "gid", "file.zip")
+	status := &aria2.DownloadStatus{GID: "gid", Status: "error", ErrorCode: 32}
+
+	updated, cmd := m.Update(statusMsg(status))
+	if cmd == nil || !updated.(Model).redownloading {
+		t.Fatal("Update() of a checksum error did not start a re-download")
+	}
+
+	// The next tick arrives before aria2 has added the download again
+	updated, cmd = updated.(Model).Update(statusMsg(status))
+	if cmd != nil {
+		t.Error("Update() started a second re-download for the same failure")
+	}
+
+	updated, _ = updated.(Model).Update(redownloadMsg("gid2"))
+	if m := updated.(Model); m.redownloading || m.redownloads != 1 || m.gid != "gid2" {
+		t.Errorf("after redownloadMsg: redownloading = %v, redownloads = %d, gid = %q", m.redownloading, m.redownloads, m.gid)
+	}
+
+	// Out of re-downloads, the mismatch is reported as such
+	m = updated.(Model)
+	m.redownloads = verify.MaxRedownloads
+	updated, _ = m.Update(statusMsg(status))
+	if err := updated.(Model).Err(); !errors.Is(err, verify.ErrMismatch) {
+		t.Errorf("Update() of a checksum error after every re-download: err = %v, want verify.ErrMismatch", err)
+	}
+}
+
+func TestUpdate_RedownloadResetsSpeed(t *testing.T) {
+	m := InitialModel(nil, "gid", "file.zip")
+	status := &aria2.DownloadStatus{GID: "gid", Status: "active", TotalLength: 1000, CompletedLength: 500, DownloadSpeed: 100}
+	updated, _ := m.Update(statusMsg(status))
+	if m = updated.(Model); m.speed.empty() || m.eta.Speed() != 100 {
+		t.Fatalf("after a status: speed history empty = %v, smoothed speed = %d", m.speed.empty(), m.eta.Speed())
+	}
+
+	updated, _ = m.Update(redownloadMsg("gid2"))
+	if m = updated.(Model); !m.speed.empty() || m.eta.Speed() != 0 {
+		t.Errorf("after redownloadMsg: speed history empty = %v, smoothed speed = %d, want both reset", m.speed.empty(), m.eta.Speed())
+	}
+}
+
+func TestUpdate_ArchivePartsNotDownloading(t *testing.T) {
+	m := InitialModel(nil, "gid", "show.part1.rar")
+	m.status = &aria2.DownloadStatus{GID: "gid", Status: "complete"}
+	set := &extract.Set{Kind: extract.KindRAR, Name: "show"}
+
+	// aria2 is still fetching a part, so check again later
+	updated, cmd := m.Update(extractCheckMsg{set: set, missing: []string{"show.part2.rar"}, downloading: true})
+	if cmd == nil || updated.(Model).extract.skipped {
+		t.Error("Update() stopped waiting for a part aria2 is downloading")
+	}
+
+	// Nothing else is queued, so the part will never arrive
+	updated, _ = m.Update(extractCheckMsg{set: set, missing: []string{"show.part2.rar"}})
+	m = updated.(Model)
+	if !m.extract.skipped {
+		t.Error("Update() keeps waiting for a part aria2 is not downloading")
+	}
+	if _, cmd := m.Update(extractRecheckMsg{}); cmd != nil {
+		t.Error("Update() rechecks a skipped archive set")
+	}
+}
+
+func TestUpdate_Removed(t *testing.T) {
+	m := InitialModel(nil, "gid", "file.zip")
+	updated, cmd := m.Update(statusMsg(&aria2.DownloadStatus{GID: "gid", Status: "removed"}))
+	m = updated.(Model)
+	if !errors.Is(m.Err(), aria2.ErrDownloadRemoved) || !m.quitting || cmd == nil {
+		t.Errorf("Update() of a removed download: err = %v, quitting = %v, want ErrDownloadRemoved and quit", m.Err(), m.quitting)
+	}
+}
+
+func TestUpdate_VerifyError(t *testing.T) {
+	m := InitialModel(nil, "gid", "file.zip")
+	m.status = &aria2.DownloadStatus{GID: "gid", Status: "complete"}
+	updated, cmd := m.Update(verifyMsg{err: errors.New("permission denied")})
+	m = updated.(Model)
+	if m.Err() == nil || !m.quitting || cmd == nil {
+		t.Errorf("Update() of a failed verification: err = %v, quitting = %v, want an error and quit", m.Err(), m.quitting)
+	}
+}
@@ -7,13 +7,18 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dustin/go-humanize"
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/verify"
 )
 
 // View renders the UI
 func (m Model) View() string {
 	if m.err != nil {
-		return errorStyle.Render(fmt.Sprintf("✗ Error: %v", m.err)) + "\n\n" +
-			helpStyle.Render("Press 'q' to quit") + "\n"
+		view := errorStyle.Render(fmt.Sprintf("✗ Error: %v", m.err)) + "\n\n"
+		if hint := debrid.Hint(m.err); hint != "" {
+			view += helpStyle.Render("Hint: "+hint) + "\n\n"
+		}
+		return view + helpStyle.Render("Press 'q' to quit") + "\n"
 	}
 
 	if m.quitting {
@@ -24,6 +29,11 @@ func (m Model) View() string {
 		return "Exiting...\n"
 	}
 
+	// Small terminals get a single status line
+	if m.compact() {
+		return m.renderCompact()
+	}
+
 	// Show completion message but don't quit automatically
 	if m.status != nil && m.status.IsComplete() {
 		return m.renderCompletionView()
@@ -42,20 +52,36 @@ func (m Model) View() string {
 	s.WriteString("\n\n")
 
 	// File info box
-	fileBox := boxStyle.Render(
+	statusText := m.getStatusStyle().Render(m.getStatusText())
+	if m.redownloads > 0 {
+		statusText += helpStyle.Render(fmt.Sprintf(" (re-download %d/%d after checksum mismatch)", m.redownloads, verify.MaxRedownloads))
+	}
+	if m.diskNotice != "" {
+		statusText += "\n" + statusErrorStyle.Render("⚠ "+m.diskNotice)
+	}
+	if m.scheduleNotice != "" {
+		statusText += "\n" + statusWarningStyle.Render("🕐 "+m.scheduleNotice)
+	}
+	fileBox := m.box().Render(
 		fmt.Sprintf("%s %s\n%s %s",
 			statLabelStyle.Render("File:"),
 			filenameStyle.Render(m.filename),
 			statLabelStyle.Render("Status:"),
-			m.getStatusStyle().Render(m.getStatusText()),
+			statusText,
 		),
 	)
 	s.WriteString(fileBox)
 	s.WriteString("\n\n")
 
+	// Video tracks
+	if mediaText := m.renderMedia(); mediaText != "" {
+		s.WriteString(m.box().Render(mediaText))
+		s.WriteString("\n\n")
+	}
+
 	// Progress section
 	progress := m.status.GetProgress()
-	progressBox := boxStyle.Render(
+	progressBox := m.box().Render(
 		fmt.Sprintf("%s\n%s\n%s",
 			statLabelStyle.Render("Progress:"),
 			m.renderProgressBar(progress),
@@ -65,39 +91,23 @@ func (m Model) View() string {
 	s.WriteString(progressBox)
 	s.WriteString("\n\n")
 
-	// Statistics section - two columns
-	statsLeft := m.renderStatsLeft()
-	statsRight := m.renderStatsRight()
-	
-	statsBox := boxStyle.Render(
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			statsLeft,
-			strings.Repeat(" ", 4),
-			statsRight,
-		),
-	)
+	// Statistics section - two columns, stacked when narrow
+	statsBox := m.box().Render(m.columns(m.renderStatsLeft(), m.renderStatsRight()))
 	s.WriteString(statsBox)
 	s.WriteString("\n\n")
 
-	// Speed graph
-	if len(m.speedHistory) > 0 {
-		graphBox := boxStyle.Render(
-			fmt.Sprintf("%s\n%s",
-				statLabelStyle.Render("Speed History:"),
-				m.renderSpeedGraph(),
-			),
-		)
-		s.WriteString(graphBox)
-		s.WriteString("\n\n")
+	// Speed graph, in the rows left over
+	help := m.renderHelp("Press 'q' or 'Esc' to quit") + "\n"
+	if !m.speed.empty() {
+		used := strings.Count(s.String(), "\n") + strings.Count(help, "\n")
+		if height := m.graphHeight(used); height > 0 {
+			s.WriteString(m.renderGraphBox(height))
+			s.WriteString("\n\n")
+		}
 	}
 
 	// Help text
-	if m.status != nil && m.status.IsComplete() {
-		s.WriteString(helpStyle.Render("Press 'o' to open file | 'd' to show in directory | 'q' to quit"))
-	} else {
-		s.WriteString(helpStyle.Render("Press 'q' or 'Esc' to quit"))
-	}
-	s.WriteString("\n")
+	s.WriteString(help)
 
 	return s.String()
 }
@@ -138,9 +148,9 @@ func (m Model) getStatusText() string {
 	}
 }
 
-// renderProgressBar creates a visual progress bar
+// renderProgressBar creates a visual progress bar as wide as the box
 func (m Model) renderProgressBar(percent float64) string {
-	width := 60
+	width := m.barWidth()
 	filled := int(percent / 100 * float64(width))
 	if filled > width {
 		filled = width
@@ -206,14 +216,16 @@ func (m Model) renderStatsRight() string {
 		statValueStyle.Render(elapsedStr),
 	))
 
-	// ETA
-	eta := m.status.GetETA()
-	if eta > 0 {
-		etaDuration := time.Duration(eta) * time.Second
-		etaStr := formatDuration(etaDuration)
+	// ETA from the smoothed speed, with the range it may vary in
+	eta := m.eta.Estimate(m.status)
+	if eta.Known && eta.Expected > 0 {
+		etaStr := statValueStyle.Render(formatDuration(eta.Expected))
+		if eta.Max-eta.Min >= time.Second {
+			etaStr += helpStyle.Render(fmt.Sprintf(" (%s–%s)", formatDuration(eta.Min), formatDuration(eta.Max)))
+		}
 		s.WriteString(fmt.Sprintf("%s %s\n",
 			statLabelStyle.Render("ETA:"),
-			statValueStyle.Render(etaStr),
+			etaStr,
 		))
 	} else {
 		s.WriteString(fmt.Sprintf("%s %s\n",
@@ -240,79 +252,6 @@ func (m Model) renderStatsRight() string {
 	return s.String()
 }
 
-// renderSpeedGraph renders a simple ASCII speed graph
-func (m Model) renderSpeedGraph() string {
-	if len(m.speedHistory) == 0 {
-		return "No data yet..."
-	}
-
-	width := 60
-	height := 8
-
-	// Find max speed for scaling
-	maxSpeed := int64(1)
-	for _, speed := range m.speedHistory {
-		if speed > maxSpeed {
-			maxSpeed = speed
-		}
-	}
-	if maxSpeed == 0 {
-		maxSpeed = 1
-	}
-
-	// Create graph grid
-	graph := make([][]bool, height)
-	for i := range graph {
-		graph[i] = make([]bool, width)
-	}
-
-	// Plot points
-	step := float64(len(m.speedHistory)) / float64(width)
-	for x := 0; x < width; x++ {
-		idx := int(float64(x) * step)
-		if idx >= len(m.speedHistory) {
-			idx = len(m.speedHistory) - 1
-		}
-		if idx < 0 {
-			idx = 0
-		}
-
-		speed := m.speedHistory[idx]
-		y := int(float64(speed) / float64(maxSpeed) * float64(height-1))
-		if y >= height {
-			y = height - 1
-		}
-		if y < 0 {
-			y = 0
-		}
-
-		// Draw line from bottom
-		for lineY := height - 1; lineY >= height-1-y; lineY-- {
-			if lineY >= 0 && lineY < height {
-				graph[lineY][x] = true
-			}
-		}
-	}
-
-	// Render graph
-	var result strings.Builder
-	for y := height - 1; y >= 0; y-- {
-		for x := 0; x < width; x++ {
-			if graph[y][x] {
-				result.WriteString(graphStyle.Render("▁"))
-			} else {
-				result.WriteString(" ")
-			}
-		}
-		if y == height-1 {
-			result.WriteString(fmt.Sprintf(" %s/s", humanize.Bytes(uint64(maxSpeed))))
-		}
-		result.WriteString("\n")
-	}
-
-	return result.String()
-}
-
 // formatDuration formats a duration in a human-readable way
 func formatDuration(d time.Duration) string {
 	if d < time.Minute {
@@ -348,28 +287,121 @@ func (m Model) renderCompletionView() string {
 		if pathToShow == "" {
 			pathToShow = fileDir
 		}
-		fileBox := boxStyle.Render(
-			fmt.Sprintf("%s %s",
+		fileBox := m.box().Render(
+			fmt.Sprintf("%s %s\n%s %s",
 				statLabelStyle.Render("Location:"),
 				filenameStyle.Render(pathToShow),
+				statLabelStyle.Render("Checksum:"),
+				m.renderVerification(),
 			),
 		)
 		s.WriteString(fileBox)
 		s.WriteString("\n\n")
 	}
 	
+	// Video tracks
+	if mediaText := m.renderMedia(); mediaText != "" {
+		s.WriteString(m.box().Render(mediaText))
+		s.WriteString("\n\n")
+	}
+
+	// Archive extraction
+	if extractText := m.renderExtraction(); extractText != "" {
+		s.WriteString(m.box().Render(fmt.Sprintf("%s %s",
+			statLabelStyle.Render("Extract:"),
+			extractText,
+		)))
+		s.WriteString("\n\n")
+	}
+
+	// Hooks
+	if hookText := m.renderHooks(); hookText != "" {
+		s.WriteString(m.box().Render(fmt.Sprintf("%s %s",
+			statLabelStyle.Render("Hooks:"),
+			hookText,
+		)))
+		s.WriteString("\n\n")
+	}
+
 	// Completion statistics
-	statsBox := boxStyle.Render(m.renderCompletionStats())
+	statsBox := m.box().Render(m.renderCompletionStats())
 	s.WriteString(statsBox)
 	s.WriteString("\n\n")
 	
 	// Help text
-	s.WriteString(helpStyle.Render("Press 'o' to open file | 'd' to show in directory | 'q' to quit"))
+	s.WriteString(m.renderHelp("Press 'o' to open file | 'd' to show in directory | 'q' to quit"))
 	s.WriteString("\n")
 	
 	return s.String()
 }
 
+// renderVerification renders the checksum verification result
+func (m Model) renderVerification() string {
+	switch {
+	case m.verifying:
+		return statValueStyle.Render("Verifying...")
+	case m.verifyErr != nil:
+		return statusErrorStyle.Render(fmt.Sprintf("✗ %v", m.verifyErr))
+	case m.verification == nil:
+		return helpStyle.Render("Not verified (no checksum given or found)")
+	case m.verification.OK():
+		return statusCompleteStyle.Render(fmt.Sprintf("✓ %s verified (%s)", m.verification.Algo, m.verification.Source))
+	default:
+		return statusErrorStyle.Render(fmt.Sprintf("✗ %s mismatch (%s) after %d re-downloads",
+			m.verification.Algo, m.verification.Source, m.redownloads))
+	}
+}
+
+// renderExtraction renders the archive extraction state, or an empty
+// string if the download is not an archive being extracted
+func (m Model) renderExtraction() string {
+	e := m.extract
+	switch {
+	case e.done && e.err != nil:
+		return statusErrorStyle.Render(fmt.Sprintf("✗ %v", e.err))
+	case e.done:
+		return statusCompleteStyle.Render("✓ Extracted to " + e.dest)
+	case e.running:
+		text := "Extracting " + e.set.Name + "..."
+		if e.progress.Percent >= 0 {
+			text = fmt.Sprintf("Extracting %s... %d%%", e.set.Name, e.progress.Percent)
+		}
+		if e.progress.Password >= 0 {
+			text += fmt.Sprintf(" (password %d)", e.progress.Password+1)
+		}
+		return statValueHighlightStyle.Render(text)
+	case e.skipped:
+		return statusWarningStyle.Render(fmt.Sprintf("Skipped extraction of %s, missing part(s): %s", e.set.Name, strings.Join(e.waiting, ", ")))
+	case e.set != nil && len(e.waiting) > 0:
+		return helpStyle.Render(fmt.Sprintf("Waiting for %d part(s): %s", len(e.waiting), strings.Join(e.waiting, ", ")))
+	case e.err != nil:
+		return statusErrorStyle.Render(fmt.Sprintf("✗ %v", e.err))
+	}
+	return ""
+}
+
+// renderHooks renders the outcome of the on_complete hooks, or an empty
+// string if none are configured
+func (m Model) renderHooks() string {
+	switch {
+	case !m.hook.started:
+		return ""
+	case !m.hook.done:
+		return statValueHighlightStyle.Render("Running...")
+	}
+
+	var failed []string
+	for _, r := range m.hook.results {
+		if r.Err != nil {
+			failed = append(failed, fmt.Sprintf("✗ %s: %v", r.Name, r.Err))
+		}
+	}
+	if len(failed) > 0 {
+		return statusErrorStyle.Render(strings.Join(failed, "\n"))
+	}
+	return statusCompleteStyle.Render(fmt.Sprintf("✓ %d hook(s) ran", len(m.hook.results)))
+}
+
 // renderCompletionStats renders statistics for completed download
 func (m Model) renderCompletionStats() string {
 	var s strings.Builder
@@ -390,13 +422,8 @@ func (m Model) renderCompletionStats() string {
 		avgSpeed = int64(float64(totalBytes) / totalTime.Seconds())
 	}
 	
-	// Find peak speed from history
-	var peakSpeed int64
-	for _, speed := range m.speedHistory {
-		if speed > peakSpeed {
-			peakSpeed = speed
-		}
-	}
+	// Peak speed from history
+	peakSpeed := m.speed.peak.speed
 	
 	// Left column
 	leftStats := fmt.Sprintf("%s %s\n%s %s\n%s %s",
@@ -419,11 +446,7 @@ func (m Model) renderCompletionStats() string {
 	)
 	
 	// Combine columns
-	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Left,
-		leftStats,
-		strings.Repeat(" ", 4),
-		rightStats,
-	))
+	s.WriteString(m.columns(leftStats, rightStats))
 	
 	return s.String()
 }
@@ -0,0 +1,398 @@
+package tui
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mhrsntrk/venaqui/internal/debrid"
+	"github.com/mhrsntrk/venaqui/internal/utils"
+)
+
+// WizardValues holds the settings asked for by the setup wizard
+type WizardValues struct {
+	APIToken    string
+	DownloadDir string
+	Aria2RPCUrl string
+	Aria2Secret string
+	Connections int
+}
+
+// WizardOptions connects the setup wizard to the debrid provider and aria2
+type WizardOptions struct {
+	ConfigPath    string
+	Existing      bool                              // The config file exists and is being edited
+	Provider      string                            // Name of the debrid service, such as Real-Debrid
+	TokenURL      string                            // Where the provider shows the API token
+	ValidateToken func(token string) error          // Checks a token with the provider
+	DetectAria2   func(rpcURL, secret string) error // Checks whether aria2 answers at rpcURL
+}
+
+// wizardStep is a screen of the setup wizard
+type wizardStep int
+
+const (
+	stepEditExisting wizardStep = iota
+	stepToken
+	stepDownloadDir
+	stepAria2URL
+	stepAria2Secret
+	stepConnections
+	stepConfirm
+)
+
+// tokenCheckedMsg carries the result of validating the API token
+type tokenCheckedMsg struct {
+	token string
+	err   error
+}
+
+// aria2DetectedMsg carries the result of looking for aria2
+type aria2DetectedMsg struct {
+	rpcURL string
+	err    error
+}
+
+// Wizard is the setup wizard run by venaqui init
+type Wizard struct {
+	opts   WizardOptions
+	values WizardValues
+	step   wizardStep
+	input  textinput.Model
+
+	err        string // Why the current answer was rejected
+	checking   bool   // Waiting for the token to be validated
+	aria2URL   string // URL aria2 was last looked for at
+	aria2Err   error
+	aria2Known bool
+
+	saved     bool
+	cancelled bool
+}
+
+// NewWizard creates a setup wizard starting from the given values
+func NewWizard(initial WizardValues, opts WizardOptions) Wizard {
+	input := textinput.New()
+	input.Prompt = "› "
+	input.CharLimit = 512
+	input.Width = 60
+
+	w := Wizard{opts: opts, values: initial, input: input}
+	if opts.Existing {
+		w.step = stepEditExisting
+	} else {
+		w = w.enter(stepToken)
+	}
+	return w
+}
+
+// Result returns the values entered and whether the user chose to save them
+func (w Wizard) Result() (WizardValues, bool) {
+	return w.values, w.saved
+}
+
+// Init starts the cursor blinking
+func (w Wizard) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// enter moves to a step, filling the input with the current value
+func (w Wizard) enter(step wizardStep) Wizard {
+	w.step = step
+	w.err = ""
+	w.input.EchoMode = textinput.EchoNormal
+	w.input.Placeholder = ""
+
+	value := ""
+	switch step {
+	case stepToken:
+		value = w.values.APIToken
+		w.input.EchoMode = textinput.EchoPassword
+		w.input.Placeholder = w.opts.TokenURL
+	case stepDownloadDir:
+		value = w.values.DownloadDir
+	case stepAria2URL:
+		value = w.values.Aria2RPCUrl
+	case stepAria2Secret:
+		value = w.values.Aria2Secret
+		w.input.EchoMode = textinput.EchoPassword
+		w.input.Placeholder = "leave empty if aria2 has no secret"
+	case stepConnections:
+		value = strconv.Itoa(w.values.Connections)
+	}
+
+	w.input.SetValue(value)
+	w.input.CursorEnd()
+	if step == stepEditExisting || step == stepConfirm {
+		w.input.Blur()
+	} else {
+		w.input.Focus()
+	}
+	return w
+}
+
+// detectAria2 looks for aria2 at the current URL
+func (w Wizard) detectAria2(rpcURL string) tea.Cmd {
+	if w.opts.DetectAria2 == nil {
+		return nil
+	}
+	detect, secret := w.opts.DetectAria2, w.values.Aria2Secret
+	return func() tea.Msg {
+		return aria2DetectedMsg{rpcURL: rpcURL, err: detect(rpcURL, secret)}
+	}
+}
+
+// Update handles key presses and the results of checks
+func (w Wizard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			w.cancelled = true
+			return w, tea.Quit
+		case tea.KeyEnter:
+			if w.checking {
+				return w, nil
+			}
+			return w.submit()
+		}
+
+		switch w.step {
+		case stepEditExisting:
+			switch strings.ToLower(msg.String()) {
+			case "y":
+				return w.enter(stepToken), nil
+			case "n", "q":
+				w.cancelled = true
+				return w, tea.Quit
+			}
+			return w, nil
+		case stepConfirm:
+			switch strings.ToLower(msg.String()) {
+			case "y":
+				return w.submit()
+			case "n", "b":
+				return w.enter(stepToken), nil
+			}
+			return w, nil
+		}
+
+	case tokenCheckedMsg:
+		w.checking = false
+		if msg.err != nil {
+			w.err = "The token could not be validated: " + msg.err.Error()
+			if hint := debrid.Hint(msg.err); hint != "" {
+				w.err += "\n  " + hint
+			}
+			return w, nil
+		}
+		w.values.APIToken = msg.token
+		return w.enter(stepDownloadDir), nil
+
+	case aria2DetectedMsg:
+		w.aria2URL, w.aria2Err, w.aria2Known = msg.rpcURL, msg.err, true
+		return w, nil
+	}
+
+	var cmd tea.Cmd
+	w.input, cmd = w.input.Update(msg)
+	return w, cmd
+}
+
+// submit accepts the answer to the current step
+func (w Wizard) submit() (tea.Model, tea.Cmd) {
+	value := strings.TrimSpace(w.input.Value())
+
+	switch w.step {
+	case stepEditExisting:
+		return w.enter(stepToken), nil
+
+	case stepToken:
+		if value == "" {
+			w.err = "The API token is required"
+			return w, nil
+		}
+		if w.opts.ValidateToken == nil {
+			w.values.APIToken = value
+			return w.enter(stepDownloadDir), nil
+		}
+		w.err = ""
+		w.checking = true
+		validate := w.opts.ValidateToken
+		return w, func() tea.Msg {
+			return tokenCheckedMsg{token: value, err: validate(value)}
+		}
+
+	case stepDownloadDir:
+		if value == "" {
+			w.err = "The download directory is required"
+			return w, nil
+		}
+		dir := utils.NormalizePath(value)
+		if err := utils.ValidatePath(dir); err != nil {
+			w.err = err.Error()
+			return w, nil
+		}
+		w.values.DownloadDir = dir
+		next := w.enter(stepAria2URL)
+		return next, next.detectAria2(next.values.Aria2RPCUrl)
+
+	case stepAria2URL:
+		if problem := rpcURLProblem(value); problem != "" {
+			w.err = problem
+			return w, nil
+		}
+		w.values.Aria2RPCUrl = value
+		return w.enter(stepAria2Secret), nil
+
+	case stepAria2Secret:
+		w.values.Aria2Secret = value
+		return w.enter(stepConnections), nil
+
+	case stepConnections:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 16 {
+			w.err = "Enter a number from 1 to 16"
+			return w, nil
+		}
+		w.values.Connections = n
+		return w.enter(stepConfirm), nil
+
+	case stepConfirm:
+		w.saved = true
+		return w, tea.Quit
+	}
+	return w, nil
+}
+
+// rpcURLProblem explains why an aria2 RPC URL can't be connected to, or
+// returns an empty string if it can
+func rpcURLProblem(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return "Enter a URL such as http://localhost:6800/jsonrpc"
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+		return ""
+	}
+	return fmt.Sprintf("Unsupported scheme %q, use http, https, ws or wss", u.Scheme)
+}
+
+// View renders the current step
+func (w Wizard) View() string {
+	if w.saved || w.cancelled {
+		return ""
+	}
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("venaqui - Setup"))
+	s.WriteString("\n\n")
+
+	switch w.step {
+	case stepEditExisting:
+		s.WriteString(fmt.Sprintf("A configuration already exists at %s.\n", w.opts.ConfigPath))
+		s.WriteString("Settings not asked for here are kept.\n\n")
+		s.WriteString(helpStyle.Render("Edit it? [y/n]"))
+
+	case stepConfirm:
+		secret := "(none)"
+		if w.values.Aria2Secret != "" {
+			secret = strings.Repeat("•", 8)
+		}
+		s.WriteString(boxStyle.Render(fmt.Sprintf("%s %s\n%s %s\n%s %s\n%s %s\n%s %d",
+			statLabelStyle.Render("API token:"), statValueStyle.Render(maskToken(w.values.APIToken)),
+			statLabelStyle.Render("Downloads:"), statValueStyle.Render(w.values.DownloadDir),
+			statLabelStyle.Render("aria2 RPC:"), statValueStyle.Render(w.values.Aria2RPCUrl),
+			statLabelStyle.Render("Secret:"), statValueStyle.Render(secret),
+			statLabelStyle.Render("Connections:"), w.values.Connections,
+		)))
+		s.WriteString("\n\n")
+		s.WriteString(fmt.Sprintf("Write %s?\n\n", w.opts.ConfigPath))
+		s.WriteString(helpStyle.Render("[y/enter] save • [n] start over • [esc] quit without saving"))
+
+	default:
+		s.WriteString(statValueStyle.Render(w.question()))
+		s.WriteString("\n")
+		s.WriteString(helpStyle.Render(w.explanation()))
+		s.WriteString("\n\n")
+		s.WriteString(w.input.View())
+		s.WriteString("\n\n")
+
+		switch {
+		case w.checking:
+			s.WriteString(statusActiveStyle.Render("Checking the token with " + w.provider() + "..."))
+			s.WriteString("\n\n")
+		case w.err != "":
+			s.WriteString(statusErrorStyle.Render("✗ " + w.err))
+			s.WriteString("\n\n")
+		}
+		if w.step == stepAria2URL && w.aria2Known {
+			if w.aria2Err == nil {
+				s.WriteString(statusCompleteStyle.Render("✓ aria2 is running at " + w.aria2URL))
+			} else {
+				s.WriteString(statusWarningStyle.Render("aria2 is not answering at " + w.aria2URL + "; venaqui starts it when needed"))
+			}
+			s.WriteString("\n\n")
+		}
+		s.WriteString(helpStyle.Render("[enter] next • [esc] quit without saving"))
+	}
+
+	return s.String() + "\n"
+}
+
+// question returns the prompt of the current step
+func (w Wizard) question() string {
+	switch w.step {
+	case stepToken:
+		return w.provider() + " API token"
+	case stepDownloadDir:
+		return "Download directory"
+	case stepAria2URL:
+		return "aria2 RPC URL"
+	case stepAria2Secret:
+		return "aria2 RPC secret"
+	case stepConnections:
+		return "Connections per download"
+	}
+	return ""
+}
+
+// explanation returns the help text of the current step
+func (w Wizard) explanation() string {
+	switch w.step {
+	case stepToken:
+		if w.opts.TokenURL == "" {
+			return "It is checked before moving on."
+		}
+		return "Find it at " + w.opts.TokenURL + ". It is checked before moving on."
+	case stepDownloadDir:
+		return "Where downloads are saved unless a location is given."
+	case stepAria2URL:
+		return "venaqui starts aria2 itself when it runs on this machine."
+	case stepAria2Secret:
+		return "The --rpc-secret aria2 was started with, if any."
+	case stepConnections:
+		return "Parallel connections aria2 opens per download, from 1 to 16."
+	}
+	return ""
+}
+
+// provider returns the name of the debrid service the token is for
+func (w Wizard) provider() string {
+	if w.opts.Provider == "" {
+		return "Debrid"
+	}
+	return w.opts.Provider
+}
+
+// maskToken hides all but the last characters of a token
+func maskToken(token string) string {
+	if len(token) <= 4 {
+		return strings.Repeat("•", len(token))
+	}
+	return strings.Repeat("•", 8) + token[len(token)-4:]
+}
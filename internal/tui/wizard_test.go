@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runWizard feeds messages to the wizard, running the commands that check
+// the token and aria2 the way Bubble Tea would
+func runWizard(t *testing.T, w Wizard, msgs ...tea.Msg) Wizard {
+	t.Helper()
+	for _, msg := range msgs {
+		model, cmd := w.Update(msg)
+		w = model.(Wizard)
+		// Follow up on the checks; other commands only blink the cursor or quit
+		if cmd != nil && (w.checking || w.step == stepAria2URL && !w.aria2Known) {
+			model, _ = w.Update(cmd())
+			w = model.(Wizard)
+		}
+	}
+	return w
+}
+
+func typed(s string) tea.Msg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+var enter = tea.KeyMsg{Type: tea.KeyEnter}
+
+func TestWizard(t *testing.T) {
+	var detected string
+	w := NewWizard(WizardValues{
+		DownloadDir: "/tmp/downloads",
+		Aria2RPCUrl: "http://localhost:6800/jsonrpc",
+		Connections: 16,
+	}, WizardOptions{
+		ConfigPath: "/tmp/config.yaml",
+		ValidateToken: func(token string) error {
+			if token != "good" {
+				return errors.New("bad token")
+			}
+			return nil
+		},
+		DetectAria2: func(rpcURL, secret string) error {
+			detected = rpcURL
+			return nil
+		},
+	})
+
+	w = runWizard(t, w, enter)
+	if w.step != stepToken || w.err == "" {
+		t.Fatalf("empty token: step = %v, err = %q, want the token step with an error", w.step, w.err)
+	}
+
+	w = runWizard(t, w, typed("wrong"), enter)
+	if w.step != stepToken || w.err == "" {
+		t.Fatalf("invalid token: step = %v, err = %q, want the token step with an error", w.step, w.err)
+	}
+
+	// Replace the rejected token
+	for range "wrong" {
+		w = runWizard(t, w, tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	w = runWizard(t, w, typed("good"), enter)
+	if w.step != stepDownloadDir {
+		t.Fatalf("valid token: step = %v, want the download directory step", w.step)
+	}
+
+	w = runWizard(t, w, enter)
+	if w.step != stepAria2URL || detected != "http://localhost:6800/jsonrpc" || w.aria2Err != nil {
+		t.Fatalf("step = %v, detected %q, want aria2 detected at the default URL", w.step, detected)
+	}
+
+	w = runWizard(t, w, enter, typed("s3cret"), enter)
+	if w.step != stepConnections {
+		t.Fatalf("step = %v, want the connections step", w.step)
+	}
+
+	w = runWizard(t, w, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace}, typed("32"), enter)
+	if w.step != stepConnections || w.err == "" {
+		t.Fatalf("too many connections: step = %v, err = %q, want an error", w.step, w.err)
+	}
+	w = runWizard(t, w, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace}, typed("8"), enter)
+	if w.step != stepConfirm {
+		t.Fatalf("step = %v, want the confirmation step", w.step)
+	}
+
+	w = runWizard(t, w, typed("y"))
+	values, saved := w.Result()
+	if !saved {
+		t.Fatal("Result() saved = false, want true")
+	}
+	want := WizardValues{
+		APIToken:    "good",
+		DownloadDir: "/tmp/downloads",
+		Aria2RPCUrl: "http://localhost:6800/jsonrpc",
+		Aria2Secret: "s3cret",
+		Connections: 8,
+	}
+	if values != want {
+		t.Errorf("Result() = %+v, want %+v", values, want)
+	}
+}
+
+func TestWizard_Existing(t *testing.T) {
+	w := NewWizard(WizardValues{APIToken: "kept", Connections: 16}, WizardOptions{Existing: true})
+	if w.step != stepEditExisting {
+		t.Fatalf("step = %v, want the edit prompt", w.step)
+	}
+
+	declined := runWizard(t, w, typed("n"))
+	if _, saved := declined.Result(); saved || !declined.cancelled {
+		t.Error("declining to edit should quit without saving")
+	}
+
+	w = runWizard(t, w, typed("y"))
+	if w.step != stepToken || w.input.Value() != "kept" {
+		t.Errorf("step = %v, input = %q, want the token step with the existing token", w.step, w.input.Value())
+	}
+}
+
+func TestWizard_Provider(t *testing.T) {
+	w := NewWizard(WizardValues{Connections: 16}, WizardOptions{
+		Provider: "AllDebrid",
+		TokenURL: "https://alldebrid.com/apikeys",
+	})
+	if got := w.question(); got != "AllDebrid API token" {
+		t.Errorf("question() = %q, want AllDebrid API token", got)
+	}
+	if got := w.explanation(); !strings.Contains(got, "https://alldebrid.com/apikeys") {
+		t.Errorf("explanation() = %q, want the AllDebrid token URL", got)
+	}
+	if w.input.Placeholder != "https://alldebrid.com/apikeys" {
+		t.Errorf("placeholder = %q, want the AllDebrid token URL", w.input.Placeholder)
+	}
+}
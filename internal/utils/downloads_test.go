@@ -6,27 +6,6 @@ import (
 	"testing"
 )
 
-func TestGetDownloadFolder(t *testing.T) {
-	folder, err := GetDownloadFolder()
-	if err != nil {
-		t.Fatalf("GetDownloadFolder() error = %v", err)
-	}
-
-	if folder == "" {
-		t.Error("GetDownloadFolder() returned empty string")
-	}
-
-	// Should contain "Downloads"
-	if filepath.Base(folder) != "Downloads" {
-		t.Errorf("GetDownloadFolder() = %v, expected path ending with Downloads", folder)
-	}
-
-	// Should be absolute path
-	if !filepath.IsAbs(folder) {
-		t.Errorf("GetDownloadFolder() = %v, expected absolute path", folder)
-	}
-}
-
 func TestEnsureDirExists(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "venaqui-test-*")
 	if err != nil {
@@ -0,0 +1,143 @@
+package verify
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrMismatch is returned when a file does not match its expected checksum
+var ErrMismatch = errors.New("checksum mismatch")
+
+// MaxRedownloads is how often a corrupted file is downloaded again before giving up
+const MaxRedownloads = 2
+
+// Checksum is an expected digest for a file
+type Checksum struct {
+	Algo string // md5, sha1, sha256, sha512 or crc32
+	Hex  string // Lowercase hex digest
+}
+
+// aria2Algos maps supported algorithms to aria2's checksum option names.
+// crc32 is only used for SFV sidecars; aria2 cannot verify it.
+var aria2Algos = map[string]string{
+	"md5":    "md5",
+	"sha1":   "sha-1",
+	"sha256": "sha-256",
+	"sha512": "sha-512",
+	"crc32":  "",
+}
+
+// ParseChecksum parses a checksum flag value such as "sha256=<hex>"
+func ParseChecksum(value string) (*Checksum, error) {
+	algo, digest, ok := strings.Cut(value, "=")
+	if !ok {
+		return nil, fmt.Errorf("checksum must be in the form <algo>=<hex>, got %q", value)
+	}
+
+	algo = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(algo), "-", ""))
+	if _, ok := aria2Algos[algo]; !ok {
+		return nil, fmt.Errorf("unsupported checksum algorithm: %s", algo)
+	}
+
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	if _, err := hex.DecodeString(digest); err != nil || digest == "" {
+		return nil, fmt.Errorf("invalid %s digest: %q", algo, digest)
+	}
+	if want := newHash(algo).Size() * 2; len(digest) != want {
+		return nil, fmt.Errorf("invalid %s digest length: got %d hex characters, want %d", algo, len(digest), want)
+	}
+
+	return &Checksum{Algo: algo, Hex: digest}, nil
+}
+
+// String returns the checksum in flag form
+func (c Checksum) String() string {
+	return c.Algo + "=" + c.Hex
+}
+
+// Aria2Option returns the value for aria2's checksum option, or an empty
+// string if aria2 cannot verify this algorithm
+func (c Checksum) Aria2Option() string {
+	name := aria2Algos[c.Algo]
+	if name == "" {
+		return ""
+	}
+	return name + "=" + c.Hex
+}
+
+// Result is the outcome of verifying a file
+type Result struct {
+	Path     string
+	Algo     string
+	Expected string
+	Actual   string
+	Source   string // "flag" or the sidecar file name
+}
+
+// OK reports whether the file matched the expected digest
+func (r *Result) OK() bool {
+	return r.Expected == r.Actual
+}
+
+// File hashes the file at path and compares it with the expected checksum
+func File(path string, expected Checksum, source string) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	h := newHash(expected.Algo)
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return &Result{
+		Path:     path,
+		Algo:     expected.Algo,
+		Expected: expected.Hex,
+		Actual:   hex.EncodeToString(h.Sum(nil)),
+		Source:   source,
+	}, nil
+}
+
+// Check verifies a downloaded file against an explicit checksum, or against
+// a sidecar checksum file when explicit is nil. It returns a nil result if
+// there is nothing to verify the file against.
+func Check(path string, explicit *Checksum) (*Result, error) {
+	if explicit != nil {
+		return File(path, *explicit, "flag")
+	}
+
+	sidecar, source, err := FindSidecar(path)
+	if err != nil || sidecar == nil {
+		return nil, err
+	}
+	return File(path, *sidecar, source)
+}
+
+// newHash returns a hash for a supported algorithm
+func newHash(algo string) hash.Hash {
+	switch algo {
+	case "md5":
+		return md5.New()
+	case "sha1":
+		return sha1.New()
+	case "sha512":
+		return sha512.New()
+	case "crc32":
+		return crc32.NewIEEE()
+	default:
+		return sha256.New()
+	}
+}
@@ -0,0 +1,170 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Digests of the string "hello world\n"
+const (
+	helloMD5    = "6f5902ac237024bdd0c176cb93063dc4"
+	helloSHA256 = "a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447"
+	helloCRC32  = "af083b2d"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestParseChecksum(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		wantAria2 string
+		wantErr   bool
+	}{
+		{
+			name:      "sha256",
+			value:     "sha256=" + helloSHA256,
+			wantAria2: "sha-256=" + helloSHA256,
+		},
+		{
+			name:      "aria2 style name and uppercase digest",
+			value:     "SHA-256=A948904F2F0F479B8F8197694B30184B0D2ED1C1CD2A1EC0FB85D299A192A447",
+			wantAria2: "sha-256=" + helloSHA256,
+		},
+		{
+			name:      "md5",
+			value:     "md5=" + helloMD5,
+			wantAria2: "md5=" + helloMD5,
+		},
+		{
+			name:    "missing separator",
+			value:   helloSHA256,
+			wantErr: true,
+		},
+		{
+			name:    "unknown algorithm",
+			value:   "whirlpool=abcd",
+			wantErr: true,
+		},
+		{
+			name:    "wrong length",
+			value:   "sha256=" + helloMD5,
+			wantErr: true,
+		},
+		{
+			name:    "not hex",
+			value:   "md5=zz5902ac237024bdd0c176cb93063dc4",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum, err := ParseChecksum(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && sum.Aria2Option() != tt.wantAria2 {
+				t.Errorf("Aria2Option() = %v, want %v", sum.Aria2Option(), tt.wantAria2)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		sidecars   map[string]string
+		explicit   string
+		wantNil    bool
+		wantOK     bool
+		wantSource string
+	}{
+		{
+			name:    "no sidecar",
+			wantNil: true,
+		},
+		{
+			name:       "explicit checksum",
+			explicit:   "md5=" + helloMD5,
+			wantOK:     true,
+			wantSource: "flag",
+		},
+		{
+			name:       "dedicated sha256 sidecar with bare digest",
+			sidecars:   map[string]string{"file.bin.sha256": helloSHA256 + "\n"},
+			wantOK:     true,
+			wantSource: "file.bin.sha256",
+		},
+		{
+			name:       "md5sum list",
+			sidecars:   map[string]string{"checksums.md5": helloMD5 + "  other.bin\n" + helloMD5 + " *file.bin\n"},
+			wantOK:     true,
+			wantSource: "checksums.md5",
+		},
+		{
+			name:       "sfv list",
+			sidecars:   map[string]string{"release.sfv": "; comment\nfile.bin " + helloCRC32 + "\n"},
+			wantOK:     true,
+			wantSource: "release.sfv",
+		},
+		{
+			name:       "corrupted file",
+			sidecars:   map[string]string{"file.bin.md5": "00000000000000000000000000000000  file.bin\n"},
+			wantOK:     false,
+			wantSource: "file.bin.md5",
+		},
+		{
+			name:     "list without the file",
+			sidecars: map[string]string{"checksums.md5": helloMD5 + "  other.bin\n"},
+			wantNil:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := writeFile(t, dir, "file.bin", "hello world\n")
+			for name, content := range tt.sidecars {
+				writeFile(t, dir, name, content)
+			}
+
+			var explicit *Checksum
+			if tt.explicit != "" {
+				sum, err := ParseChecksum(tt.explicit)
+				if err != nil {
+					t.Fatalf("ParseChecksum() error = %v", err)
+				}
+				explicit = sum
+			}
+
+			result, err := Check(path, explicit)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if tt.wantNil {
+				if result != nil {
+					t.Errorf("Check() = %+v, want nil", result)
+				}
+				return
+			}
+			if result == nil {
+				t.Fatal("Check() = nil, want result")
+			}
+			if result.OK() != tt.wantOK {
+				t.Errorf("Check().OK() = %v, want %v (actual %s)", result.OK(), tt.wantOK, result.Actual)
+			}
+			if result.Source != tt.wantSource {
+				t.Errorf("Check().Source = %v, want %v", result.Source, tt.wantSource)
+			}
+		})
+	}
+}
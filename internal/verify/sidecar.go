@@ -0,0 +1,134 @@
+package verify
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sidecarExts maps sidecar file extensions to the algorithm they contain,
+// in order of preference
+var sidecarExts = []struct {
+	ext  string
+	algo string
+}{
+	{".sha512", "sha512"},
+	{".sha256", "sha256"},
+	{".sha1", "sha1"},
+	{".md5", "md5"},
+	{".sfv", "crc32"},
+}
+
+// FindSidecar looks for a checksum file next to path that lists it.
+// Files named after the download (file.iso.sha256) are preferred over
+// directory-wide lists (SHA256SUMS.sha256, release.sfv). It returns the
+// checksum and the sidecar's file name, or nil if none is found.
+func FindSidecar(path string) (*Checksum, string, error) {
+	dir := filepath.Dir(path)
+	name := filepath.Base(path)
+
+	// Dedicated sidecars first
+	for _, s := range sidecarExts {
+		candidate := filepath.Join(dir, name+s.ext)
+		sum, err := lookupSidecar(candidate, s.algo, name, true)
+		if err != nil {
+			return nil, "", err
+		}
+		if sum != nil {
+			return sum, filepath.Base(candidate), nil
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read directory: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, s := range sidecarExts {
+		for _, candidate := range names {
+			if !strings.EqualFold(filepath.Ext(candidate), s.ext) {
+				continue
+			}
+			sum, err := lookupSidecar(filepath.Join(dir, candidate), s.algo, name, false)
+			if err != nil {
+				return nil, "", err
+			}
+			if sum != nil {
+				return sum, candidate, nil
+			}
+		}
+	}
+
+	return nil, "", nil
+}
+
+// lookupSidecar reads a checksum file and returns the entry for name.
+// A dedicated sidecar may contain a bare digest without a file name.
+func lookupSidecar(sidecarPath, algo, name string, dedicated bool) (*Checksum, error) {
+	f, err := os.Open(sidecarPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checksum file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var digest, file string
+		if algo == "crc32" {
+			digest, file = parseSFVLine(line)
+		} else {
+			digest, file = parseSumLine(line)
+		}
+
+		if file == name || (dedicated && file == "") {
+			sum, err := ParseChecksum(algo + "=" + digest)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", filepath.Base(sidecarPath), err)
+			}
+			return sum, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksum file: %w", err)
+	}
+	return nil, nil
+}
+
+// parseSumLine parses a md5sum/sha256sum line: "<hex>  <file>" or "<hex> *<file>"
+func parseSumLine(line string) (digest, file string) {
+	fields := strings.SplitN(line, " ", 2)
+	digest = fields[0]
+	if len(fields) == 2 {
+		file = strings.TrimLeft(strings.TrimSpace(fields[1]), "*")
+		file = filepath.Base(filepath.FromSlash(file))
+	}
+	return digest, file
+}
+
+// parseSFVLine parses an SFV line: "<file> <crc32>"
+func parseSFVLine(line string) (digest, file string) {
+	i := strings.LastIndex(line, " ")
+	if i < 0 {
+		return line, ""
+	}
+	return strings.TrimSpace(line[i+1:]), filepath.Base(filepath.FromSlash(strings.TrimSpace(line[:i])))
+}
@@ -0,0 +1,264 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Subfolders that handled files are moved into
+const (
+	ProcessedDir = "processed"
+	FailedDir    = "failed"
+)
+
+// defaultSettle is how long a file must stay unchanged before it is read,
+// so files still being written are not picked up half-way
+const defaultSettle = time.Second
+
+// Item is a single download found in a dropped file
+type Item struct {
+	Link    string // Hoster, magnet or .torrent link
+	Torrent []byte // Contents of a .torrent file, if Link is empty
+}
+
+// Failure is an item of a dropped file that could not be queued
+type Failure struct {
+	Item Item
+	Err  error
+}
+
+// Handler queues the items of a dropped file and returns the items that failed
+type Handler func(path string, items []Item) []Failure
+
+// Supported reports whether a file is picked up by the watcher
+func Supported(path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".torrent", ".magnet", ".txt":
+		return true
+	}
+	return false
+}
+
+// ReadItems reads the downloads listed in a .torrent, .magnet or .txt file.
+// Link lists hold one link per line; blank lines and lines starting with #
+// are ignored.
+func ReadItems(path string) ([]Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".torrent") {
+		// Torrent files are bencoded dictionaries
+		if !bytes.HasPrefix(data, []byte("d")) {
+			return nil, fmt.Errorf("%s is not a torrent file", filepath.Base(path))
+		}
+		return []Item{{Torrent: data}}, nil
+	}
+
+	var items []Item
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		items = append(items, Item{Link: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no links found in %s", filepath.Base(path))
+	}
+	return items, nil
+}
+
+// Watcher feeds files dropped into a directory to a handler and moves them
+// into the processed or failed subfolder afterwards
+type Watcher struct {
+	dir     string
+	handler Handler
+	settle  time.Duration
+}
+
+// New creates a watcher for dir
+func New(dir string, handler Handler) *Watcher {
+	return &Watcher{dir: dir, handler: handler, settle: defaultSettle}
+}
+
+// Run processes files already in the directory, then watches for new ones
+// until ctx is cancelled. Errors reported by the file system watcher, such
+// as a lost event queue, are passed to onError and the directory is scanned
+// again so no dropped file is missed.
+func (w *Watcher) Run(ctx context.Context, onError func(error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(w.dir); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", w.dir, err)
+	}
+
+	// Files that changed, and when they last did
+	pending := make(map[string]time.Time)
+	if err := w.scan(pending, time.Time{}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(w.settle / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write) != 0 && Supported(event.Name) {
+				pending[event.Name] = time.Now()
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if onError != nil {
+				onError(fmt.Errorf("watch error: %w", err))
+			}
+			// Files still being written settle before they are read
+			if err := w.scan(pending, time.Now()); err != nil && onError != nil {
+				onError(err)
+			}
+
+		case now := <-ticker.C:
+			for path, changed := range pending {
+				if now.Sub(changed) < w.settle {
+					continue
+				}
+				delete(pending, path)
+				if info, err := os.Stat(path); err != nil || info.IsDir() {
+					continue
+				}
+				if _, err := w.Process(path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// scan adds the supported files in the directory that are not pending yet,
+// as changed at the given time
+func (w *Watcher) scan(pending map[string]time.Time, changed time.Time) error {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", w.dir, err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(w.dir, entry.Name())
+		if _, ok := pending[path]; !ok && !entry.IsDir() && Supported(entry.Name()) {
+			pending[path] = changed
+		}
+	}
+	return nil
+}
+
+// Process hands a file to the handler and moves it to the processed or
+// failed subfolder. It returns the new path of the file. A file some of
+// whose links were queued goes to the processed folder, and a link list
+// holding only the failed links is written to the failed folder. Errors
+// from the handler are recorded next to the failed file; only errors
+// moving the file are returned.
+func (w *Watcher) Process(path string) (string, error) {
+	items, err := ReadItems(path)
+	var failures []Failure
+	if err == nil {
+		failures = w.handler(path, items)
+		err = joinFailures(failures)
+	}
+
+	folder := ProcessedDir
+	if err != nil && len(failures) == len(items) {
+		folder = FailedDir
+	}
+	dest, moveErr := move(path, filepath.Join(w.dir, folder))
+	if moveErr != nil {
+		return "", fmt.Errorf("failed to move %s: %w", filepath.Base(path), moveErr)
+	}
+
+	if err == nil {
+		return dest, nil
+	}
+	failed := dest
+	if folder == ProcessedDir {
+		// Only the failed links are left to retry
+		var lines []string
+		for _, f := range failures {
+			lines = append(lines, f.Item.Link)
+		}
+		failed, moveErr = freePath(filepath.Join(w.dir, FailedDir), filepath.Base(path))
+		if moveErr == nil {
+			moveErr = os.WriteFile(failed, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+		}
+		if moveErr != nil {
+			return "", fmt.Errorf("failed to write the failed links of %s: %w", filepath.Base(path), moveErr)
+		}
+	}
+
+	// Keep the reason next to the file for the user to look at
+	_ = os.WriteFile(failed+".error", []byte(err.Error()+"\n"), 0644)
+	return dest, nil
+}
+
+// joinFailures joins the errors of the failed items
+func joinFailures(failures []Failure) error {
+	var errs []error
+	for _, f := range failures {
+		errs = append(errs, f.Err)
+	}
+	return errors.Join(errs...)
+}
+
+// move moves a file into dir, adding a timestamp if the name is taken
+func move(path, dir string) (string, error) {
+	dest, err := freePath(dir, filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	return dest, os.Rename(path, dest)
+}
+
+// freePath returns a path for name inside dir, creating dir and adding a
+// timestamp if the name is taken
+func freePath(dir, name string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(dir, name)
+	if _, err := os.Stat(dest); err == nil {
+		ext := filepath.Ext(dest)
+		dest = fmt.Sprintf("%s-%s%s", strings.TrimSuffix(dest, ext), time.Now().Format("20060102-150405.000"), ext)
+	}
+	return dest, nil
+}
@@ -0,0 +1,202 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadItems(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		wantLinks []string
+		wantData  bool
+		wantErr   bool
+	}{
+		{
+			name:      "link list",
+			path:      write("links.txt", "# movies\nhttps://host/a\n\n  https://host/b  \nmagnet:?xt=urn:btih:abc\n"),
+			wantLinks: []string{"https://host/a", "https://host/b", "magnet:?xt=urn:btih:abc"},
+		},
+		{
+			name:      "magnet file",
+			path:      write("ubuntu.magnet", "magnet:?xt=urn:btih:abc\n"),
+			wantLinks: []string{"magnet:?xt=urn:btih:abc"},
+		},
+		{name: "torrent file", path: write("ubuntu.torrent", "d8:announce3:urle"), wantData: true},
+		{name: "not a torrent", path: write("bad.torrent", "<html>"), wantErr: true},
+		{name: "empty list", path: write("empty.txt", "# nothing\n"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := ReadItems(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadItems() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantData {
+				if len(items) != 1 || len(items[0].Torrent) == 0 {
+					t.Errorf("ReadItems() = %v, want torrent data", items)
+				}
+				return
+			}
+			if len(items) != len(tt.wantLinks) {
+				t.Fatalf("ReadItems() returned %d items, want %d", len(items), len(tt.wantLinks))
+			}
+			for i, item := range items {
+				if item.Link != tt.wantLinks[i] {
+					t.Errorf("ReadItems()[%d] = %q, want %q", i, item.Link, tt.wantLinks[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for path, want := range map[string]bool{
+		"a.torrent":       true,
+		"a.MAGNET":        true,
+		"links.txt":       true,
+		".hidden.txt":     false,
+		"a.torrent.part":  false,
+		"movie.mkv":       false,
+		"dir/another.txt": true,
+	} {
+		if got := Supported(path); got != want {
+			t.Errorf("Supported(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	dir := t.TempDir()
+
+	// A file dropped before the watcher starts is picked up too
+	if err := os.WriteFile(filepath.Join(dir, "early.txt"), []byte("https://host/early\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var handled []string
+	w := New(dir, func(path string, items []Item) []Failure {
+		mu.Lock()
+		defer mu.Unlock()
+		var failures []Failure
+		for _, item := range items {
+			handled = append(handled, item.Link)
+			if strings.HasSuffix(item.Link, "/bad") {
+				failures = append(failures, Failure{Item: item, Err: errors.New("hoster not supported")})
+			}
+		}
+		return failures
+	})
+	w.settle = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx, nil) }()
+
+	time.Sleep(20 * time.Millisecond)
+	for name, content := range map[string]string{
+		"good.txt":  "https://host/good\n",
+		"bad.txt":   "https://host/bad\n",
+		"mixed.txt": "https://host/ok\nhttps://other/bad\n",
+		"notes.md":  "ignored",
+		"x.torrent": "not bencoded",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantFiles := []string{
+		filepath.Join(dir, ProcessedDir, "early.txt"),
+		filepath.Join(dir, ProcessedDir, "good.txt"),
+		filepath.Join(dir, FailedDir, "bad.txt"),
+		filepath.Join(dir, FailedDir, "bad.txt.error"),
+		filepath.Join(dir, FailedDir, "x.torrent"),
+		filepath.Join(dir, ProcessedDir, "mixed.txt"),
+		filepath.Join(dir, FailedDir, "mixed.txt.error"),
+		filepath.Join(dir, "notes.md"),
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for _, path := range wantFiles {
+		for {
+			if _, err := os.Stat(path); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s was not created", path)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+
+	// Only the link that failed is left in the failed copy of a mixed list
+	data, err := os.ReadFile(filepath.Join(dir, FailedDir, "mixed.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "https://other/bad\n" {
+		t.Errorf("failed mixed.txt = %q, want only the failed link", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 5 {
+		t.Errorf("handler called for %v, want early, good, bad and both mixed links", handled)
+	}
+}
+
+func TestWatcher_scan(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.magnet", "notes.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, ProcessedDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file already pending keeps the time it last changed
+	changed := time.Now().Add(-time.Minute)
+	rescanned := time.Now()
+	pending := map[string]time.Time{filepath.Join(dir, "a.txt"): changed}
+	if err := New(dir, nil).scan(pending, rescanned); err != nil {
+		t.Fatalf("scan() error = %v", err)
+	}
+
+	want := map[string]time.Time{
+		filepath.Join(dir, "a.txt"):    changed,
+		filepath.Join(dir, "b.magnet"): rescanned,
+	}
+	if len(pending) != len(want) {
+		t.Fatalf("scan() pending = %v, want %v", pending, want)
+	}
+	for path, at := range want {
+		if !pending[path].Equal(at) {
+			t.Errorf("pending[%s] = %v, want %v", filepath.Base(path), pending[path], at)
+		}
+	}
+}